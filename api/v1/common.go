@@ -0,0 +1,174 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PisaListBE/internal/middleware"
+	"github.com/PisaListBE/internal/service"
+	"github.com/PisaListBE/pkg/mergepatch"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// MIMEMergePatch JSON Merge Patch 的请求类型，PATCH 接口同时接受 application/json
+const MIMEMergePatch = "application/merge-patch+json"
+
+// parseID 解析路径参数中的ID，格式错误时返回 false
+func parseID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// userLocation 返回 Location 中间件写入的用户时区，未设置时为 UTC
+func userLocation(c *gin.Context) *time.Location {
+	if loc, ok := c.Value(middleware.LocationKey).(*time.Location); ok {
+		return loc
+	}
+	return time.UTC
+}
+
+// parseDateRange 解析 from/to 查询参数（YYYY-MM-DD）。to 默认为用户时区的今天，
+// from 默认由 defaultFrom 根据 to 计算。参数错误时直接返回 400 并返回 false。
+func parseDateRange(c *gin.Context, defaultFrom func(to time.Time) time.Time) (from, to time.Time, ok bool) {
+	loc := userLocation(c)
+	to = time.Now().In(loc)
+	if v := c.Query("to"); v != "" {
+		t, err := time.ParseInLocation(service.DateLayout, v, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "日期格式应为 YYYY-MM-DD"})
+			return from, to, false
+		}
+		to = t
+	}
+	from = defaultFrom(to)
+	if v := c.Query("from"); v != "" {
+		t, err := time.ParseInLocation(service.DateLayout, v, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "日期格式应为 YYYY-MM-DD"})
+			return from, to, false
+		}
+		from = t
+	}
+	if from.Format(service.DateLayout) > to.Format(service.DateLayout) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "开始日期不能晚于结束日期"})
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// applyMergePatch 把 patch 作为 JSON Merge Patch 应用到 current 的 JSON 表示上，
+// 结果解析到 dst 并按 binding 标签校验，patch 中没有的字段保持 current 的值。
+// current 为 nil 时 patch 就是完整的请求体
+func applyMergePatch(current interface{}, patch []byte, dst interface{}) error {
+	// 资源始终是 JSON 对象，非对象的补丁会替换整个资源，不允许
+	if !bytes.HasPrefix(bytes.TrimSpace(patch), []byte("{")) {
+		return errors.New("无效的 JSON Merge Patch: 补丁必须是 JSON 对象")
+	}
+	merged := patch
+	if current != nil {
+		doc, err := json.Marshal(current)
+		if err != nil {
+			return err
+		}
+		if merged, err = mergepatch.Apply(doc, patch); err != nil {
+			return fmt.Errorf("无效的 JSON Merge Patch: %w", err)
+		}
+	}
+	if err := json.Unmarshal(merged, dst); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(dst)
+}
+
+// bindMergePatch 把请求体作为 JSON Merge Patch 应用到 current 上并解析到 dst，见 applyMergePatch。
+// 请求错误时直接返回 400 或 415 并返回 false。
+func bindMergePatch(c *gin.Context, current, dst interface{}) bool {
+	if ct := c.ContentType(); ct != MIMEMergePatch && ct != binding.MIMEJSON {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type 应为 " + MIMEMergePatch + " 或 " + binding.MIMEJSON})
+		return false
+	}
+	patch, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "读取请求体失败"})
+		return false
+	}
+	if err := applyMergePatch(current, patch, dst); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// etag 返回版本号对应的 ETag
+func etag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// setETag 在响应头中写入资源当前版本的 ETag
+func setETag(c *gin.Context, version uint) {
+	c.Header("ETag", etag(version))
+}
+
+// conflict 版本冲突时返回资源的当前表示和 ETag：请求带 If-Match 时为 412，
+// 没有 If-Match 但读取后被并发修改时为 409
+func conflict(c *gin.Context, version uint, current interface{}) {
+	if c.GetHeader("If-Match") != "" {
+		preconditionFailed(c, version, current)
+		return
+	}
+	setETag(c, version)
+	c.JSON(http.StatusConflict, current)
+}
+
+// preconditionFailed 客户端指定的版本与当前版本不一致，返回 412 以及资源的当前表示和 ETag
+func preconditionFailed(c *gin.Context, version uint, current interface{}) {
+	setETag(c, version)
+	c.JSON(http.StatusPreconditionFailed, current)
+}
+
+// ifMatch 解析 If-Match 请求头，返回客户端期望的版本号；没有该请求头或为 * 时返回 nil。
+// 弱 ETag（W/ 前缀）不能用于 If-Match，按不匹配处理。格式错误时直接返回 400 并返回 false
+func ifMatch(c *gin.Context) (*uint, bool) {
+	v := strings.TrimSpace(c.GetHeader("If-Match"))
+	if v == "" || v == "*" {
+		return nil, true
+	}
+	var version uint // 版本号从 1 开始，0 不会匹配任何版本
+	if !strings.HasPrefix(v, "W/") {
+		quoted := strings.HasPrefix(v, `"`) && strings.HasSuffix(v, `"`)
+		n, err := strconv.ParseUint(strings.Trim(v, `"`), 10, 64)
+		if !quoted || err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "If-Match 应为 GET 返回的 ETag，例如 \"3\""})
+			return nil, false
+		}
+		version = uint(n)
+	}
+	return &version, true
+}
+
+// notModified 请求的 If-None-Match 与当前版本一致时返回 304 并返回 true
+func notModified(c *gin.Context, version uint) bool {
+	v := strings.TrimSpace(c.GetHeader("If-None-Match"))
+	if v == "" {
+		return false
+	}
+	tag := etag(version)
+	for _, candidate := range strings.Split(v, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/PisaListBE/pkg/version"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 单个依赖检查的超时时间
+const readinessTimeout = 2 * time.Second
+
+// ReadinessCheck 依赖检查函数，返回 nil 表示依赖可用
+type ReadinessCheck func(ctx context.Context) error
+
+// HealthHandler 健康检查与版本信息接口
+type HealthHandler struct {
+	checks map[string]ReadinessCheck
+}
+
+// NewHealthHandler 创建健康检查处理器，默认检查数据库连接
+func NewHealthHandler(db *gorm.DB) *HealthHandler {
+	h := &HealthHandler{checks: make(map[string]ReadinessCheck)}
+	h.AddCheck("database", func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+	return h
+}
+
+// AddCheck 注册额外的依赖检查，需在服务启动前调用
+func (h *HealthHandler) AddCheck(name string, check ReadinessCheck) {
+	h.checks[name] = check
+}
+
+// @Summary 存活检查
+// @Description 进程存活即返回 200
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /healthz [get]
+func (h *HealthHandler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// @Summary 就绪检查
+// @Description 检查数据库等依赖是否可用，任一依赖不可用时返回 503
+// @Tags health
+// @Produce json
+// @Success 200 {object} object{status=string,checks=map[string]string}
+// @Failure 503 {object} object{status=string,checks=map[string]string}
+// @Router /readyz [get]
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	status := http.StatusOK
+	checks := make(map[string]string, len(h.checks))
+
+	for name, check := range h.checks {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+		err := check(ctx)
+		cancel()
+
+		if err != nil {
+			status = http.StatusServiceUnavailable
+			checks[name] = err.Error()
+			continue
+		}
+		checks[name] = "ok"
+	}
+
+	result := "ok"
+	if status != http.StatusOK {
+		result = "unavailable"
+	}
+	c.JSON(status, gin.H{"status": result, "checks": checks})
+}
+
+// @Summary 版本信息
+// @Description 返回构建时注入的版本、提交和构建时间
+// @Tags health
+// @Produce json
+// @Success 200 {object} object{version=string,commit=string,build_time=string,go_version=string}
+// @Router /version [get]
+func (h *HealthHandler) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"version":    version.Version,
+		"commit":     version.Commit,
+		"build_time": version.BuildTime,
+		"go_version": runtime.Version(),
+	})
+}
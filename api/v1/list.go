@@ -0,0 +1,187 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PisaListBE/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ListRequest 创建或更新清单的请求
+type ListRequest struct {
+	Name string `json:"name" binding:"required,max=64" example:"装修"`
+	// Color 十六进制颜色，可选
+	Color string `json:"color" binding:"omitempty,hexcolor,max=16" example:"#3366FF"`
+	// Icon 图标名称，由客户端定义
+	Icon string `json:"icon" binding:"max=32" example:"home"`
+	// SortOrder 排列顺序，升序；创建时省略则排在最后，更新时省略则不变
+	SortOrder *int `json:"sort_order" example:"1"`
+	// Archived 是否归档
+	Archived bool `json:"archived" example:"false"`
+}
+
+func (r ListRequest) input() service.ListInput {
+	return service.ListInput{
+		Name:      r.Name,
+		Color:     r.Color,
+		Icon:      r.Icon,
+		SortOrder: r.SortOrder,
+		Archived:  r.Archived,
+	}
+}
+
+// ListHandler 任务清单相关接口
+type ListHandler struct {
+	lists *service.ListService
+}
+
+// NewListHandler 创建清单接口处理器
+func NewListHandler(lists *service.ListService) *ListHandler {
+	return &ListHandler{lists: lists}
+}
+
+// listError 根据服务层错误返回响应，fallback 为未知错误时的提示
+func listError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrListNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "清单不存在"})
+		return
+	case errors.Is(err, service.ErrInvalidListName), errors.Is(err, service.ErrInvalidDeleteMode),
+		errors.Is(err, service.ErrInvalidMoveTarget):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
+
+// @Summary 获取清单列表
+// @Description 获取当前用户的清单，按 sort_order 排序，默认不包含已归档的清单
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param archived query bool false "是否包含已归档的清单"
+// @Success 200 {array} model.List
+// @Failure 500 {object} map[string]string
+// @Router /lists [get]
+func (h *ListHandler) GetLists(c *gin.Context) {
+	userID := c.GetUint("userID")
+	archived := c.Query("archived") == "true"
+
+	lists, err := h.lists.List(c.Request.Context(), userID, archived)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取清单失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, lists)
+}
+
+// @Summary 创建清单
+// @Description 创建一个任务清单
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param list body ListRequest true "清单信息"
+// @Success 200 {object} model.List
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /lists [post]
+func (h *ListHandler) CreateList(c *gin.Context) {
+	userID := c.GetUint("userID")
+	var req ListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := h.lists.Create(c.Request.Context(), userID, req.input())
+	if err != nil {
+		listError(c, err, "创建清单失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary 更新清单
+// @Description 更新清单的名称、颜色、图标、顺序和归档状态
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "清单ID"
+// @Param list body ListRequest true "清单信息"
+// @Success 200 {object} model.List
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /lists/{id} [put]
+func (h *ListHandler) UpdateList(c *gin.Context) {
+	userID := c.GetUint("userID")
+	listID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "清单不存在"})
+		return
+	}
+
+	var req ListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := h.lists.Update(c.Request.Context(), userID, listID, req.input())
+	if err != nil {
+		listError(c, err, "更新清单失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary 删除清单
+// @Description 删除清单。mode=move（默认）时把清单中的任务移到 target 清单，未指定 target 时移出清单；
+// @Description mode=cascade 时任务及其子任务一起移入回收站
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "清单ID"
+// @Param mode query string false "move 或 cascade" default(move)
+// @Param target query int false "mode=move 时的目标清单ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /lists/{id} [delete]
+func (h *ListHandler) DeleteList(c *gin.Context) {
+	userID := c.GetUint("userID")
+	listID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "清单不存在"})
+		return
+	}
+
+	mode := c.DefaultQuery("mode", service.DeleteListMove)
+	var target *uint
+	if v := c.Query("target"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "target 应为清单ID"})
+			return
+		}
+		t := uint(id)
+		target = &t
+	}
+
+	if err := h.lists.Delete(c.Request.Context(), userID, listID, mode, target); err != nil {
+		listError(c, err, "删除清单失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
@@ -0,0 +1,172 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PisaListBE/internal/service"
+	"github.com/PisaListBE/pkg/pagination"
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler 站内通知接口
+type NotificationHandler struct {
+	notifications *service.NotificationService
+}
+
+// NewNotificationHandler 创建站内通知接口处理器
+func NewNotificationHandler(notifications *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notifications: notifications}
+}
+
+// @Summary 获取通知列表
+// @Description 按时间倒序分页获取站内通知
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param unread query bool false "只返回未读通知"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认20，最大100"
+// @Success 200 {object} service.NotificationPage
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notifications [get]
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	q := service.NotificationQuery{Cursor: c.Query("cursor")}
+	if v := c.Query("unread"); v != "" {
+		unread, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unread 应为 true 或 false"})
+			return
+		}
+		q.UnreadOnly = unread
+	}
+	limit, err := pagination.ParseLimit(c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	q.Limit = limit
+
+	page, err := h.notifications.List(c.Request.Context(), userID, q)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取通知失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary 获取未读通知数量
+// @Description 获取未读通知数量，用于页头角标
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} object{count=int}
+// @Failure 500 {object} map[string]string
+// @Router /notifications/unread-count [get]
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	count, err := h.notifications.UnreadCount(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取未读通知数量失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"count": count})
+}
+
+// @Summary 标记通知已读
+// @Description 将一条通知标记为已读
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "通知ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notifications/{id}/read [put]
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	userID := c.GetUint("userID")
+	id, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "通知不存在"})
+		return
+	}
+
+	err := h.notifications.MarkRead(c.Request.Context(), userID, id)
+	if errors.Is(err, service.ErrNotificationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "通知不存在"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "标记已读失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已标记为已读"})
+}
+
+// @Summary 全部标记已读
+// @Description 将所有未读通知标记为已读
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} object{updated=int}
+// @Failure 500 {object} map[string]string
+// @Router /notifications/read [put]
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	updated, err := h.notifications.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "标记已读失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+// @Summary 删除通知
+// @Description 删除一条通知
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "通知ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notifications/{id} [delete]
+func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
+	userID := c.GetUint("userID")
+	id, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "通知不存在"})
+		return
+	}
+
+	err := h.notifications.Delete(c.Request.Context(), userID, id)
+	if errors.Is(err, service.ErrNotificationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "通知不存在"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除通知失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
@@ -0,0 +1,167 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/PisaListBE/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ReminderRequest 创建提醒的请求
+// @Description remind_at 与 offset_minutes 必须且只能指定一个
+type ReminderRequest struct {
+	// RemindAt 绝对提醒时间
+	RemindAt *time.Time `json:"remind_at" example:"2025-01-12T09:00:00+08:00"`
+	// OffsetMinutes 在任务截止时间前多少分钟提醒，最多提前30天
+	OffsetMinutes *int `json:"offset_minutes" binding:"omitempty,min=0,max=43200" example:"30"`
+	// Channels 逗号分隔的发送渠道：inbox、email、webhook，为空时使用服务端默认渠道
+	Channels string `json:"channels" binding:"max=64" example:"inbox,email"`
+}
+
+// ReminderHandler 任务提醒接口
+type ReminderHandler struct {
+	reminders *service.ReminderService
+}
+
+// NewReminderHandler 创建任务提醒接口处理器
+func NewReminderHandler(reminders *service.ReminderService) *ReminderHandler {
+	return &ReminderHandler{reminders: reminders}
+}
+
+// reminderError 根据服务层错误返回响应，fallback 为未知错误时的提示
+func reminderError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrTaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	case errors.Is(err, service.ErrReminderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "提醒不存在"})
+		return
+	case errors.Is(err, service.ErrInvalidReminder), errors.Is(err, service.ErrTaskHasNoDueDate),
+		errors.Is(err, service.ErrReminderInPast), errors.Is(err, service.ErrInvalidChannel):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrVersionConflict):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
+
+// @Summary 添加任务提醒
+// @Description 为任务添加提醒，可以指定绝对时间，也可以指定在截止时间前多少分钟提醒
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Param reminder body ReminderRequest true "提醒信息"
+// @Param If-Match header string false "任务的 ETag，版本不一致时返回 412"
+// @Success 200 {object} model.Reminder
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tasks/{id}/reminders [post]
+func (h *ReminderHandler) CreateReminder(c *gin.Context) {
+	userID := c.GetUint("userID")
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	var req ReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reminder, err := h.reminders.Create(c.Request.Context(), userID, taskID, service.ReminderInput{
+		RemindAt:      req.RemindAt,
+		OffsetMinutes: req.OffsetMinutes,
+		Channels:      req.Channels,
+		Version:       version,
+	})
+	if err != nil {
+		reminderError(c, err, "添加提醒失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, reminder)
+}
+
+// @Summary 获取任务提醒
+// @Description 获取任务的全部提醒及发送状态
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Success 200 {array} model.Reminder
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tasks/{id}/reminders [get]
+func (h *ReminderHandler) GetReminders(c *gin.Context) {
+	userID := c.GetUint("userID")
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+
+	reminders, err := h.reminders.List(c.Request.Context(), userID, taskID)
+	if err != nil {
+		reminderError(c, err, "获取提醒失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, reminders)
+}
+
+// @Summary 删除任务提醒
+// @Description 删除任务的一个提醒
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Param reminderId path string true "提醒ID"
+// @Param If-Match header string false "任务的 ETag，版本不一致时返回 412"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tasks/{id}/reminders/{reminderId} [delete]
+func (h *ReminderHandler) DeleteReminder(c *gin.Context) {
+	userID := c.GetUint("userID")
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+	reminderID, ok := parseID(c, "reminderId")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "提醒不存在"})
+		return
+	}
+
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := h.reminders.Delete(c.Request.Context(), userID, taskID, reminderID, version); err != nil {
+		reminderError(c, err, "删除提醒失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
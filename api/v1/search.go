@@ -0,0 +1,67 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/PisaListBE/internal/service"
+	"github.com/PisaListBE/pkg/pagination"
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler 全文搜索接口
+type SearchHandler struct {
+	search *service.SearchService
+}
+
+// NewSearchHandler 创建搜索接口处理器
+func NewSearchHandler(search *service.SearchService) *SearchHandler {
+	return &SearchHandler{search: search}
+}
+
+// @Summary 全文搜索
+// @Description 在当前用户的任务和心愿中搜索内容和描述，可同时搜索社区共享心愿，结果按相关度排序。
+// @Description event 和 description 为高亮片段，匹配部分用 <mark></mark> 包裹，其余内容已做 HTML 转义。
+// @Description 中文按两个字一组匹配，单个汉字的关键词可能搜不到结果
+// @Tags search
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param q query string true "搜索关键词，最多100个字符"
+// @Param community query bool false "同时搜索社区共享心愿"
+// @Param limit query int false "返回数量，默认20，最大100"
+// @Success 200 {array} search.Hit
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	community := false
+	if v := c.Query("community"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "community 应为 true 或 false"})
+			return
+		}
+		community = b
+	}
+	limit, err := pagination.ParseLimit(c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hits, err := h.search.Search(c.Request.Context(), userID, c.Query("q"), community, limit)
+	if errors.Is(err, service.ErrInvalidSearchQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "搜索失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, hits)
+}
@@ -0,0 +1,39 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/PisaListBE/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// StatsHandler 统计接口
+type StatsHandler struct {
+	stats *service.StatsService
+}
+
+// NewStatsHandler 创建统计接口处理器
+func NewStatsHandler(stats *service.StatsService) *StatsHandler {
+	return &StatsHandler{stats: stats}
+}
+
+// @Summary 获取习惯统计
+// @Description 获取每个循环任务的当前连续完成次数、最长连续次数以及最近7/30/365天的完成率
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} service.HabitStats
+// @Failure 500 {object} map[string]string
+// @Router /stats/habits [get]
+func (h *StatsHandler) GetHabitStats(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	stats, err := h.stats.Habits(c.Request.Context(), userID, userLocation(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取习惯统计失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,216 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/PisaListBE/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ChecklistRequest 创建或更新清单项的请求，更新时省略的字段保持不变
+type ChecklistRequest struct {
+	// Content 清单项内容，创建时必填
+	Content *string `json:"content" binding:"omitempty,max=256" example:"Milk"`
+	// Completed 是否已勾选
+	Completed *bool `json:"completed" example:"false"`
+	// Position 排序位置，升序；创建时省略则排在最后
+	Position *int `json:"position" example:"1"`
+}
+
+func (r ChecklistRequest) input() service.ChecklistInput {
+	return service.ChecklistInput{
+		Content:   r.Content,
+		Completed: r.Completed,
+		Position:  r.Position,
+	}
+}
+
+// checklistError 根据服务层错误返回响应，fallback 为未知错误时的提示
+func checklistError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrChecklistItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "清单项不存在"})
+		return
+	case errors.Is(err, service.ErrInvalidChecklistItem):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	taskError(c, err, fallback)
+}
+
+// @Summary 获取子任务
+// @Description 获取任务的清单项（按 position 排序）和直接子任务。子任务通过创建任务时指定 parent_id 添加
+// @Tags subtasks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Success 200 {object} service.SubtaskList
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tasks/{id}/subtasks [get]
+func (h *TaskHandler) GetSubtasks(c *gin.Context) {
+	userID := c.GetUint("userID")
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+
+	subtasks, err := h.tasks.Subtasks(c.Request.Context(), userID, taskID, userLocation(c))
+	if err != nil {
+		taskError(c, err, "获取子任务失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, subtasks)
+}
+
+// @Summary 添加清单项
+// @Description 在任务下添加一个清单项
+// @Tags subtasks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Param item body ChecklistRequest true "清单项"
+// @Param If-Match header string false "任务的 ETag，版本不一致时返回 412 和任务的当前表示"
+// @Success 200 {object} model.ChecklistItem
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} model.Task
+// @Failure 500 {object} map[string]string
+// @Router /tasks/{id}/subtasks [post]
+func (h *TaskHandler) CreateChecklistItem(c *gin.Context) {
+	userID := c.GetUint("userID")
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	var req ChecklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	in := req.input()
+	in.Version = version
+
+	item, err := h.tasks.AddChecklistItem(c.Request.Context(), userID, taskID, in, userLocation(c))
+	if errors.Is(err, service.ErrVersionConflict) {
+		h.taskConflict(c, userID, taskID)
+		return
+	}
+	if err != nil {
+		checklistError(c, err, "添加清单项失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// @Summary 更新清单项
+// @Description 更新清单项的内容、完成状态或位置，省略的字段保持不变
+// @Tags subtasks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Param itemId path string true "清单项ID"
+// @Param item body ChecklistRequest true "清单项"
+// @Param If-Match header string false "任务的 ETag，版本不一致时返回 412 和任务的当前表示"
+// @Success 200 {object} model.ChecklistItem
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} model.Task
+// @Failure 500 {object} map[string]string
+// @Router /tasks/{id}/subtasks/{itemId} [put]
+func (h *TaskHandler) UpdateChecklistItem(c *gin.Context) {
+	userID := c.GetUint("userID")
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+	itemID, ok := parseID(c, "itemId")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "清单项不存在"})
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	var req ChecklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	in := req.input()
+	in.Version = version
+
+	item, err := h.tasks.UpdateChecklistItem(c.Request.Context(), userID, taskID, itemID, in, userLocation(c))
+	if errors.Is(err, service.ErrVersionConflict) {
+		h.taskConflict(c, userID, taskID)
+		return
+	}
+	if err != nil {
+		checklistError(c, err, "更新清单项失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// @Summary 删除清单项
+// @Description 删除任务的一个清单项
+// @Tags subtasks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Param itemId path string true "清单项ID"
+// @Param If-Match header string false "任务的 ETag，版本不一致时返回 412 和任务的当前表示"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} model.Task
+// @Failure 500 {object} map[string]string
+// @Router /tasks/{id}/subtasks/{itemId} [delete]
+func (h *TaskHandler) DeleteChecklistItem(c *gin.Context) {
+	userID := c.GetUint("userID")
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+	itemID, ok := parseID(c, "itemId")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "清单项不存在"})
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	err := h.tasks.DeleteChecklistItem(c.Request.Context(), userID, taskID, itemID, version, userLocation(c))
+	if errors.Is(err, service.ErrVersionConflict) {
+		h.taskConflict(c, userID, taskID)
+		return
+	}
+	if err != nil {
+		checklistError(c, err, "删除清单项失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
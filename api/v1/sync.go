@@ -0,0 +1,483 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/service"
+	"github.com/PisaListBE/pkg/pagination"
+	"github.com/gin-gonic/gin"
+)
+
+// 同步操作，其余为 delete
+const (
+	syncCreate = "create"
+	syncUpdate = "update"
+)
+
+// 同步操作的结果
+const (
+	// SyncApplied 操作已应用，重复提交的创建操作也返回 applied
+	SyncApplied = "applied"
+	// SyncConflict 记录在客户端修改之后被其他设备修改过，data 为服务器上的当前记录
+	SyncConflict = "conflict"
+	// SyncNotFound 要更新或删除的记录不存在或已删除；创建时 client_id 对应的记录已删除也返回 not_found
+	SyncNotFound = "not_found"
+	// SyncInvalid 操作的数据无效，重试也不会成功
+	SyncInvalid = "invalid"
+	// SyncError 服务器错误，可以稍后重试
+	SyncError = "error"
+)
+
+// SyncMutation 客户端离线时产生的一个修改
+type SyncMutation struct {
+	// ClientID 客户端生成的记录ID：创建时必填，重复提交时返回已创建的记录；更新和删除时省略 id 则按它查找记录
+	ClientID string `json:"client_id" binding:"max=64" example:"7b0e4c1a-9d2f-4e3b-8a61-0c5d2f9e1a34"`
+	// Type 记录类型：task、wish、list 或 tag
+	Type string `json:"type" binding:"required,oneof=task wish list tag" example:"task"`
+	// Op 操作：create、update 或 delete
+	Op string `json:"op" binding:"required,oneof=create update delete" example:"update"`
+	// ID 要更新或删除的记录的服务端ID
+	ID uint `json:"id" example:"12"`
+	// Version 客户端修改时基于的版本号，任务和心愿与当前版本不一致时返回 conflict；省略时不校验
+	Version *uint `json:"version" example:"3"`
+	// Data 创建时为完整的记录（同 POST /tasks 等接口的请求体），更新时为 JSON Merge Patch，删除时省略。
+	// 任务可以用 list_client_id、parent_client_id 引用尚未拿到服务端ID的清单和父任务，
+	// 任务和心愿可以用 tag_client_ids 引用标签，与 tag_ids 合并
+	Data json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// SyncRequest 批量提交离线修改的请求
+type SyncRequest struct {
+	// Mutations 按顺序逐个应用，每个操作单独成功或失败，后面的操作可以引用前面创建的记录
+	Mutations []SyncMutation `json:"mutations" binding:"required,max=100,dive"`
+}
+
+// SyncResult 一个修改的处理结果，与请求中的操作一一对应
+type SyncResult struct {
+	ClientID string `json:"client_id,omitempty" example:"7b0e4c1a-9d2f-4e3b-8a61-0c5d2f9e1a34"`
+	Type     string `json:"type" example:"task"`
+	Op       string `json:"op" example:"update"`
+	// Status applied、conflict、not_found、invalid 或 error
+	Status string `json:"status" example:"applied"`
+	// ID 记录的服务端ID
+	ID uint `json:"id,omitempty" example:"12"`
+	// Data 应用后的记录，冲突时为服务器上的当前记录，删除时为空
+	Data interface{} `json:"data,omitempty" swaggertype:"object"`
+	// Error status 为 invalid 或 error 时的原因
+	Error string `json:"error,omitempty"`
+}
+
+// SyncResponse 批量提交离线修改的结果
+type SyncResponse struct {
+	Results []SyncResult `json:"results"`
+}
+
+// invalidData 修改的数据无效，结果为 invalid
+type invalidData struct{ error }
+
+// syncNotFound 各类型记录不存在时服务层返回的错误
+var syncNotFound = map[string]error{
+	service.SyncTask: service.ErrTaskNotFound,
+	service.SyncWish: service.ErrWishNotFound,
+	service.SyncList: service.ErrListNotFound,
+	service.SyncTag:  service.ErrTagNotFound,
+}
+
+// syncInvalidErrors 服务层校验失败的错误，对应单独接口中的 400 和标签重名的 409
+var syncInvalidErrors = []error{
+	service.ErrInvalidRecurrence, service.ErrInvalidTaskDates, service.ErrInvalidParent,
+	service.ErrTagNotFound, service.ErrListNotFound, service.ErrInvalidListName,
+	service.ErrInvalidTagName, service.ErrTagExists,
+}
+
+// syncRef 数据中用客户端ID引用其他记录的字段，应用前替换为服务端ID
+type syncRef struct {
+	// field 客户端ID字段
+	field string
+	// target 替换成的服务端ID字段
+	target string
+	// typ 被引用记录的类型
+	typ string
+	// many 为 true 时是ID列表
+	many bool
+}
+
+var syncRefs = map[string][]syncRef{
+	service.SyncTask: {
+		{field: "list_client_id", target: "list_id", typ: service.SyncList},
+		{field: "parent_client_id", target: "parent_id", typ: service.SyncTask},
+		{field: "tag_client_ids", target: "tag_ids", typ: service.SyncTag, many: true},
+	},
+	service.SyncWish: {
+		{field: "tag_client_ids", target: "tag_ids", typ: service.SyncTag, many: true},
+	},
+}
+
+// SyncHandler 离线客户端的增量同步接口
+type SyncHandler struct {
+	sync   *service.SyncService
+	tasks  *service.TaskService
+	wishes *service.WishService
+	lists  *service.ListService
+	tags   *service.TagService
+}
+
+// NewSyncHandler 创建同步接口处理器
+func NewSyncHandler(sync *service.SyncService, tasks *service.TaskService, wishes *service.WishService, lists *service.ListService, tags *service.TagService) *SyncHandler {
+	return &SyncHandler{sync: sync, tasks: tasks, wishes: wishes, lists: lists, tags: tags}
+}
+
+// @Summary 拉取增量变更
+// @Description 返回游标之后创建、修改或删除的任务、心愿、清单和标签，以及下次同步使用的游标。
+// @Description 删除的记录（包括移入回收站的）只返回ID；游标之前一分钟内的变更会重复返回，客户端按 id 覆盖即可。
+// @Description 省略 since 或游标早于回收站保留期限时返回全部数据且 reset 为 true，客户端应清空本地数据后重建。
+// @Description 删除标签不会修改任务和心愿，客户端收到标签的删除记录后需自行从本地记录上移除
+// @Tags sync
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param since query string false "上次同步返回的 cursor"
+// @Success 200 {object} service.SyncChanges
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /sync [get]
+func (h *SyncHandler) GetChanges(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	changes, err := h.sync.Changes(c.Request.Context(), userID, c.Query("since"), userLocation(c))
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的同步游标"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取变更失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// @Summary 提交离线修改
+// @Description 按顺序应用客户端离线时产生的修改，每个修改单独返回结果，一个失败不影响其他修改。
+// @Description 创建时 client_id 必填，重复提交同一个创建操作不会重复创建；后面的修改可以用 client_id 引用前面创建的记录。
+// @Description 任务和心愿的更新、删除带 version 时按版本号检测冲突，冲突时返回服务器上的当前记录；清单和标签以最后一次写入为准。
+// @Description 删除清单时清单中的任务移出清单
+// @Tags sync
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param mutations body SyncRequest true "离线修改，最多100个"
+// @Success 200 {object} SyncResponse
+// @Failure 400 {object} map[string]string
+// @Router /sync [post]
+func (h *SyncHandler) PushMutations(c *gin.Context) {
+	userID := c.GetUint("userID")
+	var req SyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	loc := userLocation(c)
+	results := make([]SyncResult, 0, len(req.Mutations))
+	for _, m := range req.Mutations {
+		results = append(results, h.apply(ctx, userID, m, loc))
+	}
+
+	c.JSON(http.StatusOK, SyncResponse{Results: results})
+}
+
+// apply 应用一个修改并返回结果
+func (h *SyncHandler) apply(ctx context.Context, userID uint, m SyncMutation, loc *time.Location) SyncResult {
+	res := SyncResult{ClientID: m.ClientID, Type: m.Type, Op: m.Op, ID: m.ID}
+
+	switch {
+	case m.Op == syncCreate && m.ClientID == "":
+		return h.failed(ctx, userID, res, invalidData{errors.New("创建时 client_id 不能为空")}, loc)
+	case m.Op != syncCreate && m.ID == 0:
+		if m.ClientID == "" {
+			return h.failed(ctx, userID, res, invalidData{errors.New("更新和删除时需要 id 或 client_id")}, loc)
+		}
+		id, err := h.sync.Resolve(ctx, userID, m.Type, m.ClientID)
+		if err != nil {
+			return h.failed(ctx, userID, res, err, loc)
+		}
+		res.ID = id
+	}
+
+	data, err := h.resolveRefs(ctx, userID, m.Type, m.Data)
+	if err != nil {
+		return h.failed(ctx, userID, res, err, loc)
+	}
+	if m.Op == syncUpdate && len(data) == 0 {
+		data = []byte("{}")
+	}
+
+	var record interface{}
+	switch m.Type {
+	case service.SyncTask:
+		record, err = h.applyTask(ctx, userID, res.ID, m, data, loc)
+	case service.SyncWish:
+		record, err = h.applyWish(ctx, userID, res.ID, m, data)
+	case service.SyncList:
+		record, err = h.applyList(ctx, userID, res.ID, m, data)
+	case service.SyncTag:
+		record, err = h.applyTag(ctx, userID, res.ID, m, data)
+	}
+	if err != nil {
+		return h.failed(ctx, userID, res, err, loc)
+	}
+
+	res.Status = SyncApplied
+	switch r := record.(type) {
+	case *model.Task:
+		res.ID, res.Data = r.ID, r
+	case *model.Wish:
+		res.ID, res.Data = r.ID, r
+	case *model.List:
+		res.ID, res.Data = r.ID, r
+	case *model.Tag:
+		res.ID, res.Data = r.ID, r
+	}
+	return res
+}
+
+// failed 根据错误设置结果状态，版本冲突时附上服务器上的当前记录
+func (h *SyncHandler) failed(ctx context.Context, userID uint, res SyncResult, err error, loc *time.Location) SyncResult {
+	var invalid invalidData
+	switch {
+	case errors.As(err, &invalid):
+		res.Status, res.Error = SyncInvalid, invalid.Error()
+	case errors.Is(err, service.ErrVersionConflict):
+		res.Status = SyncConflict
+		switch res.Type {
+		case service.SyncTask:
+			if task, err := h.tasks.Get(ctx, userID, res.ID, loc); err == nil {
+				res.Data = task
+			}
+		case service.SyncWish:
+			if wish, err := h.wishes.Get(ctx, userID, res.ID); err == nil {
+				res.Data = wish
+			}
+		}
+	case errors.Is(err, syncNotFound[res.Type]):
+		res.Status = SyncNotFound
+	default:
+		res.Status, res.Error = SyncError, "同步失败"
+		for _, target := range syncInvalidErrors {
+			if errors.Is(err, target) {
+				res.Status, res.Error = SyncInvalid, err.Error()
+				break
+			}
+		}
+	}
+	return res
+}
+
+// resolveRefs 把数据中用客户端ID引用的记录替换为服务端ID，引用的记录不存在时返回 invalidData
+func (h *SyncHandler) resolveRefs(ctx context.Context, userID uint, typ string, data json.RawMessage) (json.RawMessage, error) {
+	refs := syncRefs[typ]
+	if len(refs) == 0 || len(data) == 0 {
+		return data, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return nil, invalidData{errors.New("data 应为 JSON 对象")}
+	}
+
+	resolve := func(ref syncRef, clientID string) (uint, error) {
+		id, err := h.sync.Resolve(ctx, userID, ref.typ, clientID)
+		if errors.Is(err, syncNotFound[ref.typ]) {
+			return 0, invalidData{fmt.Errorf("%s 引用的记录 %s 不存在", ref.field, clientID)}
+		}
+		return id, err
+	}
+
+	changed := false
+	for _, ref := range refs {
+		raw, ok := fields[ref.field]
+		if !ok {
+			continue
+		}
+		delete(fields, ref.field)
+		changed = true
+
+		var value interface{}
+		if ref.many {
+			var clientIDs []string
+			if err := json.Unmarshal(raw, &clientIDs); err != nil {
+				return nil, invalidData{fmt.Errorf("%s 应为字符串数组", ref.field)}
+			}
+			ids := []uint{}
+			if existing, ok := fields[ref.target]; ok {
+				if err := json.Unmarshal(existing, &ids); err != nil {
+					return nil, invalidData{fmt.Errorf("%s 应为ID数组", ref.target)}
+				}
+			}
+			for _, clientID := range clientIDs {
+				id, err := resolve(ref, clientID)
+				if err != nil {
+					return nil, err
+				}
+				ids = append(ids, id)
+			}
+			value = ids
+		} else {
+			var clientID *string
+			if err := json.Unmarshal(raw, &clientID); err != nil {
+				return nil, invalidData{fmt.Errorf("%s 应为字符串", ref.field)}
+			}
+			if clientID != nil {
+				id, err := resolve(ref, *clientID)
+				if err != nil {
+					return nil, err
+				}
+				value = id
+			}
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[ref.target] = encoded
+	}
+	if !changed {
+		return data, nil
+	}
+	return json.Marshal(fields)
+}
+
+// decodeSyncData 把 data 解析到 dst，current 不为 nil 时 data 作为 JSON Merge Patch 应用到 current 上
+func decodeSyncData(current interface{}, data []byte, dst interface{}) error {
+	if err := applyMergePatch(current, data, dst); err != nil {
+		return invalidData{err}
+	}
+	return nil
+}
+
+// checkSyncVersion 客户端修改时基于的版本与当前版本不一致时返回 ErrVersionConflict
+func checkSyncVersion(current uint, expected *uint) error {
+	if expected != nil && *expected != current {
+		return service.ErrVersionConflict
+	}
+	return nil
+}
+
+func (h *SyncHandler) applyTask(ctx context.Context, userID, id uint, m SyncMutation, data []byte, loc *time.Location) (interface{}, error) {
+	switch m.Op {
+	case syncCreate:
+		var req TaskRequest
+		if err := decodeSyncData(nil, data, &req); err != nil {
+			return nil, err
+		}
+		in := req.input()
+		in.ClientID = m.ClientID
+		return h.tasks.Create(ctx, userID, in, loc)
+	case syncUpdate:
+		task, err := h.tasks.Get(ctx, userID, id, loc)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkSyncVersion(task.Version, m.Version); err != nil {
+			return nil, err
+		}
+		var req TaskRequest
+		if err := decodeSyncData(taskRequest(task), data, &req); err != nil {
+			return nil, err
+		}
+		if req.TagIDs == nil {
+			req.TagIDs = []uint{}
+		}
+		in := req.input()
+		in.Version = &task.Version
+		return h.tasks.Update(ctx, userID, id, in, loc)
+	}
+	return nil, h.tasks.Delete(ctx, userID, id, m.Version, loc)
+}
+
+func (h *SyncHandler) applyWish(ctx context.Context, userID, id uint, m SyncMutation, data []byte) (interface{}, error) {
+	switch m.Op {
+	case syncCreate:
+		var req WishRequest
+		if err := decodeSyncData(nil, data, &req); err != nil {
+			return nil, err
+		}
+		in := req.input()
+		in.ClientID = m.ClientID
+		return h.wishes.Create(ctx, userID, in)
+	case syncUpdate:
+		wish, err := h.wishes.Get(ctx, userID, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkSyncVersion(wish.Version, m.Version); err != nil {
+			return nil, err
+		}
+		var req WishRequest
+		if err := decodeSyncData(wishRequest(wish), data, &req); err != nil {
+			return nil, err
+		}
+		if req.TagIDs == nil {
+			req.TagIDs = []uint{}
+		}
+		in := req.input()
+		in.Version = &wish.Version
+		return h.wishes.Update(ctx, userID, id, in)
+	}
+	return nil, h.wishes.Delete(ctx, userID, id, m.Version)
+}
+
+func (h *SyncHandler) applyList(ctx context.Context, userID, id uint, m SyncMutation, data []byte) (interface{}, error) {
+	switch m.Op {
+	case syncCreate:
+		var req ListRequest
+		if err := decodeSyncData(nil, data, &req); err != nil {
+			return nil, err
+		}
+		in := req.input()
+		in.ClientID = m.ClientID
+		return h.lists.Create(ctx, userID, in)
+	case syncUpdate:
+		list, err := h.lists.Get(ctx, userID, id)
+		if err != nil {
+			return nil, err
+		}
+		req := ListRequest{Name: list.Name, Color: list.Color, Icon: list.Icon, SortOrder: &list.SortOrder, Archived: list.Archived}
+		if err := decodeSyncData(req, data, &req); err != nil {
+			return nil, err
+		}
+		return h.lists.Update(ctx, userID, id, req.input())
+	}
+	return nil, h.lists.Delete(ctx, userID, id, service.DeleteListMove, nil)
+}
+
+func (h *SyncHandler) applyTag(ctx context.Context, userID, id uint, m SyncMutation, data []byte) (interface{}, error) {
+	switch m.Op {
+	case syncCreate:
+		var req TagRequest
+		if err := decodeSyncData(nil, data, &req); err != nil {
+			return nil, err
+		}
+		in := req.input()
+		in.ClientID = m.ClientID
+		return h.tags.Create(ctx, userID, in)
+	case syncUpdate:
+		tag, err := h.tags.Get(ctx, userID, id)
+		if err != nil {
+			return nil, err
+		}
+		req := TagRequest{Name: tag.Name, Color: tag.Color}
+		if err := decodeSyncData(req, data, &req); err != nil {
+			return nil, err
+		}
+		return h.tags.Update(ctx, userID, id, req.input())
+	}
+	return nil, h.tags.Delete(ctx, userID, id)
+}
@@ -0,0 +1,160 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/PisaListBE/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// TagRequest 创建或更新标签的请求
+type TagRequest struct {
+	Name string `json:"name" binding:"required,max=64" example:"旅行"`
+	// Color 十六进制颜色，可选
+	Color string `json:"color" binding:"omitempty,hexcolor,max=16" example:"#FF8800"`
+}
+
+func (r TagRequest) input() service.TagInput {
+	return service.TagInput{Name: r.Name, Color: r.Color}
+}
+
+// TagHandler 标签相关接口
+type TagHandler struct {
+	tags *service.TagService
+}
+
+// NewTagHandler 创建标签接口处理器
+func NewTagHandler(tags *service.TagService) *TagHandler {
+	return &TagHandler{tags: tags}
+}
+
+// tagError 根据服务层错误返回响应，fallback 为未知错误时的提示
+func tagError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrTagNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "标签不存在"})
+		return
+	case errors.Is(err, service.ErrTagExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrInvalidTagName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
+
+// @Summary 获取标签列表
+// @Description 获取当前用户的所有标签，按名称排序
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} model.Tag
+// @Failure 500 {object} map[string]string
+// @Router /tags [get]
+func (h *TagHandler) GetTags(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	tags, err := h.tags.List(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取标签失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// @Summary 创建标签
+// @Description 创建一个标签，同一用户下名称不能重复
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param tag body TagRequest true "标签信息"
+// @Success 200 {object} model.Tag
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tags [post]
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	userID := c.GetUint("userID")
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.tags.Create(c.Request.Context(), userID, req.input())
+	if err != nil {
+		tagError(c, err, "创建标签失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// @Summary 更新标签
+// @Description 更新标签的名称和颜色
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "标签ID"
+// @Param tag body TagRequest true "标签信息"
+// @Success 200 {object} model.Tag
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tags/{id} [put]
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	userID := c.GetUint("userID")
+	tagID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "标签不存在"})
+		return
+	}
+
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.tags.Update(c.Request.Context(), userID, tagID, req.input())
+	if err != nil {
+		tagError(c, err, "更新标签失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// @Summary 删除标签
+// @Description 删除标签，同时从所有任务和心愿上移除该标签
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "标签ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tags/{id} [delete]
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	userID := c.GetUint("userID")
+	tagID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "标签不存在"})
+		return
+	}
+
+	if err := h.tags.Delete(c.Request.Context(), userID, tagID); err != nil {
+		tagError(c, err, "删除标签失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
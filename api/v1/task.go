@@ -1,11 +1,14 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/PisaListBE/internal/model"
-	"github.com/PisaListBE/pkg/database"
+	"github.com/PisaListBE/internal/service"
+	"github.com/PisaListBE/pkg/pagination"
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,6 +25,189 @@ type TaskRequest struct {
 	Description     string `json:"description" example:"Milk, eggs, bread"`
 	IsCycle         bool   `json:"is_cycle" example:"false"`
 	ImportanceLevel int    `json:"importance_level" binding:"min=0,max=5" example:"3"`
+	// Recurrence 重复规则：daily、weekly、monthly 简写或 RRULE 子集，如 FREQ=DAILY;INTERVAL=3
+	Recurrence string `json:"recurrence" binding:"max=255" example:"FREQ=WEEKLY;BYDAY=MO,WE,FR"`
+	// StartAt 开始时间，之前不出现在今日任务中
+	StartAt *time.Time `json:"start_at" example:"2025-01-10T09:00:00+08:00"`
+	// DueAt 截止时间
+	DueAt *time.Time `json:"due_at" example:"2025-01-12T18:00:00+08:00"`
+	// ParentID 父任务ID，设置后作为该任务的子任务
+	ParentID *uint `json:"parent_id" example:"1"`
+	// AutoComplete 所有子任务和清单项完成后自动完成
+	AutoComplete bool `json:"auto_complete" example:"false"`
+	// TagIDs 标签ID列表，PUT 时省略表示清空
+	TagIDs []uint `json:"tag_ids" example:"1,2"`
+	// ListID 所属清单ID，创建子任务时省略则沿用父任务的清单
+	ListID *uint `json:"list_id" example:"1"`
+}
+
+func (r TaskRequest) input() service.TaskInput {
+	return service.TaskInput{
+		Event:           r.Event,
+		Description:     r.Description,
+		IsCycle:         r.IsCycle,
+		ImportanceLevel: r.ImportanceLevel,
+		Recurrence:      r.Recurrence,
+		StartAt:         r.StartAt,
+		DueAt:           r.DueAt,
+		ParentID:        r.ParentID,
+		AutoComplete:    r.AutoComplete,
+		TagIDs:          r.TagIDs,
+		ListID:          r.ListID,
+	}
+}
+
+// taskRequest 返回任务当前值对应的请求，作为 PATCH 合并的基础。
+// Recurrence 留空，未修改重复规则时与 PUT 省略 recurrence 一样：is_cycle 为 true 沿用当前规则，为 false 清除规则
+func taskRequest(task *model.Task) TaskRequest {
+	tagIDs := make([]uint, 0, len(task.Tags))
+	for _, tag := range task.Tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	return TaskRequest{
+		Event:           task.Event,
+		Description:     task.Description,
+		IsCycle:         task.IsCycle,
+		ImportanceLevel: task.ImportanceLevel,
+		StartAt:         task.StartAt,
+		DueAt:           task.DueAt,
+		ParentID:        task.ParentID,
+		AutoComplete:    task.AutoComplete,
+		TagIDs:          tagIDs,
+		ListID:          task.ListID,
+	}
+}
+
+// taskFilter 解析任务列表的 tag 和 list_id 查询参数，list_id=0 表示不属于任何清单的任务。
+// 参数错误时直接返回 400 并返回 false。
+func taskFilter(c *gin.Context) (service.TaskFilter, bool) {
+	f := service.TaskFilter{Tag: c.Query("tag")}
+	if v := c.Query("list_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "list_id 应为清单ID"})
+			return f, false
+		}
+		listID := uint(id)
+		f.ListID = &listID
+	}
+	return f, true
+}
+
+// taskSort 解析 sort/order 查询参数。order 默认升序，按重要性排序时默认降序（优先级高的在前）。
+// 参数错误时直接返回 400 并返回 false。
+func taskSort(c *gin.Context) (service.TaskSort, bool) {
+	by := service.TaskSort{Field: c.Query("sort")}
+	if by.Field != "" && !service.ValidSortField(by.Field) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort 应为 position、importance、due_at 或 created_at"})
+		return by, false
+	}
+	switch c.Query("order") {
+	case "":
+		by.Desc = by.Field == service.SortImportance
+	case "asc":
+	case "desc":
+		by.Desc = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order 应为 asc 或 desc"})
+		return by, false
+	}
+	return by, true
+}
+
+// MoveTaskRequest 移动任务的请求，至少指定一个相邻任务
+type MoveTaskRequest struct {
+	// AfterID 移动后排在该任务之后
+	AfterID *uint `json:"after_id" example:"3"`
+	// BeforeID 移动后排在该任务之前
+	BeforeID *uint `json:"before_id" example:"4"`
+}
+
+// TaskHandler 任务相关接口
+type TaskHandler struct {
+	tasks *service.TaskService
+}
+
+// NewTaskHandler 创建任务接口处理器
+func NewTaskHandler(tasks *service.TaskService) *TaskHandler {
+	return &TaskHandler{tasks: tasks}
+}
+
+// taskError 根据服务层错误返回响应，fallback 为未知错误时的提示
+func taskError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrTaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	case errors.Is(err, service.ErrInvalidRecurrence), errors.Is(err, service.ErrInvalidTaskDates),
+		errors.Is(err, service.ErrInvalidParent), errors.Is(err, service.ErrTagNotFound),
+		errors.Is(err, service.ErrListNotFound), errors.Is(err, service.ErrInvalidPosition):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrVersionConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
+
+// taskConflict 版本冲突时返回任务的当前表示和 ETag：请求带 If-Match 时为 412，
+// 没有 If-Match 但读取后被并发修改时为 409
+func (h *TaskHandler) taskConflict(c *gin.Context, userID, id uint) {
+	task, err := h.tasks.Get(c.Request.Context(), userID, id, userLocation(c))
+	if err != nil {
+		taskError(c, err, "获取任务失败")
+		return
+	}
+	conflict(c, task.Version, task)
+}
+
+// deletedTaskConflict 恢复时版本冲突，返回回收站中任务的当前表示和 ETag
+func (h *TaskHandler) deletedTaskConflict(c *gin.Context, userID, id uint) {
+	task, err := h.tasks.GetDeleted(c.Request.Context(), userID, id, userLocation(c))
+	if errors.Is(err, service.ErrTaskNotInTrash) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "回收站中不存在该任务"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取任务失败"})
+		return
+	}
+	conflict(c, task.Version, task)
+}
+
+// @Summary 获取任务
+// @Description 获取任务详情，响应头 ETag 为任务的当前版本，可用于 If-Match；If-None-Match 与当前版本一致时返回 304
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Param If-None-Match header string false "上次获取的 ETag"
+// @Success 200 {object} model.Task
+// @Success 304
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tasks/{id} [get]
+func (h *TaskHandler) GetTask(c *gin.Context) {
+	userID := c.GetUint("userID")
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+
+	task, err := h.tasks.Get(c.Request.Context(), userID, taskID, userLocation(c))
+	if err != nil {
+		taskError(c, err, "获取任务失败")
+		return
+	}
+
+	setETag(c, task.Version)
+	if notModified(c, task.Version) {
+		return
+	}
+	c.JSON(http.StatusOK, task)
 }
 
 // @Summary 创建任务
@@ -35,7 +221,7 @@ type TaskRequest struct {
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /tasks [post]
-func CreateTask(c *gin.Context) {
+func (h *TaskHandler) CreateTask(c *gin.Context) {
 	userID := c.GetUint("userID")
 	var req TaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -43,45 +229,49 @@ func CreateTask(c *gin.Context) {
 		return
 	}
 
-	task := model.Task{
-		UserID:          userID,
-		Event:           req.Event,
-		Description:     req.Description,
-		IsCycle:         req.IsCycle,
-		ImportanceLevel: req.ImportanceLevel,
-	}
-
-	if err := database.GormDB.Create(&task).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建任务失败"})
+	task, err := h.tasks.Create(c.Request.Context(), userID, req.input(), userLocation(c))
+	if err != nil {
+		taskError(c, err, "创建任务失败")
 		return
 	}
 
+	setETag(c, task.Version)
 	c.JSON(http.StatusOK, task)
 }
 
 // @Summary 删除任务
-// @Description 根据ID删除任务
+// @Description 根据ID删除任务，子任务一起移入回收站
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path string true "任务ID"
+// @Param If-Match header string false "任务的 ETag，版本不一致时返回 412 和任务的当前表示"
 // @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 412 {object} model.Task
 // @Failure 500 {object} map[string]string
 // @Router /tasks/{id} [delete]
-func DeleteTask(c *gin.Context) {
+func (h *TaskHandler) DeleteTask(c *gin.Context) {
 	userID := c.GetUint("userID")
-	taskID := c.Param("id")
-
-	var task model.Task
-	if err := database.GormDB.Where("id = ? AND user_id = ?", taskID, userID).First(&task).Error; err != nil {
+	taskID, ok := parseID(c, "id")
+	if !ok {
 		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
 		return
 	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
 
-	if err := database.GormDB.Delete(&task).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除任务失败"})
+	err := h.tasks.Delete(c.Request.Context(), userID, taskID, version, userLocation(c))
+	if errors.Is(err, service.ErrVersionConflict) {
+		h.taskConflict(c, userID, taskID)
+		return
+	}
+	if err != nil {
+		taskError(c, err, "删除任务失败")
 		return
 	}
 
@@ -89,46 +279,122 @@ func DeleteTask(c *gin.Context) {
 }
 
 // @Summary 更新任务
-// @Description 更新任务信息
+// @Description 用请求替换任务的全部内容，省略的字段按零值处理（start_at、due_at、parent_id、list_id 清除，tag_ids 清空）。只修改部分字段请使用 PATCH
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path string true "任务ID"
 // @Param task body TaskRequest true "更新后的任务信息"
+// @Param If-Match header string false "任务的 ETag，版本不一致时返回 412 和任务的当前表示"
 // @Success 200 {object} model.Task
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 412 {object} model.Task
 // @Failure 500 {object} map[string]string
 // @Router /tasks/{id} [put]
-func UpdateTask(c *gin.Context) {
+func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	userID := c.GetUint("userID")
-	taskID := c.Param("id")
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
 
 	var req TaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.TagIDs == nil {
+		// PUT 整体替换任务，省略 tag_ids 与其他字段一样表示清空
+		req.TagIDs = []uint{}
+	}
+	in := req.input()
+	in.Version = version
+
+	task, err := h.tasks.Update(c.Request.Context(), userID, taskID, in, userLocation(c))
+	if errors.Is(err, service.ErrVersionConflict) {
+		h.taskConflict(c, userID, taskID)
+		return
+	}
+	if err != nil {
+		taskError(c, err, "更新任务失败")
+		return
+	}
+
+	setETag(c, task.Version)
+	c.JSON(http.StatusOK, task)
+}
 
-	var task model.Task
-	if err := database.GormDB.Where("id = ? AND user_id = ?", taskID, userID).First(&task).Error; err != nil {
+// @Summary 部分更新任务
+// @Description 使用 JSON Merge Patch（RFC 7396）只更新请求中出现的字段，字段设为 null 表示清空（event 不能清空）。
+// @Description 只修改 is_cycle 时：设为 true 沿用当前重复规则（没有则按每天重复），设为 false 清除重复规则
+// @Tags tasks
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Param task body TaskRequest true "需要修改的字段"
+// @Param If-Match header string false "任务的 ETag，版本不一致时返回 412 和任务的当前表示"
+// @Success 200 {object} model.Task
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} model.Task
+// @Failure 412 {object} model.Task
+// @Failure 415 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tasks/{id} [patch]
+func (h *TaskHandler) PatchTask(c *gin.Context) {
+	userID := c.GetUint("userID")
+	taskID, ok := parseID(c, "id")
+	if !ok {
 		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
 		return
 	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
 
-	updates := map[string]interface{}{
-		"event":       req.Event,
-		"description": req.Description,
-		"is_cycle":    req.IsCycle,
-		// "importance_level": req.ImportanceLevel,
+	loc := userLocation(c)
+	task, err := h.tasks.Get(c.Request.Context(), userID, taskID, loc)
+	if err != nil {
+		taskError(c, err, "更新任务失败")
+		return
+	}
+	if version != nil && *version != task.Version {
+		h.taskConflict(c, userID, taskID)
+		return
+	}
+	var req TaskRequest
+	if !bindMergePatch(c, taskRequest(task), &req) {
+		return
+	}
+	if req.TagIDs == nil {
+		// tag_ids 为 null 表示清空标签，而 TaskInput 中的 nil 表示不修改
+		req.TagIDs = []uint{}
 	}
+	// 补丁基于读取到的版本合并，之后被其他请求修改过时不能覆盖
+	in := req.input()
+	in.Version = &task.Version
 
-	if err := database.GormDB.Model(&task).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新任务失败"})
+	task, err = h.tasks.Update(c.Request.Context(), userID, taskID, in, loc)
+	if errors.Is(err, service.ErrVersionConflict) {
+		h.taskConflict(c, userID, taskID)
+		return
+	}
+	if err != nil {
+		taskError(c, err, "更新任务失败")
 		return
 	}
 
+	setETag(c, task.Version)
 	c.JSON(http.StatusOK, task)
 }
 
@@ -139,91 +405,131 @@ func UpdateTask(c *gin.Context) {
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path string true "任务ID"
+// @Param If-Match header string false "任务的 ETag，版本不一致时返回 412 和任务的当前表示"
 // @Success 200 {object} model.Task
 // @Failure 404 {object} map[string]string
+// @Failure 412 {object} model.Task
 // @Failure 500 {object} map[string]string
 // @Router /tasks/{id}/complete [put]
-func CompleteTask(c *gin.Context) {
+func (h *TaskHandler) CompleteTask(c *gin.Context) {
 	userID := c.GetUint("userID")
-	taskID := c.Param("id")
-
-	var task model.Task
-	if err := database.GormDB.Where("id = ? AND user_id = ?", taskID, userID).First(&task).Error; err != nil {
+	taskID, ok := parseID(c, "id")
+	if !ok {
 		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
 		return
 	}
 
-	// 切换完成状态
-	newCompleted := !task.Completed
-
-	updates := map[string]interface{}{
-		"completed": newCompleted,
-	}
-
-	if newCompleted {
-		updates["completed_date"] = time.Now()
-	} else {
-		updates["completed_date"] = nil // 取消完成时清空完成日期
+	version, ok := ifMatch(c)
+	if !ok {
+		return
 	}
 
-	if err := database.GormDB.Model(&task).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新任务状态失败"})
+	// 切换完成状态
+	task, err := h.tasks.ToggleComplete(c.Request.Context(), userID, taskID, version, userLocation(c))
+	if errors.Is(err, service.ErrVersionConflict) {
+		h.taskConflict(c, userID, taskID)
 		return
 	}
-
-	// 重新获取更新后的任务信息
-	if err := database.GormDB.First(&task, task.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取更新后的任务失败"})
+	if err != nil {
+		taskError(c, err, "更新任务状态失败")
 		return
 	}
 
+	setETag(c, task.Version)
 	c.JSON(http.StatusOK, task)
 }
 
 // @Summary 获取任务时间线
-// @Description 获取过去7天完成的任务
+// @Description 按完成日期倒序分页获取完成记录，循环任务的每一次完成各占一条，日期按用户时区划分。
+// @Description 默认返回最近7天（含今天）。指定 group_by 时改为返回每天、每周（周一开始）或每月的完成数量，不分页。
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Success 200 {array} model.Task
+// @Param from query string false "开始日期 YYYY-MM-DD，默认结束日期往前6天"
+// @Param to query string false "结束日期 YYYY-MM-DD，默认今天"
+// @Param group_by query string false "分组方式" Enums(day, week, month)
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认20，最大100"
+// @Success 200 {object} service.TimelinePage "未指定 group_by"
+// @Success 200 {array} service.TimelineBucket "指定 group_by"
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /tasks/timeline [get]
-func GetTaskTimeline(c *gin.Context) {
+func (h *TaskHandler) GetTaskTimeline(c *gin.Context) {
 	userID := c.GetUint("userID")
-	sevenDaysAgo := time.Now().AddDate(0, 0, -7) //计算出七天前的日期。这两个变量将用于后续的数据库查询。
 
-	var tasks []model.Task
-	if err := database.GormDB.Where("user_id = ? AND completed = ? AND completed_date >= ?",
-		userID, true, sevenDaysAgo).
-		Order("completed_date desc").
-		Find(&tasks).Error; err != nil {
+	from, to, ok := parseDateRange(c, func(to time.Time) time.Time { return to.AddDate(0, 0, -6) })
+	if !ok {
+		return
+	}
+	q := service.TimelineQuery{
+		From:   from.Format(service.DateLayout),
+		To:     to.Format(service.DateLayout),
+		Cursor: c.Query("cursor"),
+	}
+
+	if groupBy := c.Query("group_by"); groupBy != "" {
+		if !service.ValidGroupBy(groupBy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "group_by 应为 day、week 或 month"})
+			return
+		}
+		buckets, err := h.tasks.TimelineGroups(c.Request.Context(), userID, q, groupBy, userLocation(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取任务时间线失败"})
+			return
+		}
+		c.JSON(http.StatusOK, buckets)
+		return
+	}
+
+	limit, err := pagination.ParseLimit(c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	q.Limit = limit
+
+	page, err := h.tasks.Timeline(c.Request.Context(), userID, q, userLocation(c))
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取任务时间线失败"})
 		return
 	}
 
-	c.JSON(http.StatusOK, tasks)
+	c.JSON(http.StatusOK, page)
 }
 
 // @Summary 获取今日任务
-// @Description 获取今天需要完成的任务
+// @Description 获取今天需要完成的任务，未到开始时间的任务不返回。默认逾期任务排在最前，其余按手动顺序排列
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
+// @Param tag query string false "标签名称"
+// @Param list_id query int false "清单ID，0 表示不属于任何清单的任务"
+// @Param sort query string false "排序字段：position、importance、due_at、created_at"
+// @Param order query string false "asc 或 desc，按 importance 排序时默认 desc"
 // @Success 200 {array} model.Task
 // @Failure 500 {object} map[string]string
 // @Router /tasks/today [get]
-func GetTodayTasks(c *gin.Context) {
+func (h *TaskHandler) GetTodayTasks(c *gin.Context) {
 	userID := c.GetUint("userID")
-	today := time.Now().Format("2006-01-02")
-
-	var tasks []model.Task
-	if err := database.GormDB.Where(
-		"user_id = ? AND (completed = false OR (completed = true AND DATE(completed_date) = ?) OR (completed = true AND is_cycle = true))",
-		userID,
-		today,
-	).Order("importance_level asc").Find(&tasks).Error; err != nil {
+
+	f, ok := taskFilter(c)
+	if !ok {
+		return
+	}
+	by, ok := taskSort(c)
+	if !ok {
+		return
+	}
+
+	tasks, err := h.tasks.Today(c.Request.Context(), userID, f, by, userLocation(c))
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取今日任务失败"})
 		return
 	}
@@ -231,46 +537,176 @@ func GetTodayTasks(c *gin.Context) {
 	c.JSON(http.StatusOK, tasks)
 }
 
-// @Summary 获取用户任务
-// @Description 获取用户所有任务
+// @Summary 获取任务列表
+// @Description 按条件筛选、排序并分页返回任务，默认按手动顺序排列。日期参数为用户时区的 YYYY-MM-DD，包含两端
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Success 200 {array} model.Task
+// @Param completed query bool false "是否已完成，循环任务表示当前这一次是否已完成"
+// @Param is_cycle query bool false "是否为循环任务"
+// @Param min_importance query int false "最低重要性（0–5）"
+// @Param max_importance query int false "最高重要性（0–5）"
+// @Param list_id query int false "清单ID，0 表示不属于任何清单的任务"
+// @Param tag query string false "标签名称"
+// @Param created_from query string false "创建日期下限"
+// @Param created_to query string false "创建日期上限"
+// @Param completed_from query string false "完成日期下限，循环任务任一次在范围内完成即可"
+// @Param completed_to query string false "完成日期上限"
+// @Param q query string false "在内容和描述中搜索"
+// @Param sort query string false "排序字段：position、importance、due_at、created_at"
+// @Param order query string false "asc 或 desc，按 importance 排序时默认 desc"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认20，最大100"
+// @Success 200 {object} service.TaskPage
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
-// @Router /tasks/user [get]
-func GetUserTasks(c *gin.Context) {
+// @Router /tasks [get]
+func (h *TaskHandler) GetUserTasks(c *gin.Context) {
 	userID := c.GetUint("userID")
 
-	var tasks []model.Task
-	if err := database.GormDB.Where("user_id = ?", userID).Find(&tasks).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用户任务失败"})
+	f, ok := taskFilter(c)
+	if !ok {
+		return
+	}
+	by, ok := taskSort(c)
+	if !ok {
 		return
 	}
+	limit, err := pagination.ParseLimit(c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	q := service.TaskListQuery{
+		ListID: f.ListID,
+		Tag:    f.Tag,
+		Text:   c.Query("q"),
+		Sort:   by,
+		Cursor: c.Query("cursor"),
+		Limit:  limit,
+	}
 
-	c.JSON(http.StatusOK, tasks)
+	for name, dst := range map[string]**bool{"completed": &q.Completed, "is_cycle": &q.IsCycle} {
+		if v := c.Query(name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": name + " 应为 true 或 false"})
+				return
+			}
+			*dst = &b
+		}
+	}
+	for name, dst := range map[string]**int{"min_importance": &q.MinImportance, "max_importance": &q.MaxImportance} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 || n > 5 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": name + " 应为 0 到 5 之间的整数"})
+				return
+			}
+			*dst = &n
+		}
+	}
+	for name, dst := range map[string]*string{
+		"created_from": &q.CreatedFrom, "created_to": &q.CreatedTo,
+		"completed_from": &q.CompletedFrom, "completed_to": &q.CompletedTo,
+	} {
+		if v := c.Query(name); v != "" {
+			if _, err := time.Parse(service.DateLayout, v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": name + " 日期格式应为 YYYY-MM-DD"})
+				return
+			}
+			*dst = v
+		}
+	}
+
+	page, err := h.tasks.Query(c.Request.Context(), userID, q, userLocation(c))
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取任务列表失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary 调整任务顺序
+// @Description 把任务移到两个相邻任务之间，只更新被移动任务的 position。只指定一侧时另一侧取当前实际相邻的任务
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Param position body MoveTaskRequest true "相邻任务"
+// @Param If-Match header string false "任务的 ETag，版本不一致时返回 412 和任务的当前表示"
+// @Success 200 {object} model.Task
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} model.Task
+// @Failure 500 {object} map[string]string
+// @Router /tasks/{id}/position [put]
+func (h *TaskHandler) MoveTask(c *gin.Context) {
+	userID := c.GetUint("userID")
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	var req MoveTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, err := h.tasks.Move(c.Request.Context(), userID, taskID, service.MoveInput{
+		AfterID:  req.AfterID,
+		BeforeID: req.BeforeID,
+		Version:  version,
+	}, userLocation(c))
+	if errors.Is(err, service.ErrVersionConflict) {
+		h.taskConflict(c, userID, taskID)
+		return
+	}
+	if err != nil {
+		taskError(c, err, "调整任务顺序失败")
+		return
+	}
+
+	setETag(c, task.Version)
+	c.JSON(http.StatusOK, task)
 }
 
 // @Summary 批量更新任务优先级
-// @Description 批量更新多个任务的重要性级别
+// @Description 批量更新多个任务的重要性级别（0–5，5 最高）。调整顺序请使用 PUT /tasks/{id}/position。
+// @Description 每个任务可以带上 GET 返回的 version，任一任务版本不一致时整体不更新，返回 412 以及该任务的当前表示和 ETag
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Param tasks body object{tasks=array[object{id=int,importance_level=int}]} true "任务优先级列表"
+// @Param tasks body object{tasks=array[object{id=int,importance_level=int,version=int}]} true "任务优先级列表，version 为可选的期望版本"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
+// @Failure 412 {object} model.Task "版本不一致的任务"
 // @Failure 500 {object} map[string]string
 // @Router /tasks/importance [put]
-func UpdateTasksImportance(c *gin.Context) {
+func (h *TaskHandler) UpdateTasksImportance(c *gin.Context) {
 	userID := c.GetUint("userID")
 
 	var req struct {
 		Tasks []struct {
-			ID              int `json:"id"`
-			ImportanceLevel int `json:"importance_level" binding:"min=0"`
-		} `json:"tasks" binding:"required"`
+			ID              uint  `json:"id"`
+			ImportanceLevel int   `json:"importance_level" binding:"min=0,max=5"`
+			Version         *uint `json:"version"`
+		} `json:"tasks" binding:"required,dive"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -278,33 +714,189 @@ func UpdateTasksImportance(c *gin.Context) {
 		return
 	}
 
-	// 开始事务
-	tx := database.GormDB.Begin()
-
+	items := make([]service.ImportanceInput, 0, len(req.Tasks))
 	for _, taskUpdate := range req.Tasks {
-		// 验证任务归属权并更新
-		result := tx.Model(&model.Task{}).
-			Where("id = ? AND user_id = ?", taskUpdate.ID, userID).
-			Update("importance_level", taskUpdate.ImportanceLevel)
-
-		if result.Error != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "更新任务优先级失败"})
+		items = append(items, service.ImportanceInput{
+			ID:      taskUpdate.ID,
+			Level:   taskUpdate.ImportanceLevel,
+			Version: taskUpdate.Version,
+		})
+	}
+
+	if err := h.tasks.UpdateImportance(c.Request.Context(), userID, items); err != nil {
+		if errors.Is(err, service.ErrTaskNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "任务不存在或无权限更新"})
+			return
+		}
+		// 版本号在请求体中给出，冲突时与 If-Match 一样返回 412
+		var conflictErr *service.BatchVersionError
+		if errors.As(err, &conflictErr) {
+			task, err := h.tasks.Get(c.Request.Context(), userID, conflictErr.ID, userLocation(c))
+			if err != nil {
+				taskError(c, err, "获取任务失败")
+				return
+			}
+			preconditionFailed(c, task.Version, task)
 			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新任务优先级失败"})
+		return
+	}
 
-		if result.RowsAffected == 0 {
-			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"error": "任务不存在或无权限更新"})
+	c.JSON(http.StatusOK, gin.H{"message": "更新成功"})
+}
+
+// @Summary 恢复任务
+// @Description 从回收站恢复已删除的任务
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Param If-Match header string false "回收站中任务的 ETag，版本不一致时返回 412"
+// @Success 200 {object} model.Task
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} model.Task "回收站中任务的当前表示"
+// @Failure 500 {object} map[string]string
+// @Router /tasks/{id}/restore [post]
+func (h *TaskHandler) RestoreTask(c *gin.Context) {
+	userID := c.GetUint("userID")
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "回收站中不存在该任务"})
+		return
+	}
+
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	task, err := h.tasks.Restore(c.Request.Context(), userID, taskID, version, userLocation(c))
+	if errors.Is(err, service.ErrTaskNotInTrash) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "回收站中不存在该任务"})
+		return
+	}
+	if errors.Is(err, service.ErrVersionConflict) {
+		h.deletedTaskConflict(c, userID, taskID)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "恢复任务失败"})
+		return
+	}
+
+	setETag(c, task.Version)
+	c.JSON(http.StatusOK, task)
+}
+
+// @Summary 获取任务完成日历
+// @Description 获取任务在日期范围内每一次的完成记录，默认最近一年
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Param from query string false "开始日期 YYYY-MM-DD"
+// @Param to query string false "结束日期 YYYY-MM-DD，默认用户时区的今天"
+// @Success 200 {object} service.CompletionCalendar
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tasks/{id}/completions [get]
+func (h *TaskHandler) GetTaskCompletions(c *gin.Context) {
+	userID := c.GetUint("userID")
+	taskID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在"})
+		return
+	}
+
+	from, to, ok := parseDateRange(c, func(to time.Time) time.Time { return to.AddDate(-1, 0, 1) })
+	if !ok {
+		return
+	}
+
+	calendar, err := h.tasks.Completions(c.Request.Context(), userID, taskID,
+		from.Format(service.DateLayout), to.Format(service.DateLayout))
+	if err != nil {
+		taskError(c, err, "获取完成记录失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+// 即将到期任务查询的默认和最大天数
+const (
+	defaultUpcomingDays = 7
+	maxUpcomingDays     = 365
+)
+
+// @Summary 获取即将到期的任务
+// @Description 获取未来 N 天内到期的未完成任务，按截止时间升序
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param days query int false "天数，默认7，最大365"
+// @Param tag query string false "标签名称"
+// @Param list_id query int false "清单ID，0 表示不属于任何清单的任务"
+// @Success 200 {array} model.Task
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tasks/upcoming [get]
+func (h *TaskHandler) GetUpcomingTasks(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	days := defaultUpcomingDays
+	if v := c.Query("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxUpcomingDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days 应为 1 到 365 之间的整数"})
 			return
 		}
+		days = n
+	}
+	f, ok := taskFilter(c)
+	if !ok {
+		return
 	}
 
-	// 提交事务
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新任务优先级失败"})
+	tasks, err := h.tasks.Upcoming(c.Request.Context(), userID, days, f, userLocation(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取即将到期任务失败"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "更新成功"})
+	c.JSON(http.StatusOK, tasks)
+}
+
+// @Summary 获取逾期任务
+// @Description 获取已过截止时间的未完成任务，按截止时间升序
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param tag query string false "标签名称"
+// @Param list_id query int false "清单ID，0 表示不属于任何清单的任务"
+// @Success 200 {array} model.Task
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tasks/overdue [get]
+func (h *TaskHandler) GetOverdueTasks(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	f, ok := taskFilter(c)
+	if !ok {
+		return
+	}
+
+	tasks, err := h.tasks.Overdue(c.Request.Context(), userID, f, userLocation(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取逾期任务失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
 }
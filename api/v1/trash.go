@@ -0,0 +1,39 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/PisaListBE/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// TrashHandler 回收站接口
+type TrashHandler struct {
+	trash *service.TrashService
+}
+
+// NewTrashHandler 创建回收站接口处理器
+func NewTrashHandler(trash *service.TrashService) *TrashHandler {
+	return &TrashHandler{trash: trash}
+}
+
+// @Summary 获取回收站
+// @Description 获取已删除但尚未被彻底清除的任务和心愿
+// @Tags trash
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} service.Trash
+// @Failure 500 {object} map[string]string
+// @Router /trash [get]
+func (h *TrashHandler) GetTrash(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	trash, err := h.trash.List(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取回收站失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, trash)
+}
@@ -1,14 +1,17 @@
 package v1
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/PisaListBE/internal/middleware"
 	"github.com/PisaListBE/internal/model"
-	"github.com/PisaListBE/pkg/database"
+	"github.com/PisaListBE/internal/service"
 	"github.com/PisaListBE/pkg/jwt"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // @title PisaList User API
@@ -21,6 +24,57 @@ type UserRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=32" example:"johndoe" description:"用户名，3-32个字符"`
 	Password string `json:"password" binding:"required,min=6" example:"password123" description:"密码，最少6个字符"`
 	Email    string `json:"email" binding:"required,email" example:"john@example.com" description:"电子邮件地址"`
+	Timezone string `json:"timezone" binding:"max=64" example:"Asia/Shanghai" description:"IANA 时区名，可选，默认使用服务端配置"`
+}
+
+// UserResponse 用户信息
+type UserResponse struct {
+	ID       uint   `json:"id" example:"1"`
+	Username string `json:"username" example:"johndoe"`
+	Email    string `json:"email" example:"john@example.com"`
+	Timezone string `json:"timezone" example:"Asia/Shanghai"`
+}
+
+// UserHandler 用户注册、登录和个人设置接口
+type UserHandler struct {
+	users *service.UserService
+}
+
+// NewUserHandler 创建用户接口处理器
+func NewUserHandler(users *service.UserService) *UserHandler {
+	return &UserHandler{users: users}
+}
+
+func (h *UserHandler) response(user *model.User) UserResponse {
+	return UserResponse{
+		ID:       user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Timezone: h.users.Timezone(user),
+	}
+}
+
+// ResolveLocation 供 middleware.Location 查询用户时区
+func (h *UserHandler) ResolveLocation(ctx context.Context, userID uint) (*time.Location, error) {
+	loc, err := h.users.Location(ctx, userID)
+	if errors.Is(err, service.ErrUserNotFound) {
+		return nil, middleware.ErrUnknownUser
+	}
+	return loc, err
+}
+
+// respondWithToken 生成 token 并返回用户信息
+func (h *UserHandler) respondWithToken(c *gin.Context, user *model.User) {
+	token, err := jwt.GenerateToken(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成token失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token": token,
+		"user":  h.response(user),
+	})
 }
 
 // @Summary 用户注册
@@ -29,59 +83,39 @@ type UserRequest struct {
 // @Accept json
 // @Produce json
 // @Param user body UserRequest true "用户注册信息"
-// @Success 200 {object} object{token=string,user=object{id=integer,username=string,email=string}} "注册成功返回token和用户信息"
+// @Success 200 {object} object{token=string,user=UserResponse} "注册成功返回token和用户信息"
 // @Failure 400 {object} map[string]string "请求参数错误"
 // @Failure 500 {object} map[string]string "服务器内部错误"
 // @Router /register [post]
-func Register(c *gin.Context) {
+func (h *UserHandler) Register(c *gin.Context) {
 	var req UserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// 检查用户名是否已存在
-	var existingUser model.User
-	if err := database.GormDB.Where("username = ?", req.Username).First(&existingUser).Error; err == nil {
+	user, err := h.users.Register(c.Request.Context(), service.RegisterInput{
+		Username: req.Username,
+		Password: req.Password,
+		Email:    req.Email,
+		Timezone: req.Timezone,
+	})
+	if errors.Is(err, service.ErrUsernameTaken) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "用户名已存在"})
 		return
 	}
-
-	// 加密密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "密码加密失败"})
+	if errors.Is(err, service.ErrInvalidTimezone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	user := model.User{
-		Username: req.Username,
-		Password: string(hashedPassword),
-		Email:    req.Email,
-	}
-
-	if err := database.GormDB.Create(&user).Error; err != nil {
+	if err != nil {
 		// 添加详细的错误日志
 		fmt.Printf("创建用户失败: %v\n", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建用户失败"})
 		return
 	}
 
-	// 生成 token
-	token, err := jwt.GenerateToken(user.ID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成token失败"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user": gin.H{
-			"id":       user.ID,
-			"username": user.Username,
-			"email":    user.Email,
-		},
-	})
+	h.respondWithToken(c, user)
 }
 
 // @Summary 用户登录
@@ -90,12 +124,12 @@ func Register(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param credentials body object{username=string,password=string} true "登录凭证"
-// @Success 200 {object} object{token=string,user=object{id=integer,username=string,email=string}} "登录成功返回token和用户信息"
+// @Success 200 {object} object{token=string,user=UserResponse} "登录成功返回token和用户信息"
 // @Failure 400 {object} map[string]string "请求参数错误"
 // @Failure 401 {object} map[string]string "用户名或密码错误"
 // @Failure 500 {object} map[string]string "服务器内部错误"
 // @Router /login [post]
-func Login(c *gin.Context) {
+func (h *UserHandler) Login(c *gin.Context) {
 	var req struct {
 		Username string `json:"username" binding:"required" example:"johndoe"`
 		Password string `json:"password" binding:"required" example:"password123"`
@@ -106,29 +140,80 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	var user model.User
-	if err := database.GormDB.Where("username = ?", req.Username).First(&user).Error; err != nil {
+	user, err := h.users.Login(c.Request.Context(), req.Username, req.Password)
+	if errors.Is(err, service.ErrInvalidCredentials) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户名或密码错误"})
 		return
 	}
-
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户名或密码错误"})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "登录失败"})
 		return
 	}
 
-	token, err := jwt.GenerateToken(user.ID)
+	h.respondWithToken(c, user)
+}
+
+// @Summary 获取当前用户
+// @Description 获取当前登录用户的信息，timezone 为实际使用的时区
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} UserResponse
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/me [get]
+func (h *UserHandler) GetCurrentUser(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	user, err := h.users.Get(c.Request.Context(), userID)
+	if errors.Is(err, service.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "用户不存在"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成token失败"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用户信息失败"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user": gin.H{
-			"id":       user.ID,
-			"username": user.Username,
-			"email":    user.Email,
-		},
-	})
+	c.JSON(http.StatusOK, h.response(user))
+}
+
+// @Summary 设置时区
+// @Description 设置当前用户的 IANA 时区，今日任务、时间线和循环任务按该时区划分日期；传空字符串恢复默认时区
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param timezone body object{timezone=string} true "时区，如 Asia/Shanghai"
+// @Success 200 {object} UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/me/timezone [put]
+func (h *UserHandler) UpdateTimezone(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req struct {
+		Timezone string `json:"timezone" binding:"max=64" example:"Asia/Shanghai"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.users.SetTimezone(c.Request.Context(), userID, req.Timezone)
+	switch {
+	case errors.Is(err, service.ErrInvalidTimezone):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "用户不存在"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "设置时区失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.response(user))
 }
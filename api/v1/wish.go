@@ -1,11 +1,11 @@
 package v1
 
 import (
-	"math/rand"
+	"errors"
 	"net/http"
 
 	"github.com/PisaListBE/internal/model"
-	"github.com/PisaListBE/pkg/database"
+	"github.com/PisaListBE/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,6 +19,121 @@ type WishRequest struct {
 	Event       string `json:"event" binding:"required" example:"环游世界" description:"心愿内容"`
 	Description string `json:"description" example:"想去看看世界的每个角落" description:"心愿详细描述"`
 	IsCycle     bool   `json:"is_cycle" example:"false" description:"是否为循环心愿"`
+	TagIDs      []uint `json:"tag_ids" example:"1,2" description:"标签ID列表，更新时省略表示不修改"`
+}
+
+func (r WishRequest) input() service.WishInput {
+	return service.WishInput{
+		Event:       r.Event,
+		Description: r.Description,
+		IsCycle:     r.IsCycle,
+		TagIDs:      r.TagIDs,
+	}
+}
+
+// wishRequest 返回心愿当前值对应的请求，作为 PATCH 合并的基础
+func wishRequest(wish *model.Wish) WishRequest {
+	tagIDs := make([]uint, 0, len(wish.Tags))
+	for _, tag := range wish.Tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	return WishRequest{
+		Event:       wish.Event,
+		Description: wish.Description,
+		IsCycle:     wish.IsCycle,
+		TagIDs:      tagIDs,
+	}
+}
+
+// WishHandler 心愿及心愿社区相关接口
+type WishHandler struct {
+	wishes *service.WishService
+}
+
+// NewWishHandler 创建心愿接口处理器
+func NewWishHandler(wishes *service.WishService) *WishHandler {
+	return &WishHandler{wishes: wishes}
+}
+
+// wishError 根据服务层错误返回响应，fallback 为未知错误时的提示
+func wishError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrWishNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "心愿不存在"})
+		return
+	case errors.Is(err, service.ErrSharedWishNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "社区心愿不存在"})
+		return
+	case errors.Is(err, service.ErrTagNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrWishAlreadyShared):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrVersionConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
+
+// wishConflict 版本冲突时返回心愿的当前表示和 ETag：请求带 If-Match 时为 412，
+// 没有 If-Match 但读取后被并发修改时为 409
+func (h *WishHandler) wishConflict(c *gin.Context, userID, id uint) {
+	wish, err := h.wishes.Get(c.Request.Context(), userID, id)
+	if err != nil {
+		wishError(c, err, "获取心愿失败")
+		return
+	}
+	conflict(c, wish.Version, wish)
+}
+
+// deletedWishConflict 恢复时版本冲突，返回回收站中心愿的当前表示和 ETag
+func (h *WishHandler) deletedWishConflict(c *gin.Context, userID, id uint) {
+	wish, err := h.wishes.GetDeleted(c.Request.Context(), userID, id)
+	if errors.Is(err, service.ErrWishNotInTrash) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "回收站中不存在该心愿"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取心愿失败"})
+		return
+	}
+	conflict(c, wish.Version, wish)
+}
+
+// @Summary 获取心愿
+// @Description 获取心愿详情，响应头 ETag 为心愿的当前版本，可用于 If-Match；If-None-Match 与当前版本一致时返回 304
+// @Tags wishes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "心愿ID"
+// @Param If-None-Match header string false "上次获取的 ETag"
+// @Success 200 {object} model.Wish "心愿信息"
+// @Success 304 "未修改"
+// @Failure 404 {object} map[string]string "心愿不存在"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /wishes/{id} [get]
+func (h *WishHandler) GetWish(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "心愿不存在"})
+		return
+	}
+
+	wish, err := h.wishes.Get(c.Request.Context(), userID, wishID)
+	if err != nil {
+		wishError(c, err, "获取心愿失败")
+		return
+	}
+
+	setETag(c, wish.Version)
+	if notModified(c, wish.Version) {
+		return
+	}
+	c.JSON(http.StatusOK, wish)
 }
 
 // @Summary 创建心愿
@@ -32,7 +147,7 @@ type WishRequest struct {
 // @Failure 400 {object} map[string]string "请求参数错误"
 // @Failure 500 {object} map[string]string "服务器内部错误"
 // @Router /wishes [post]
-func CreateWish(c *gin.Context) {
+func (h *WishHandler) CreateWish(c *gin.Context) {
 	userID := c.GetUint("userID")
 	var req WishRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -40,19 +155,13 @@ func CreateWish(c *gin.Context) {
 		return
 	}
 
-	wish := model.Wish{
-		UserID:      userID,
-		Event:       req.Event,
-		Description: req.Description,
-		IsCycle:     req.IsCycle,
-		IsShared:    false,
-	}
-
-	if err := database.GormDB.Create(&wish).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建心愿失败"})
+	wish, err := h.wishes.Create(c.Request.Context(), userID, req.input())
+	if err != nil {
+		wishError(c, err, "创建心愿失败")
 		return
 	}
 
+	setETag(c, wish.Version)
 	c.JSON(http.StatusOK, wish)
 }
 
@@ -63,22 +172,32 @@ func CreateWish(c *gin.Context) {
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path string true "心愿ID"
+// @Param If-Match header string false "心愿的 ETag，版本不一致时返回 412 和心愿的当前表示"
 // @Success 200 {object} map[string]string "删除成功"
+// @Failure 400 {object} map[string]string "If-Match 格式错误"
 // @Failure 404 {object} map[string]string "心愿不存在"
+// @Failure 412 {object} model.Wish "版本不一致"
 // @Failure 500 {object} map[string]string "服务器内部错误"
 // @Router /wishes/{id} [delete]
-func DeleteWish(c *gin.Context) {
+func (h *WishHandler) DeleteWish(c *gin.Context) {
 	userID := c.GetUint("userID")
-	wishID := c.Param("id")
-
-	var wish model.Wish
-	if err := database.GormDB.Where("id = ? AND user_id = ?", wishID, userID).First(&wish).Error; err != nil {
+	wishID, ok := parseID(c, "id")
+	if !ok {
 		c.JSON(http.StatusNotFound, gin.H{"error": "心愿不存在"})
 		return
 	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
 
-	if err := database.GormDB.Delete(&wish).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除心愿失败"})
+	err := h.wishes.Delete(c.Request.Context(), userID, wishID, version)
+	if errors.Is(err, service.ErrVersionConflict) {
+		h.wishConflict(c, userID, wishID)
+		return
+	}
+	if err != nil {
+		wishError(c, err, "删除心愿失败")
 		return
 	}
 
@@ -93,43 +212,114 @@ func DeleteWish(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Param id path string true "心愿ID"
 // @Param wish body WishRequest true "更新的心愿信息"
+// @Param If-Match header string false "心愿的 ETag，版本不一致时返回 412 和心愿的当前表示"
 // @Success 200 {object} model.Wish "更新成功返回心愿信息"
 // @Failure 400 {object} map[string]string "请求参数错误"
 // @Failure 404 {object} map[string]string "心愿不存在"
+// @Failure 412 {object} model.Wish "版本不一致"
 // @Failure 500 {object} map[string]string "服务器内部错误"
 // @Router /wishes/{id} [put]
-func UpdateWish(c *gin.Context) {
+func (h *WishHandler) UpdateWish(c *gin.Context) {
 	userID := c.GetUint("userID")
-	wishID := c.Param("id")
+	wishID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "心愿不存在"})
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
 
 	var req WishRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	in := req.input()
+	in.Version = version
+
+	wish, err := h.wishes.Update(c.Request.Context(), userID, wishID, in)
+	if errors.Is(err, service.ErrVersionConflict) {
+		h.wishConflict(c, userID, wishID)
+		return
+	}
+	if err != nil {
+		wishError(c, err, "更新心愿失败")
+		return
+	}
+
+	setETag(c, wish.Version)
+	c.JSON(http.StatusOK, wish)
+}
 
-	var wish model.Wish
-	if err := database.GormDB.Where("id = ? AND user_id = ?", wishID, userID).First(&wish).Error; err != nil {
+// @Summary 部分更新心愿
+// @Description 使用 JSON Merge Patch（RFC 7396）只更新请求中出现的字段，字段设为 null 表示清空（event 不能清空）
+// @Tags wishes
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "心愿ID"
+// @Param wish body WishRequest true "需要修改的字段"
+// @Param If-Match header string false "心愿的 ETag，版本不一致时返回 412 和心愿的当前表示"
+// @Success 200 {object} model.Wish "更新成功返回心愿信息"
+// @Failure 400 {object} map[string]string "请求参数错误"
+// @Failure 404 {object} map[string]string "心愿不存在"
+// @Failure 409 {object} model.Wish "合并期间心愿被修改"
+// @Failure 412 {object} model.Wish "版本不一致"
+// @Failure 415 {object} map[string]string "请求类型错误"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /wishes/{id} [patch]
+func (h *WishHandler) PatchWish(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishID, ok := parseID(c, "id")
+	if !ok {
 		c.JSON(http.StatusNotFound, gin.H{"error": "心愿不存在"})
 		return
 	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
 
-	updates := map[string]interface{}{
-		"event":       req.Event,
-		"description": req.Description,
-		"is_cycle":    req.IsCycle,
+	wish, err := h.wishes.Get(c.Request.Context(), userID, wishID)
+	if err != nil {
+		wishError(c, err, "更新心愿失败")
+		return
 	}
+	if version != nil && *version != wish.Version {
+		h.wishConflict(c, userID, wishID)
+		return
+	}
+	var req WishRequest
+	if !bindMergePatch(c, wishRequest(wish), &req) {
+		return
+	}
+	if req.TagIDs == nil {
+		// tag_ids 为 null 表示清空标签，而 WishInput 中的 nil 表示不修改
+		req.TagIDs = []uint{}
+	}
+	// 补丁基于读取到的版本合并，之后被其他请求修改过时不能覆盖
+	in := req.input()
+	in.Version = &wish.Version
 
-	if err := database.GormDB.Model(&wish).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新心愿失败"})
+	wish, err = h.wishes.Update(c.Request.Context(), userID, wishID, in)
+	if errors.Is(err, service.ErrVersionConflict) {
+		h.wishConflict(c, userID, wishID)
+		return
+	}
+	if err != nil {
+		wishError(c, err, "更新心愿失败")
 		return
 	}
 
+	setETag(c, wish.Version)
 	c.JSON(http.StatusOK, wish)
 }
 
 // @Summary 分享心愿
-// @Description 将心愿分享到心愿社区
+// @Description 将心愿分享到心愿社区，每个心愿只能分享一次
 // @Tags wishes
 // @Accept json
 // @Produce json
@@ -137,32 +327,19 @@ func UpdateWish(c *gin.Context) {
 // @Param id path string true "心愿ID"
 // @Success 200 {object} map[string]string "分享成功"
 // @Failure 404 {object} map[string]string "心愿不存在"
+// @Failure 409 {object} map[string]string "心愿已分享"
 // @Failure 500 {object} map[string]string "服务器内部错误"
 // @Router /wishes/{id}/share [post]
-func ShareWish(c *gin.Context) {
+func (h *WishHandler) ShareWish(c *gin.Context) {
 	userID := c.GetUint("userID")
-	wishID := c.Param("id")
-
-	var wish model.Wish
-	if err := database.GormDB.Where("id = ? AND user_id = ?", wishID, userID).First(&wish).Error; err != nil {
+	wishID, ok := parseID(c, "id")
+	if !ok {
 		c.JSON(http.StatusNotFound, gin.H{"error": "心愿不存在"})
 		return
 	}
 
-	sharedWish := model.SharedWish{
-		OriginalWishID: wish.ID,
-		Event:          wish.Event,
-		Description:    wish.Description,
-		SharedByUserID: userID,
-	}
-
-	if err := database.GormDB.Create(&sharedWish).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "分享心愿失败"})
-		return
-	}
-
-	if err := database.GormDB.Model(&wish).Update("is_shared", true).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新心愿分享状态失败"})
+	if _, err := h.wishes.Share(c.Request.Context(), userID, wishID); err != nil {
+		wishError(c, err, "分享心愿失败")
 		return
 	}
 
@@ -170,19 +347,20 @@ func ShareWish(c *gin.Context) {
 }
 
 // @Summary 获取用户心愿列表
-// @Description 获取当前用户的所有心愿
+// @Description 获取当前用户的所有心愿，可按标签名称筛选
 // @Tags wishes
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
+// @Param tag query string false "标签名称"
 // @Success 200 {array} model.Wish "心愿列表"
 // @Failure 500 {object} map[string]string "服务器内部错误"
 // @Router /wishes [get]
-func GetUserWishes(c *gin.Context) {
+func (h *WishHandler) GetUserWishes(c *gin.Context) {
 	userID := c.GetUint("userID")
 
-	var wishes []model.Wish
-	if err := database.GormDB.Where("user_id = ?", userID).Find(&wishes).Error; err != nil {
+	wishes, err := h.wishes.List(c.Request.Context(), userID, c.Query("tag"))
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取心愿列表失败"})
 		return
 	}
@@ -191,16 +369,17 @@ func GetUserWishes(c *gin.Context) {
 }
 
 // @Summary 获取心愿社区列表
-// @Description 获取所有已分享的心愿
+// @Description 获取所有已分享的心愿，可按标签名称浏览同一主题的心愿
 // @Tags wishes
 // @Accept json
 // @Produce json
+// @Param tag query string false "标签名称"
 // @Success 200 {array} model.SharedWish "分享的心愿列表"
 // @Failure 500 {object} map[string]string "服务器内部错误"
 // @Router /wishes/community [get]
-func GetCommunityWishes(c *gin.Context) {
-	var sharedWishes []model.SharedWish
-	if err := database.GormDB.Find(&sharedWishes).Error; err != nil {
+func (h *WishHandler) GetCommunityWishes(c *gin.Context) {
+	sharedWishes, err := h.wishes.Community(c.Request.Context(), c.Query("tag"))
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取心愿社区失败"})
 		return
 	}
@@ -217,24 +396,116 @@ func GetCommunityWishes(c *gin.Context) {
 // @Failure 404 {object} map[string]string "暂无共享心愿"
 // @Failure 500 {object} map[string]string "服务器内部错误"
 // @Router /wishes/random [get]
-func GetRandomWish(c *gin.Context) {
-	var count int64
-	if err := database.GormDB.Model(&model.SharedWish{}).Count(&count).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取心愿数量失败"})
+func (h *WishHandler) GetRandomWish(c *gin.Context) {
+	wish, err := h.wishes.Random(c.Request.Context())
+	if errors.Is(err, service.ErrNoSharedWish) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "暂无共享心愿"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取随机心愿失败"})
 		return
 	}
 
-	if count == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "暂无共享心愿"})
+	c.JSON(http.StatusOK, wish)
+}
+
+// @Summary 恢复心愿
+// @Description 从回收站恢复已删除的心愿
+// @Tags wishes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "心愿ID"
+// @Param If-Match header string false "回收站中心愿的 ETag，版本不一致时返回 412"
+// @Success 200 {object} model.Wish "恢复后的心愿信息"
+// @Failure 404 {object} map[string]string "回收站中不存在该心愿"
+// @Failure 412 {object} model.Wish "版本不一致，返回回收站中心愿的当前表示"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /wishes/{id}/restore [post]
+func (h *WishHandler) RestoreWish(c *gin.Context) {
+	userID := c.GetUint("userID")
+	wishID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "回收站中不存在该心愿"})
 		return
 	}
 
-	offset := rand.Int63n(count)
-	var wish model.SharedWish
-	if err := database.GormDB.Offset(int(offset)).First(&wish).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取随机心愿失败"})
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	wish, err := h.wishes.Restore(c.Request.Context(), userID, wishID, version)
+	if errors.Is(err, service.ErrWishNotInTrash) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "回收站中不存在该心愿"})
+		return
+	}
+	if errors.Is(err, service.ErrVersionConflict) {
+		h.deletedWishConflict(c, userID, wishID)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "恢复心愿失败"})
 		return
 	}
 
+	setETag(c, wish.Version)
 	c.JSON(http.StatusOK, wish)
 }
+
+// @Summary 点赞社区心愿
+// @Description 点赞心愿社区中的心愿，并通知分享者；重复点赞不会重复计数
+// @Tags wishes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "社区心愿ID"
+// @Success 200 {object} model.SharedWish "点赞后的社区心愿"
+// @Failure 404 {object} map[string]string "社区心愿不存在"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /wishes/community/{id}/like [post]
+func (h *WishHandler) LikeSharedWish(c *gin.Context) {
+	userID := c.GetUint("userID")
+	sharedID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "社区心愿不存在"})
+		return
+	}
+
+	shared, err := h.wishes.Like(c.Request.Context(), userID, sharedID)
+	if err != nil {
+		wishError(c, err, "点赞失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, shared)
+}
+
+// @Summary 取消点赞社区心愿
+// @Description 取消对社区心愿的点赞
+// @Tags wishes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "社区心愿ID"
+// @Success 200 {object} model.SharedWish "取消点赞后的社区心愿"
+// @Failure 404 {object} map[string]string "社区心愿不存在"
+// @Failure 500 {object} map[string]string "服务器内部错误"
+// @Router /wishes/community/{id}/like [delete]
+func (h *WishHandler) UnlikeSharedWish(c *gin.Context) {
+	userID := c.GetUint("userID")
+	sharedID, ok := parseID(c, "id")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "社区心愿不存在"})
+		return
+	}
+
+	shared, err := h.wishes.Unlike(c.Request.Context(), userID, sharedID)
+	if err != nil {
+		wishError(c, err, "取消点赞失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, shared)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/PisaListBE/api/v1"
+	"github.com/PisaListBE/internal/notify"
+	"github.com/PisaListBE/internal/repository"
+	"github.com/PisaListBE/internal/search"
+	"github.com/PisaListBE/internal/service"
+	"github.com/PisaListBE/pkg/config"
+	"github.com/PisaListBE/pkg/worker"
+	"github.com/PisaListBE/router"
+	"gorm.io/gorm"
+)
+
+// app 按 仓储 -> 服务 -> 接口 的顺序组装的应用依赖
+type app struct {
+	tasks         *service.TaskService
+	wishes        *service.WishService
+	users         *service.UserService
+	trash         *service.TrashService
+	stats         *service.StatsService
+	reminders     *service.ReminderService
+	notifications *service.NotificationService
+	tags          *service.TagService
+	lists         *service.ListService
+	search        *service.SearchService
+	sync          *service.SyncService
+	searcher      search.Searcher
+}
+
+func newApp(db *gorm.DB, cfg *config.Config) (*app, error) {
+	tx := repository.NewTransactor(db)
+	taskRepo := repository.NewTaskRepository(db)
+	wishRepo := repository.NewWishRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	completionRepo := repository.NewCompletionRepository(db)
+	reminderRepo := repository.NewReminderRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	checklistRepo := repository.NewChecklistRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	listRepo := repository.NewListRepository(db)
+
+	users := service.NewUserService(userRepo, cfg.App.Location())
+	dispatcher := newDispatcher(cfg.Notify, notificationRepo)
+	notifications := service.NewNotificationService(notificationRepo, taskRepo, users, cfg.Notify.DueHour)
+	searcher, err := newSearcher(cfg.Search, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &app{
+		tasks:         service.NewTaskService(tx, taskRepo, completionRepo, reminderRepo, checklistRepo, tagRepo, listRepo),
+		wishes:        service.NewWishService(tx, wishRepo, tagRepo, notifications),
+		users:         users,
+		trash:         service.NewTrashService(taskRepo, wishRepo, tagRepo, listRepo, cfg.Trash.Retention()),
+		stats:         service.NewStatsService(taskRepo, completionRepo),
+		reminders:     service.NewReminderService(reminderRepo, taskRepo, users, dispatcher, cfg.Notify.MaxAttempts),
+		notifications: notifications,
+		tags:          service.NewTagService(tagRepo),
+		lists:         service.NewListService(tx, listRepo, taskRepo),
+		search:        service.NewSearchService(searcher),
+		sync:          service.NewSyncService(taskRepo, wishRepo, listRepo, tagRepo, cfg.Trash.Retention()),
+		searcher:      searcher,
+	}, nil
+}
+
+// newDispatcher 根据配置启用通知渠道，站内信始终可用
+func newDispatcher(cfg config.NotifyConfig, notifications repository.NotificationRepository) *notify.Dispatcher {
+	notifiers := []notify.Notifier{notify.NewInboxNotifier(notifications)}
+	if cfg.SMTP.Enabled() {
+		notifiers = append(notifiers, notify.NewEmailNotifier(cfg.SMTP))
+	}
+	if cfg.Webhook.Enabled() {
+		notifiers = append(notifiers, notify.NewWebhookNotifier(cfg.Webhook))
+	}
+	return notify.NewDispatcher(cfg.Channels, notifiers...)
+}
+
+// newSearcher 根据配置创建全文搜索后端
+func newSearcher(cfg config.SearchConfig, db *gorm.DB) (search.Searcher, error) {
+	if cfg.Backend == config.SearchBackendMySQL {
+		return search.NewMySQLSearcher(db), nil
+	}
+	return search.NewBleveSearcher(db, cfg.BlevePath)
+}
+
+// close 释放应用持有的资源，在后台任务停止后调用
+func (a *app) close() error {
+	return a.searcher.Close()
+}
+
+// handlers 创建路由使用的接口处理器
+func (a *app) handlers(db *gorm.DB) router.Handlers {
+	return router.Handlers{
+		Health:       v1.NewHealthHandler(db),
+		User:         v1.NewUserHandler(a.users),
+		Task:         v1.NewTaskHandler(a.tasks),
+		Wish:         v1.NewWishHandler(a.wishes),
+		Trash:        v1.NewTrashHandler(a.trash),
+		Stats:        v1.NewStatsHandler(a.stats),
+		Reminder:     v1.NewReminderHandler(a.reminders),
+		Notification: v1.NewNotificationHandler(a.notifications),
+		Tag:          v1.NewTagHandler(a.tags),
+		List:         v1.NewListHandler(a.lists),
+		Search:       v1.NewSearchHandler(a.search),
+		Sync:         v1.NewSyncHandler(a.sync, a.tasks, a.wishes, a.lists, a.tags),
+	}
+}
+
+// startWorkers 启动后台任务，服务退出时由 workers.Stop 统一停止
+func (a *app) startWorkers(workers *worker.Group, cfg *config.Config) {
+	workers.Every("trash-purge", cfg.Trash.PurgeInterval, func(ctx context.Context) {
+		tasks, wishes, err := a.trash.Purge(ctx)
+		if err != nil {
+			fmt.Printf("清理回收站失败: %v\n", err)
+			return
+		}
+		if tasks > 0 || wishes > 0 {
+			fmt.Printf("已清理回收站: %d 个任务, %d 个心愿\n", tasks, wishes)
+		}
+	})
+	workers.Every("reminders", cfg.Notify.PollInterval, func(ctx context.Context) {
+		if _, err := a.reminders.FireDue(ctx); err != nil {
+			fmt.Printf("发送提醒失败: %v\n", err)
+		}
+	})
+	workers.Every("task-due-notify", cfg.Notify.DueCheckInterval, func(ctx context.Context) {
+		if _, err := a.notifications.NotifyDueRecurring(ctx); err != nil {
+			fmt.Printf("写入循环任务通知失败: %v\n", err)
+		}
+	})
+	if s, ok := a.searcher.(search.Syncer); ok {
+		workers.Every("search-index", cfg.Search.SyncInterval, func(ctx context.Context) {
+			if err := s.Sync(ctx); err != nil {
+				fmt.Printf("同步搜索索引失败: %v\n", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,576 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/notify"
+	"github.com/PisaListBE/pkg/config"
+	"github.com/PisaListBE/pkg/database"
+	"github.com/PisaListBE/pkg/jwt"
+	"github.com/PisaListBE/pkg/migrate"
+	"github.com/PisaListBE/router"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// testServer 在内存 SQLite 上按 runServer 的方式组装完整应用，接口级的冒烟测试和回归测试都基于它
+type testServer struct {
+	t   *testing.T
+	srv *httptest.Server
+	// db 供测试直接构造接口无法产生的数据
+	db *gorm.DB
+	// app 供测试直接调用后台任务
+	app *app
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	jwt.Init(config.JWTConfig{Secret: "test-secret", Expire: 1})
+
+	cfg := &config.Config{
+		App:      config.AppConfig{DefaultTimezone: "UTC"},
+		Database: config.DatabaseConfig{Driver: database.DriverSQLite, DSN: "file::memory:", ConnectRetries: 1},
+		Trash:    config.TrashConfig{RetentionDays: 30},
+		Notify:   config.NotifyConfig{Channels: []string{notify.ChannelInbox}, MaxAttempts: 5, DueHour: 8},
+		Search:   config.SearchConfig{Backend: config.SearchBackendBleve},
+	}
+	if err := database.InitGormDB(cfg.Database); err != nil {
+		t.Fatal(err)
+	}
+	db := database.GormDB
+	t.Cleanup(func() { database.Close() })
+	if _, err := migrate.Up(db); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+	if err := migrate.Seed(db); err != nil {
+		t.Fatalf("写入初始数据失败: %v", err)
+	}
+
+	a, err := newApp(db, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.close() })
+
+	r := gin.New()
+	router.InitRouter(r, a.handlers(db))
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return &testServer{t: t, srv: srv, db: db, app: a}
+}
+
+// testResponse 读取完毕的响应
+type testResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// decode 把响应体解析到 v，失败时终止测试
+func (r testResponse) decode(t *testing.T, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(r.body, v); err != nil {
+		t.Fatalf("解析响应失败: %v\n%s", err, r.body)
+	}
+}
+
+// do 发送请求，body 不为 nil 时编码为 JSON；header 为成对的请求头名称和值
+func (s *testServer) do(method, path, token string, body interface{}, header ...string) testResponse {
+	s.t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, s.srv.URL+path, reader)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return testResponse{status: resp.StatusCode, header: resp.Header, body: data}
+}
+
+// expect 发送请求并检查状态码
+func (s *testServer) expect(status int, method, path, token string, body interface{}, header ...string) testResponse {
+	s.t.Helper()
+	resp := s.do(method, path, token, body, header...)
+	if resp.status != status {
+		s.t.Fatalf("%s %s = %d, want %d\n%s", method, path, resp.status, status, resp.body)
+	}
+	return resp
+}
+
+// register 注册用户并返回 token
+func (s *testServer) register(username string) string {
+	s.t.Helper()
+	resp := s.expect(http.StatusOK, http.MethodPost, "/api/v1/register", "", map[string]string{
+		"username": username,
+		"password": "123456",
+		"email":    username + "@pisa.test",
+	})
+	var out struct {
+		Token string `json:"token"`
+	}
+	resp.decode(s.t, &out)
+	if out.Token == "" {
+		s.t.Fatalf("注册没有返回 token: %s", resp.body)
+	}
+	return out.Token
+}
+
+// createTask 创建任务并返回创建结果
+func (s *testServer) createTask(token string, body map[string]interface{}) model.Task {
+	s.t.Helper()
+	var task model.Task
+	s.expect(http.StatusOK, http.MethodPost, "/api/v1/tasks", token, body).decode(s.t, &task)
+	return task
+}
+
+func taskPath(id uint, suffix string) string {
+	return "/api/v1/tasks/" + strconv.FormatUint(uint64(id), 10) + suffix
+}
+
+func TestHealth(t *testing.T) {
+	s := newTestServer(t)
+	s.expect(http.StatusOK, http.MethodGet, "/healthz", "", nil)
+	s.expect(http.StatusOK, http.MethodGet, "/readyz", "", nil)
+	s.expect(http.StatusOK, http.MethodGet, "/version", "", nil)
+}
+
+func TestAuth(t *testing.T) {
+	s := newTestServer(t)
+	s.expect(http.StatusUnauthorized, http.MethodGet, "/api/v1/tasks", "", nil)
+	s.expect(http.StatusUnauthorized, http.MethodGet, "/api/v1/tasks", "invalid", nil)
+
+	token := s.register("abc")
+	var me struct {
+		Username string `json:"username"`
+	}
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/users/me", token, nil).decode(t, &me)
+	if me.Username != "abc" {
+		t.Errorf("username = %q, want abc", me.Username)
+	}
+	s.expect(http.StatusOK, http.MethodPost, "/api/v1/login", "", map[string]string{"username": "abc", "password": "123456"})
+	s.expect(http.StatusUnauthorized, http.MethodPost, "/api/v1/login", "", map[string]string{"username": "abc", "password": "654321"})
+}
+
+func TestTaskLifecycle(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	other := s.register("xyz")
+
+	task := s.createTask(token, map[string]interface{}{"event": "买牛奶", "importance_level": 3})
+	path := taskPath(task.ID, "")
+
+	// 其他用户看不到
+	s.expect(http.StatusNotFound, http.MethodGet, path, other, nil)
+
+	resp := s.expect(http.StatusOK, http.MethodGet, path, token, nil)
+	tag := resp.header.Get("ETag")
+	if tag != `"1"` {
+		t.Fatalf("ETag = %q, want \"1\"", tag)
+	}
+	s.expect(http.StatusNotModified, http.MethodGet, path, token, nil, "If-None-Match", tag)
+
+	s.expect(http.StatusOK, http.MethodPatch, path, token, map[string]string{"description": "全脂"}, "If-Match", tag)
+	s.expect(http.StatusPreconditionFailed, http.MethodPatch, path, token, map[string]string{"description": "脱脂"}, "If-Match", tag)
+
+	var done model.Task
+	s.expect(http.StatusOK, http.MethodPut, taskPath(task.ID, "/complete"), token, nil).decode(t, &done)
+	if !done.Completed {
+		t.Fatal("任务没有完成")
+	}
+	var page struct {
+		Items []model.Task `json:"items"`
+		Total int64        `json:"total"`
+	}
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/tasks?completed=true", token, nil).decode(t, &page)
+	if page.Total != 1 || page.Items[0].ID != task.ID {
+		t.Fatalf("completed=true 返回 %+v", page)
+	}
+
+	s.expect(http.StatusOK, http.MethodDelete, path, token, nil)
+	s.expect(http.StatusNotFound, http.MethodGet, path, token, nil)
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/trash", token, nil)
+	s.expect(http.StatusOK, http.MethodPost, taskPath(task.ID, "/restore"), token, nil)
+	s.expect(http.StatusOK, http.MethodGet, path, token, nil)
+}
+
+// user-012: PUT 整体替换任务，PATCH 只修改请求中的字段
+func TestUpdateTaskReplacesOmittedFields(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+
+	var list struct {
+		ID uint `json:"id"`
+	}
+	s.expect(http.StatusOK, http.MethodPost, "/api/v1/lists", token, map[string]string{"name": "购物"}).decode(t, &list)
+	var tag model.Tag
+	s.expect(http.StatusOK, http.MethodPost, "/api/v1/tags", token, map[string]string{"name": "日常"}).decode(t, &tag)
+	parent := s.createTask(token, map[string]interface{}{"event": "周末"})
+	fields := map[string]interface{}{
+		"event":            "买牛奶",
+		"description":      "全脂",
+		"importance_level": 3,
+		"auto_complete":    true,
+		"due_at":           "2030-01-02T10:00:00Z",
+		"parent_id":        parent.ID,
+		"list_id":          list.ID,
+		"tag_ids":          []uint{tag.ID},
+	}
+	task := s.createTask(token, fields)
+
+	// PATCH 只修改请求中的字段
+	var updated model.Task
+	s.expect(http.StatusOK, http.MethodPatch, taskPath(task.ID, ""), token, map[string]string{"event": "买酸奶"}).decode(t, &updated)
+	if updated.Event != "买酸奶" || updated.Description != "全脂" || updated.ImportanceLevel != 3 || !updated.AutoComplete ||
+		updated.DueAt == nil || updated.ParentID == nil || updated.ListID == nil || len(updated.Tags) != 1 {
+		t.Fatalf("PATCH 修改了请求中没有的字段: %+v", updated)
+	}
+
+	// PUT 整体替换，省略的字段全部清除
+	s.expect(http.StatusOK, http.MethodPut, taskPath(task.ID, ""), token, map[string]string{"event": "买牛奶"}).decode(t, &updated)
+	if updated.Event != "买牛奶" || updated.Description != "" || updated.ImportanceLevel != 0 || updated.AutoComplete ||
+		updated.DueAt != nil || updated.ParentID != nil || updated.ListID != nil || len(updated.Tags) != 0 {
+		t.Fatalf("PUT 保留了省略的字段: %+v", updated)
+	}
+
+	s.expect(http.StatusOK, http.MethodPut, taskPath(task.ID, ""), token, fields).decode(t, &updated)
+	if updated.Description != "全脂" || updated.DueAt == nil || updated.ParentID == nil || updated.ListID == nil || len(updated.Tags) != 1 {
+		t.Fatalf("PUT 没有写入请求中的字段: %+v", updated)
+	}
+	s.expect(http.StatusOK, http.MethodPatch, taskPath(task.ID, ""), token, map[string]interface{}{"due_at": nil}).decode(t, &updated)
+	if updated.DueAt != nil || updated.ListID == nil {
+		t.Fatalf("PATCH null 只应清除截止时间: %+v", updated)
+	}
+}
+
+// user-017: 没有子任务的自动完成任务，编辑后保持手动设置的完成状态
+func TestAutoCompleteWithoutChildrenKeepsCompletion(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+
+	task := s.createTask(token, map[string]interface{}{"event": "整理", "auto_complete": true})
+	s.expect(http.StatusOK, http.MethodPut, taskPath(task.ID, "/complete"), token, nil)
+
+	var updated model.Task
+	s.expect(http.StatusOK, http.MethodPatch, taskPath(task.ID, ""), token, map[string]string{"description": "书架"}).decode(t, &updated)
+	if !updated.Completed {
+		t.Fatal("编辑后任务被重新打开")
+	}
+}
+
+// user-023: 非对象的补丁会替换整个资源，PATCH 接口不接受
+func TestPatchTaskRejectsNonObject(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	task := s.createTask(token, map[string]interface{}{"event": "买牛奶"})
+
+	for _, patch := range []interface{}{"x", []int{1}, nil} {
+		resp := s.do(http.MethodPatch, taskPath(task.ID, ""), token, patch, "Content-Type", "application/merge-patch+json")
+		if patch == nil {
+			// 没有请求体
+			if resp.status != http.StatusBadRequest {
+				t.Errorf("空补丁 = %d, want 400", resp.status)
+			}
+			continue
+		}
+		if resp.status != http.StatusBadRequest {
+			t.Errorf("补丁 %v = %d, want 400\n%s", patch, resp.status, resp.body)
+		}
+	}
+}
+
+// user-024: 状态、顺序、子资源等修改同样检查 If-Match
+func TestIfMatchOnTaskMutations(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	task := s.createTask(token, map[string]interface{}{"event": "买牛奶", "due_at": "2030-01-02T10:00:00Z"})
+	other := s.createTask(token, map[string]interface{}{"event": "买面包"})
+	stale := []string{"If-Match", `"9"`}
+
+	s.expect(http.StatusPreconditionFailed, http.MethodPut, taskPath(task.ID, "/complete"), token, nil, stale...)
+	s.expect(http.StatusPreconditionFailed, http.MethodPut, taskPath(task.ID, "/position"), token,
+		map[string]uint{"after_id": other.ID}, stale...)
+	s.expect(http.StatusPreconditionFailed, http.MethodPost, taskPath(task.ID, "/subtasks"), token,
+		map[string]string{"content": "全脂"}, stale...)
+	s.expect(http.StatusPreconditionFailed, http.MethodPost, taskPath(task.ID, "/reminders"), token,
+		map[string]int{"offset_minutes": 30}, stale...)
+	s.expect(http.StatusPreconditionFailed, http.MethodPut, "/api/v1/tasks/importance", token, map[string]interface{}{
+		"tasks": []map[string]interface{}{{"id": task.ID, "importance_level": 5, "version": 9}},
+	})
+
+	// 版本没有变化
+	var current model.Task
+	s.expect(http.StatusOK, http.MethodGet, taskPath(task.ID, ""), token, nil).decode(t, &current)
+	if current.Version != 1 || current.Completed || current.ImportanceLevel != 0 {
+		t.Fatalf("冲突的请求修改了任务: %+v", current)
+	}
+
+	s.expect(http.StatusOK, http.MethodPut, taskPath(task.ID, "/complete"), token, nil, "If-Match", `"1"`)
+
+	s.expect(http.StatusOK, http.MethodDelete, taskPath(task.ID, ""), token, nil)
+	s.expect(http.StatusPreconditionFailed, http.MethodPost, taskPath(task.ID, "/restore"), token, nil, stale...)
+}
+
+// user-013: 非循环任务取消完成时删除全部完成记录，包括按旧时区日期生成的记录
+func TestUncompleteRemovesStaleCompletion(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	task := s.createTask(token, map[string]interface{}{"event": "买牛奶"})
+	s.expect(http.StatusOK, http.MethodPut, taskPath(task.ID, "/complete"), token, nil)
+
+	// 模拟迁移 0004 按服务器本地日期生成的记录
+	err := s.db.Model(&model.TaskCompletion{}).Where("task_id = ?", task.ID).
+		Update("occurrence_date", "2000-01-01").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var undone model.Task
+	s.expect(http.StatusOK, http.MethodPut, taskPath(task.ID, "/complete"), token, nil).decode(t, &undone)
+	if undone.Completed {
+		t.Fatal("任务没有取消完成")
+	}
+	var count int64
+	if err := s.db.Model(&model.TaskCompletion{}).Where("task_id = ?", task.ID).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("取消完成后仍有 %d 条完成记录", count)
+	}
+}
+
+// user-019: 恢复任务时所属清单已删除，只把恢复的任务及其子任务移出清单
+func TestRestoreTaskFromDeletedList(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	var list model.List
+	s.expect(http.StatusOK, http.MethodPost, "/api/v1/lists", token, map[string]string{"name": "工作"}).decode(t, &list)
+
+	a := s.createTask(token, map[string]interface{}{"event": "周报", "list_id": list.ID})
+	child := s.createTask(token, map[string]interface{}{"event": "汇总数据", "parent_id": a.ID})
+	b := s.createTask(token, map[string]interface{}{"event": "会议", "list_id": list.ID})
+	s.expect(http.StatusOK, http.MethodDelete, "/api/v1/lists/"+strconv.Itoa(int(list.ID))+"?mode=cascade", token, nil)
+
+	var restored model.Task
+	s.expect(http.StatusOK, http.MethodPost, taskPath(a.ID, "/restore"), token, nil).decode(t, &restored)
+	if restored.ListID != nil {
+		t.Fatalf("恢复的任务仍属于已删除的清单 %d", *restored.ListID)
+	}
+	var got model.Task
+	s.expect(http.StatusOK, http.MethodGet, taskPath(child.ID, ""), token, nil).decode(t, &got)
+	if got.ListID != nil {
+		t.Fatalf("一起恢复的子任务仍属于已删除的清单 %d", *got.ListID)
+	}
+
+	// 仍在回收站中的其他任务不受影响
+	var trash struct {
+		Tasks []model.Task `json:"tasks"`
+	}
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/trash", token, nil).decode(t, &trash)
+	if len(trash.Tasks) != 1 || trash.Tasks[0].ID != b.ID {
+		t.Fatalf("回收站 = %+v, want 只有任务 %d", trash.Tasks, b.ID)
+	}
+	if trash.Tasks[0].ListID == nil || *trash.Tasks[0].ListID != list.ID || trash.Tasks[0].Version != b.Version {
+		t.Fatalf("回收站中的任务被修改: %+v", trash.Tasks[0])
+	}
+}
+
+// user-019: 清理回收站时一起清理超过保留期的已删除清单
+func TestPurgeDeletedLists(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	var expired, recent model.List
+	s.expect(http.StatusOK, http.MethodPost, "/api/v1/lists", token, map[string]string{"name": "旧清单"}).decode(t, &expired)
+	s.expect(http.StatusOK, http.MethodPost, "/api/v1/lists", token, map[string]string{"name": "新清单"}).decode(t, &recent)
+	for _, id := range []uint{expired.ID, recent.ID} {
+		s.expect(http.StatusOK, http.MethodDelete, "/api/v1/lists/"+strconv.Itoa(int(id)), token, nil)
+	}
+	err := s.db.Unscoped().Model(&model.List{}).Where("id = ?", expired.ID).
+		Update("deleted_at", time.Now().UTC().AddDate(0, 0, -31)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := s.app.trash.Purge(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var ids []uint
+	if err := s.db.Unscoped().Model(&model.List{}).Order("id").Pluck("id", &ids).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != recent.ID {
+		t.Fatalf("清理后剩余清单 %v, want [%d]", ids, recent.ID)
+	}
+}
+
+// zoneAtHour 返回当前本地时间为 hour 点的 Etc 时区
+func zoneAtHour(hour int) string {
+	offset := ((hour-time.Now().UTC().Hour())%24 + 24) % 24
+	if offset > 14 {
+		offset -= 24
+	}
+	switch {
+	case offset > 0:
+		return "Etc/GMT-" + strconv.Itoa(offset)
+	case offset < 0:
+		return "Etc/GMT+" + strconv.Itoa(-offset)
+	}
+	return "Etc/GMT"
+}
+
+// user-016: 当天循环任务通知按时区扫描，未到发送时间、未开始和已通知的任务不会重复处理
+func TestNotifyDueRecurring(t *testing.T) {
+	s := newTestServer(t)
+	due := s.register("abc")
+	early := s.register("xyz")
+	s.expect(http.StatusOK, http.MethodPut, "/api/v1/users/me/timezone", due, map[string]string{"timezone": zoneAtHour(12)})
+	s.expect(http.StatusOK, http.MethodPut, "/api/v1/users/me/timezone", early, map[string]string{"timezone": zoneAtHour(2)})
+
+	task := s.createTask(due, map[string]interface{}{"event": "背单词", "recurrence": "daily"})
+	s.createTask(due, map[string]interface{}{
+		"event":      "健身",
+		"recurrence": "daily",
+		"start_at":   time.Now().Add(48 * time.Hour).Format(time.RFC3339),
+	})
+	s.createTask(early, map[string]interface{}{"event": "跑步", "recurrence": "daily"})
+
+	ctx := context.Background()
+	for i, want := range []int{1, 0} {
+		created, err := s.app.notifications.NotifyDueRecurring(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created != want {
+			t.Fatalf("第 %d 次写入 %d 条通知, want %d", i+1, created, want)
+		}
+	}
+
+	var page struct {
+		Items []model.Notification `json:"items"`
+	}
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/notifications", due, nil).decode(t, &page)
+	if len(page.Items) != 1 || page.Items[0].ResourceID != task.ID {
+		t.Fatalf("通知 = %+v, want 任务 %d 的一条通知", page.Items, task.ID)
+	}
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/notifications", early, nil).decode(t, &page)
+	if len(page.Items) != 0 {
+		t.Fatalf("未到发送时间的用户收到通知: %+v", page.Items)
+	}
+}
+
+// createWish 创建心愿并返回
+func (s *testServer) createWish(token string, body map[string]interface{}) model.Wish {
+	s.t.Helper()
+	var wish model.Wish
+	s.expect(http.StatusOK, http.MethodPost, "/api/v1/wishes", token, body).decode(s.t, &wish)
+	return wish
+}
+
+// user-008: 心愿的 JSON 不包含 deleted_at，回收站中的心愿带有删除时间
+func TestWishDeletedAtJSON(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	wish := s.createWish(token, map[string]interface{}{"event": "环游世界"})
+	path := "/api/v1/wishes/" + strconv.Itoa(int(wish.ID))
+
+	var body map[string]json.RawMessage
+	s.expect(http.StatusOK, http.MethodGet, path, token, nil).decode(t, &body)
+	if _, ok := body["deleted_at"]; ok {
+		t.Fatalf("心愿 JSON 包含 deleted_at: %s", body["deleted_at"])
+	}
+
+	s.expect(http.StatusOK, http.MethodDelete, path, token, nil)
+	var trash struct {
+		Wishes []map[string]json.RawMessage `json:"wishes"`
+	}
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/trash", token, nil).decode(t, &trash)
+	if len(trash.Wishes) != 1 {
+		t.Fatalf("回收站有 %d 个心愿, want 1", len(trash.Wishes))
+	}
+	var deletedAt time.Time
+	if err := json.Unmarshal(trash.Wishes[0]["deleted_at"], &deletedAt); err != nil || deletedAt.IsZero() {
+		t.Fatalf("回收站中心愿的 deleted_at = %s", trash.Wishes[0]["deleted_at"])
+	}
+}
+
+// user-024: 批量更新优先级和从回收站恢复时版本不一致，返回 412 以及当前表示和 ETag
+func TestPreconditionFailedReturnsCurrent(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	a := s.createTask(token, map[string]interface{}{"event": "买牛奶"})
+	b := s.createTask(token, map[string]interface{}{"event": "背单词"})
+	stale := a.Version
+	s.expect(http.StatusOK, http.MethodPatch, taskPath(b.ID, ""), token, map[string]string{"description": "50 个"})
+
+	check := func(resp testResponse, id uint) {
+		t.Helper()
+		var current struct {
+			ID      uint `json:"id"`
+			Version uint `json:"version"`
+		}
+		resp.decode(t, &current)
+		if current.ID != id {
+			t.Fatalf("412 返回了 %d, want %d: %s", current.ID, id, resp.body)
+		}
+		if want := `"` + strconv.Itoa(int(current.Version)) + `"`; resp.header.Get("ETag") != want {
+			t.Fatalf("ETag = %q, want %q", resp.header.Get("ETag"), want)
+		}
+	}
+
+	resp := s.expect(http.StatusPreconditionFailed, http.MethodPut, "/api/v1/tasks/importance", token, map[string]interface{}{
+		"tasks": []map[string]interface{}{
+			{"id": a.ID, "importance_level": 2, "version": a.Version},
+			{"id": b.ID, "importance_level": 3, "version": b.Version},
+		},
+	})
+	check(resp, b.ID)
+
+	s.expect(http.StatusOK, http.MethodDelete, taskPath(a.ID, ""), token, nil)
+	resp = s.expect(http.StatusPreconditionFailed, http.MethodPost, taskPath(a.ID, "/restore"), token, nil,
+		"If-Match", `"`+strconv.Itoa(int(stale))+`"`)
+	check(resp, a.ID)
+
+	wish := s.createWish(token, map[string]interface{}{"event": "环游世界"})
+	path := "/api/v1/wishes/" + strconv.Itoa(int(wish.ID))
+	s.expect(http.StatusOK, http.MethodDelete, path, token, nil)
+	resp = s.expect(http.StatusPreconditionFailed, http.MethodPost, path+"/restore", token, nil,
+		"If-Match", `"`+strconv.Itoa(int(wish.Version))+`"`)
+	check(resp, wish.ID)
+
+	// 按 412 返回的 ETag 重试可以恢复
+	s.expect(http.StatusOK, http.MethodPost, path+"/restore", token, nil, "If-Match", resp.header.Get("ETag"))
+	s.expect(http.StatusOK, http.MethodGet, path, token, nil)
+}
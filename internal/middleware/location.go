@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocationKey 请求上下文中保存用户时区的键
+const LocationKey = "location"
+
+// ErrUnknownUser 由 LocationResolver 返回，表示 token 对应的用户已不存在
+var ErrUnknownUser = errors.New("用户不存在")
+
+// LocationResolver 根据用户ID查询其时区
+type LocationResolver func(ctx context.Context, userID uint) (*time.Location, error)
+
+// Location 查询当前用户的时区并写入上下文，需放在 JWT 中间件之后
+func Location(resolve LocationResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		loc, err := resolve(c.Request.Context(), c.GetUint("userID"))
+		if errors.Is(err, ErrUnknownUser) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code": 401,
+				"msg":  "用户不存在",
+			})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			fmt.Printf("获取用户时区失败: %v\n", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取用户时区失败"})
+			c.Abort()
+			return
+		}
+
+		c.Set(LocationKey, loc)
+		c.Next()
+	}
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// ChecklistItem is an ordered checklist entry under a task
+// @Description 任务下的清单项，按 position 排序
+type ChecklistItem struct {
+	ID        uint      `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt time.Time `json:"created_at" example:"2025-01-10T15:04:05Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2025-01-10T15:04:05Z"`
+	// TaskID is the task the item belongs to
+	TaskID uint `gorm:"not null;index" json:"task_id" example:"1"`
+	// Content is the text of the item
+	Content string `gorm:"type:varchar(256);not null" json:"content" example:"Milk"`
+	// Completed indicates if the item is checked
+	Completed bool `gorm:"not null;default:false" json:"completed" example:"false"`
+	// CompletedAt records when the item was checked
+	CompletedAt *time.Time `json:"completed_at" example:"2025-01-10T15:04:05Z"`
+	// Position orders the items within a task, ascending
+	Position int `gorm:"not null;default:0" json:"position" example:"1"`
+}
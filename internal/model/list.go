@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// List 任务清单，用于把任务按项目分组
+// @Description 任务清单（项目）
+type List struct {
+	ID        uint           `json:"id" gorm:"primarykey" example:"1"`
+	CreatedAt time.Time      `json:"created_at" example:"2024-01-10T15:04:05Z"`
+	UpdatedAt time.Time      `json:"updated_at" example:"2024-01-10T15:04:05Z"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	UserID    uint           `json:"user_id" gorm:"not null;index;uniqueIndex:idx_lists_user_client,priority:1" example:"1"`
+	Name      string         `json:"name" gorm:"type:varchar(64);not null" example:"装修"`
+	Color     string         `json:"color" gorm:"type:varchar(16);not null;default:''" example:"#3366FF"`
+	Icon      string         `json:"icon" gorm:"type:varchar(32);not null;default:''" example:"home"`
+	// SortOrder 清单的排列顺序，升序
+	SortOrder int `json:"sort_order" gorm:"not null;default:0" example:"1"`
+	// Archived 已归档的清单默认不在清单列表中显示
+	Archived bool `json:"archived" gorm:"not null;default:false" example:"false"`
+	// ClientID 离线客户端通过 POST /sync 创建时生成的ID，同一用户内唯一
+	ClientID *string `json:"client_id" gorm:"type:varchar(64);uniqueIndex:idx_lists_user_client,priority:2" example:"5f3c2a10-8e4d-4b7a-9c21-6d0e1f2a3b4c"`
+}
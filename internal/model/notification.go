@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// 通知类型
+const (
+	NotificationReminder  = "reminder"
+	NotificationTaskDue   = "task_due"
+	NotificationWishLiked = "wish_liked"
+)
+
+// Notification 站内通知
+// @Description 用户收件箱中的一条通知
+type Notification struct {
+	ID        uint      `json:"id" gorm:"primarykey" example:"1"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-10T15:04:05Z"`
+	UserID    uint      `json:"user_id" gorm:"not null;index:idx_notifications_user_read,priority:1;uniqueIndex:idx_notifications_user_dedup,priority:1" example:"1"`
+	Type      string    `json:"type" gorm:"type:varchar(32);not null" example:"reminder"`
+	Title     string    `json:"title" gorm:"type:varchar(255);not null" example:"任务提醒：背单词"`
+	Body      string    `json:"body" gorm:"type:text" example:"截止时间 2024-01-10 09:00"`
+	// Resource 和 ResourceID 指向通知相关的对象，如 task
+	Resource   string     `json:"resource" gorm:"type:varchar(32);not null;default:''" example:"task"`
+	ResourceID uint       `json:"resource_id" gorm:"not null;default:0" example:"1"`
+	ReadAt     *time.Time `json:"read_at" gorm:"index:idx_notifications_user_read,priority:2" example:"2024-01-10T15:04:05Z"`
+	// DedupKey 不为空时同一用户只会收到一条相同 key 的通知
+	DedupKey *string `json:"-" gorm:"type:varchar(64);uniqueIndex:idx_notifications_user_dedup,priority:2"`
+}
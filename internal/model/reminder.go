@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Reminder 任务提醒
+// @Description 任务的提醒时间，可以是绝对时间，也可以是截止时间前的若干分钟
+type Reminder struct {
+	ID        uint      `json:"id" gorm:"primarykey" example:"1"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-10T15:04:05Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2024-01-10T15:04:05Z"`
+	TaskID    uint      `json:"task_id" gorm:"not null;index" example:"1"`
+	UserID    uint      `json:"user_id" gorm:"not null;index" example:"1"`
+	// RemindAt 绝对提醒时间，与 OffsetMinutes 二选一
+	RemindAt *time.Time `json:"remind_at,omitempty" example:"2024-01-10T09:00:00Z"`
+	// OffsetMinutes 在任务截止时间前多少分钟提醒，截止时间变化时重新计算
+	OffsetMinutes *int `json:"offset_minutes,omitempty" example:"30"`
+	// FireAt 下一次尝试发送的时间，没有截止时间的相对提醒为空
+	FireAt *time.Time `json:"fire_at" gorm:"index" example:"2024-01-10T08:30:00Z"`
+	// Channels 发送渠道，逗号分隔，为空时使用 notify.channels
+	Channels string `json:"channels" gorm:"type:varchar(64);not null;default:''" example:"inbox,email"`
+	// Delivered 已发送成功的渠道，重试时跳过
+	Delivered string     `json:"-" gorm:"type:varchar(64);not null;default:''"`
+	SentAt    *time.Time `json:"sent_at" example:"2024-01-10T08:30:01Z"`
+	Attempts  int        `json:"attempts" gorm:"not null;default:0" example:"0"`
+	LastError string     `json:"last_error,omitempty" gorm:"type:varchar(512);not null;default:''" example:""`
+}
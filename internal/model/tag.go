@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tag 用户自定义标签，可以同时用于任务和心愿
+// @Description 用户自定义标签
+type Tag struct {
+	ID        uint           `json:"id" gorm:"primarykey" example:"1"`
+	CreatedAt time.Time      `json:"created_at" example:"2024-01-10T15:04:05Z"`
+	UpdatedAt time.Time      `json:"updated_at" example:"2024-01-10T15:04:05Z"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	UserID    uint           `json:"user_id" gorm:"not null;index;uniqueIndex:idx_tags_user_client,priority:1" example:"1"`
+	Name      string         `json:"name" gorm:"type:varchar(64);not null" example:"旅行"`
+	Color     string         `json:"color" gorm:"type:varchar(16);not null;default:''" example:"#FF8800"`
+	// ClientID 离线客户端通过 POST /sync 创建时生成的ID，同一用户内唯一
+	ClientID *string `json:"client_id" gorm:"type:varchar(64);uniqueIndex:idx_tags_user_client,priority:2" example:"1c9e7d52-3a4b-4f60-8d12-7e8f9a0b1c2d"`
+}
+
+// SharedWishTag 分享到社区时复制的心愿标签，只保留名称和颜色，供社区按主题浏览
+type SharedWishTag struct {
+	ID           uint   `json:"-" gorm:"primarykey"`
+	SharedWishID uint   `json:"-" gorm:"not null;index"`
+	Name         string `json:"name" gorm:"type:varchar(64);not null;index" example:"旅行"`
+	Color        string `json:"color" gorm:"type:varchar(16);not null;default:''" example:"#FF8800"`
+}
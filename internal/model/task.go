@@ -3,7 +3,7 @@ package model
 import (
 	"time"
 
-	_ "gorm.io/gorm"
+	"gorm.io/gorm"
 )
 
 // gorm.Model definition
@@ -11,7 +11,8 @@ type Model struct {
 	ID        uint `gorm:"primarykey"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	DeletedAt *time.Time `gorm:"index"`
+	// DeletedAt 软删除时间，删除的记录进入回收站，到期后由后台任务彻底清除
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
 // Task represents a todo task
@@ -19,7 +20,7 @@ type Model struct {
 type Task struct {
 	Model
 	// UserID is the owner of the task
-	UserID uint `gorm:"not null" json:"user_id" example:"1"`
+	UserID uint `gorm:"not null;index:idx_tasks_user_position,priority:1;uniqueIndex:idx_tasks_user_client,priority:1" json:"user_id" example:"1"`
 	// Event is the main task description
 	Event string `gorm:"type:varchar(256);not null" json:"event" example:"Buy groceries"`
 	// Completed indicates if the task is done
@@ -28,8 +29,30 @@ type Task struct {
 	IsCycle bool `gorm:"default:false" json:"is_cycle" example:"false"`
 	// Description provides additional details about the task
 	Description string `gorm:"type:text" json:"description" example:"Milk, eggs, bread"`
-	// ImportanceLevel indicates task priority (0-5)
+	// ImportanceLevel indicates task priority from 0 (none) to 5 (highest)
 	ImportanceLevel int `gorm:"default:0" json:"importance_level" example:"3"`
 	// CompletedDate records when the task was completed
 	CompletedDate time.Time `gorm:"column:completed_date;default:null" json:"completed_date,omitempty" example:"2025-01-10 15:04:05"`
+	// StartAt hides the task from today's list until this time
+	StartAt *time.Time `json:"start_at" example:"2025-01-10T09:00:00Z"`
+	// DueAt is the deadline of the task, unfinished tasks past it are overdue
+	DueAt *time.Time `gorm:"index" json:"due_at" example:"2025-01-12T18:00:00Z"`
+	// Recurrence is the repeat rule of a cycle task (RRULE subset), empty for one-off tasks
+	Recurrence string `gorm:"type:varchar(255);not null;default:''" json:"recurrence" example:"FREQ=WEEKLY;BYDAY=MO,WE,FR"`
+	// ParentID nests this task under another task as a subtask
+	ParentID *uint `gorm:"index" json:"parent_id" example:"1"`
+	// AutoComplete completes the task automatically once all of its subtasks and checklist items are done
+	AutoComplete bool `gorm:"not null;default:false" json:"auto_complete" example:"false"`
+	// Position is the manual sort key, tasks are ordered by it ascending (see pkg/ordering)
+	Position string `gorm:"type:varchar(255);not null;default:'';index:idx_tasks_user_position,priority:2" json:"position" example:"i"`
+	// ListID is the list (project) the task belongs to, nil for tasks not in any list
+	ListID *uint `gorm:"index" json:"list_id" example:"1"`
+	// Version is incremented on every change, it is exposed as the ETag for optimistic concurrency control
+	Version uint `gorm:"not null;default:1" json:"version" example:"1"`
+	// ClientID is the ID generated by an offline client that created the task through POST /sync, unique per user
+	ClientID *string `gorm:"type:varchar(64);uniqueIndex:idx_tasks_user_client,priority:2" json:"client_id" example:"7b0e4c1a-9d2f-4e3b-8a61-0c5d2f9e1a34"`
+	// Tags are the user's tags attached to the task
+	Tags []Tag `gorm:"many2many:task_tags" json:"tags"`
+	// NextOccurrence is the next date a cycle task is due, computed on read
+	NextOccurrence *time.Time `gorm:"-" json:"next_occurrence,omitempty" example:"2025-01-13T00:00:00Z"`
 }
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// TaskCompletion records one completed occurrence of a task
+// @Description 任务某一次的完成记录，循环任务每次完成各有一条
+type TaskCompletion struct {
+	ID        uint      `gorm:"primarykey" json:"id" example:"1"`
+	CreatedAt time.Time `json:"created_at" example:"2025-01-10T15:04:05Z"`
+	// TaskID is the completed task
+	TaskID uint `gorm:"not null;uniqueIndex:idx_task_completions_task_date" json:"task_id" example:"1"`
+	// UserID is the owner of the task
+	UserID uint `gorm:"not null;index;index:idx_task_completions_user_date,priority:1" json:"user_id" example:"1"`
+	// OccurrenceDate is the date (YYYY-MM-DD) of the occurrence this completion belongs to
+	OccurrenceDate string `gorm:"type:varchar(10);not null;uniqueIndex:idx_task_completions_task_date;index:idx_task_completions_user_date,priority:2" json:"occurrence_date" example:"2025-01-10"`
+	// CompletedAt records when the occurrence was completed
+	CompletedAt time.Time `gorm:"not null;index" json:"completed_at" example:"2025-01-10T15:04:05Z"`
+}
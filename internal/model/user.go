@@ -9,4 +9,6 @@ type User struct {
 	Username string `gorm:"type:varchar(32);uniqueIndex;not null"`
 	Password string `gorm:"type:varchar(255);not null"`
 	Email    string `gorm:"type:varchar(255);uniqueIndex;not null"`
+	// Timezone IANA 时区名，为空时使用 app.default_timezone
+	Timezone string `gorm:"type:varchar(64);not null;default:''"`
 }
@@ -2,20 +2,27 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Wish 心愿模型
 // @Description 用户的心愿信息
 type Wish struct {
-	ID          uint       `json:"id" gorm:"primarykey" example:"1"`
-	CreatedAt   time.Time  `json:"created_at" example:"2024-01-10T15:04:05Z"`
-	UpdatedAt   time.Time  `json:"updated_at" example:"2024-01-10T15:04:05Z"`
-	DeletedAt   *time.Time `json:"deleted_at,omitempty" example:"2024-01-10T15:04:05Z"`
-	UserID      uint       `json:"user_id" gorm:"not null" example:"1"`
-	Event       string     `json:"event" gorm:"type:varchar(256);not null" example:"环游世界"`
-	IsCycle     bool       `json:"is_cycle" gorm:"default:false" example:"false"`
-	Description string     `json:"description" gorm:"type:text" example:"想去看看世界的每个角落"`
-	IsShared    bool       `json:"is_shared" gorm:"default:false" example:"false"`
+	ID          uint           `json:"id" gorm:"primarykey" example:"1"`
+	CreatedAt   time.Time      `json:"created_at" example:"2024-01-10T15:04:05Z"`
+	UpdatedAt   time.Time      `json:"updated_at" example:"2024-01-10T15:04:05Z"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	UserID      uint           `json:"user_id" gorm:"not null;uniqueIndex:idx_wishes_user_client,priority:1" example:"1"`
+	Event       string         `json:"event" gorm:"type:varchar(256);not null" example:"环游世界"`
+	IsCycle     bool           `json:"is_cycle" gorm:"default:false" example:"false"`
+	Description string         `json:"description" gorm:"type:text" example:"想去看看世界的每个角落"`
+	IsShared    bool           `json:"is_shared" gorm:"default:false" example:"false"`
+	// Version 每次修改加一，作为 ETag 用于乐观并发控制
+	Version uint `json:"version" gorm:"not null;default:1" example:"1"`
+	// ClientID 离线客户端通过 POST /sync 创建时生成的ID，同一用户内唯一
+	ClientID *string `json:"client_id" gorm:"type:varchar(64);uniqueIndex:idx_wishes_user_client,priority:2" example:"7b0e4c1a-9d2f-4e3b-8a61-0c5d2f9e1a34"`
+	Tags     []Tag   `json:"tags" gorm:"many2many:wish_tags"`
 }
 
 // SharedWish 共享心愿模型
@@ -29,4 +36,15 @@ type SharedWish struct {
 	Event          string     `json:"event" gorm:"type:varchar(256);not null" example:"环游世界"`
 	Description    string     `json:"description" gorm:"type:text" example:"想去看看世界的每个角落"`
 	SharedByUserID uint       `json:"shared_by_user_id" gorm:"not null" example:"1"`
+	LikeCount      int        `json:"like_count" gorm:"not null;default:0" example:"3"`
+	// Tags 分享时从原心愿复制的标签
+	Tags []SharedWishTag `json:"tags"`
+}
+
+// SharedWishLike 用户对社区心愿的点赞
+type SharedWishLike struct {
+	ID           uint      `json:"id" gorm:"primarykey" example:"1"`
+	CreatedAt    time.Time `json:"created_at" example:"2024-01-10T15:04:05Z"`
+	SharedWishID uint      `json:"shared_wish_id" gorm:"not null;uniqueIndex:idx_shared_wish_likes_wish_user,priority:1" example:"1"`
+	UserID       uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_shared_wish_likes_wish_user,priority:2" example:"2"`
 }
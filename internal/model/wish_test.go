@@ -0,0 +1,23 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestWishJSONOmitsDeletedAt(t *testing.T) {
+	wish := Wish{ID: 1, Event: "环游世界", DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}
+	for _, w := range []Wish{{ID: 1, Event: "环游世界"}, wish} {
+		data, err := json.Marshal(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "deleted_at") {
+			t.Fatalf("心愿 JSON 包含 deleted_at: %s", data)
+		}
+	}
+}
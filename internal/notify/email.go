@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"net/smtp"
+	"time"
+
+	"github.com/PisaListBE/pkg/config"
+)
+
+// EmailNotifier 通过 SMTP 发送邮件通知。
+// 未配置用户名时不进行认证，可以直接对接本地的 mailpit、MailHog 等测试服务。
+type EmailNotifier struct {
+	cfg config.SMTPConfig
+}
+
+// NewEmailNotifier 创建邮件渠道
+func NewEmailNotifier(cfg config.SMTPConfig) *EmailNotifier {
+	return &EmailNotifier{cfg: cfg}
+}
+
+func (n *EmailNotifier) Name() string { return ChannelEmail }
+
+func (n *EmailNotifier) Notify(ctx context.Context, msg Message) error {
+	if msg.Email == "" {
+		return errors.New("用户没有邮箱地址")
+	}
+	from, err := mail.ParseAddress(n.cfg.From)
+	if err != nil {
+		return fmt.Errorf("无效的发件人地址: %w", err)
+	}
+
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+
+	// net/smtp 不支持 context，放到 goroutine 中以便在服务退出时不被阻塞
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(n.cfg.Addr(), auth, from.Address, []string{msg.Email}, buildMail(from.String(), msg))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// buildMail 生成 UTF-8 纯文本邮件
+func buildMail(from string, msg Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.Email)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", msg.Title))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
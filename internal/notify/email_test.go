@@ -0,0 +1,186 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PisaListBE/pkg/config"
+)
+
+// smtpSession 测试 SMTP 服务收到的一封邮件
+type smtpSession struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// startSMTP 启动只接收一封邮件的 SMTP 服务，返回地址和收到的邮件。
+// 设置了 auth 时声明支持 AUTH PLAIN。
+func startSMTP(t *testing.T, auth bool) (string, int, <-chan smtpSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan smtpSession, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		var s smtpSession
+		reply("220 localhost ESMTP test")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			cmd := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				if auth {
+					reply("250-localhost")
+					reply("250 AUTH PLAIN")
+				} else {
+					reply("250 localhost")
+				}
+			case strings.HasPrefix(cmd, "AUTH PLAIN"):
+				s.auth = strings.TrimSpace(line[len("AUTH PLAIN"):])
+				reply("235 2.7.0 Authentication successful")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				s.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+				reply("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				s.to = append(s.to, strings.Trim(line[len("RCPT TO:"):], "<> "))
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				s.data = data.String()
+				reply("250 OK")
+			case cmd == "QUIT":
+				reply("221 Bye")
+				received <- s
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	n, _ := strconv.Atoi(port)
+	return host, n, received
+}
+
+func TestEmailNotifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantAuth string
+	}{
+		{name: "无认证"},
+		{name: "PLAIN 认证", username: "pisa", password: "secret", wantAuth: "\x00pisa\x00secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port, received := startSMTP(t, tt.username != "")
+			n := NewEmailNotifier(config.SMTPConfig{
+				Host:     host,
+				Port:     port,
+				Username: tt.username,
+				Password: tt.password,
+				From:     "PisaList <noreply@pisa.test>",
+			})
+
+			err := n.Notify(context.Background(), Message{
+				UserID: 1,
+				Email:  "user@pisa.test",
+				Title:  "任务提醒：买牛奶",
+				Body:   "截止时间：2025-01-10 18:00",
+			})
+			if err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+
+			var s smtpSession
+			select {
+			case s = <-received:
+			case <-time.After(5 * time.Second):
+				t.Fatal("SMTP 服务没有收到邮件")
+			}
+
+			if s.from != "noreply@pisa.test" {
+				t.Errorf("MAIL FROM = %q, want noreply@pisa.test", s.from)
+			}
+			if len(s.to) != 1 || s.to[0] != "user@pisa.test" {
+				t.Errorf("RCPT TO = %q, want [user@pisa.test]", s.to)
+			}
+			if tt.wantAuth == "" {
+				if s.auth != "" {
+					t.Errorf("unexpected AUTH %q", s.auth)
+				}
+			} else if got, _ := base64.StdEncoding.DecodeString(s.auth); string(got) != tt.wantAuth {
+				t.Errorf("AUTH PLAIN = %q, want %q", got, tt.wantAuth)
+			}
+
+			msg, err := mail.ReadMessage(strings.NewReader(s.data))
+			if err != nil {
+				t.Fatalf("解析邮件失败: %v\n%s", err, s.data)
+			}
+			subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+			if err != nil || subject != "任务提醒：买牛奶" {
+				t.Errorf("Subject = %q (%v), want 任务提醒：买牛奶", subject, err)
+			}
+			for key, want := range map[string]string{
+				"From":         `"PisaList" <noreply@pisa.test>`,
+				"To":           "user@pisa.test",
+				"Content-Type": "text/plain; charset=UTF-8",
+			} {
+				if got := msg.Header.Get(key); got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+			body, _ := io.ReadAll(msg.Body)
+			if string(body) != "截止时间：2025-01-10 18:00\r\n" {
+				t.Errorf("正文 = %q", body)
+			}
+		})
+	}
+}
+
+func TestEmailNotifierRequiresAddress(t *testing.T) {
+	n := NewEmailNotifier(config.SMTPConfig{Host: "127.0.0.1", Port: 1, From: "noreply@pisa.test"})
+	if err := n.Notify(context.Background(), Message{UserID: 1, Title: "t"}); err == nil {
+		t.Fatal("没有邮箱地址时应返回错误")
+	}
+}
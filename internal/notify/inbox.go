@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/repository"
+)
+
+// InboxNotifier 将通知写入用户的站内收件箱
+type InboxNotifier struct {
+	notifications repository.NotificationRepository
+}
+
+// NewInboxNotifier 创建站内信渠道
+func NewInboxNotifier(notifications repository.NotificationRepository) *InboxNotifier {
+	return &InboxNotifier{notifications: notifications}
+}
+
+func (n *InboxNotifier) Name() string { return ChannelInbox }
+
+func (n *InboxNotifier) Notify(ctx context.Context, msg Message) error {
+	return n.notifications.Create(ctx, &model.Notification{
+		UserID:     msg.UserID,
+		Type:       msg.Type,
+		Title:      msg.Title,
+		Body:       msg.Body,
+		Resource:   msg.Resource,
+		ResourceID: msg.ResourceID,
+	})
+}
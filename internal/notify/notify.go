@@ -0,0 +1,100 @@
+// Package notify 定义通知渠道接口，并提供站内信、邮件和 Webhook 三种实现
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 通知渠道名称
+const (
+	ChannelInbox   = "inbox"
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
+
+// ErrUnknownChannel 渠道不存在或未在配置中启用
+var ErrUnknownChannel = errors.New("通知渠道未启用")
+
+// Message 一条待发送的通知
+type Message struct {
+	// UserID 和 Email 为接收人
+	UserID uint   `json:"user_id"`
+	Email  string `json:"-"`
+	// Type 通知类型，见 model.Notification* 常量
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	// Resource 和 ResourceID 指向通知相关的对象，如 task
+	Resource   string `json:"resource"`
+	ResourceID uint   `json:"resource_id"`
+}
+
+// Notifier 通知渠道
+type Notifier interface {
+	// Name 渠道名称，对应 notify.channels 中的取值
+	Name() string
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Dispatcher 按渠道名称分发通知
+type Dispatcher struct {
+	notifiers map[string]Notifier
+	defaults  []string
+}
+
+// NewDispatcher 创建分发器，defaults 为未指定渠道时使用的默认渠道
+func NewDispatcher(defaults []string, notifiers ...Notifier) *Dispatcher {
+	d := &Dispatcher{notifiers: make(map[string]Notifier, len(notifiers)), defaults: defaults}
+	for _, n := range notifiers {
+		d.notifiers[n.Name()] = n
+	}
+	return d
+}
+
+// Defaults 返回默认渠道
+func (d *Dispatcher) Defaults() []string {
+	return d.defaults
+}
+
+// ParseChannels 解析逗号分隔的渠道列表，并检查每个渠道是否已启用
+func (d *Dispatcher) ParseChannels(s string) ([]string, error) {
+	var channels []string
+	for _, ch := range strings.Split(s, ",") {
+		ch = strings.TrimSpace(ch)
+		if ch == "" {
+			continue
+		}
+		if _, ok := d.notifiers[ch]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownChannel, ch)
+		}
+		channels = append(channels, ch)
+	}
+	return channels, nil
+}
+
+// Send 通过 channels 发送通知，channels 为空时使用默认渠道。
+// 返回发送成功的渠道，部分渠道失败时同时返回合并后的错误，调用方可以只重试失败的渠道。
+func (d *Dispatcher) Send(ctx context.Context, channels []string, msg Message) ([]string, error) {
+	if len(channels) == 0 {
+		channels = d.defaults
+	}
+
+	var delivered []string
+	var errs []error
+	for _, ch := range channels {
+		n, ok := d.notifiers[ch]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrUnknownChannel, ch))
+			continue
+		}
+		if err := n.Notify(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ch, err))
+			continue
+		}
+		delivered = append(delivered, ch)
+	}
+	return delivered, errors.Join(errs...)
+}
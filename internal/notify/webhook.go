@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/PisaListBE/pkg/config"
+)
+
+// SignatureHeader Webhook 请求体的 HMAC-SHA256 签名头
+const SignatureHeader = "X-Pisa-Signature"
+
+// WebhookNotifier 以 JSON POST 的方式将通知发送到配置的 URL
+type WebhookNotifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewWebhookNotifier 创建 Webhook 渠道
+func NewWebhookNotifier(cfg config.WebhookConfig) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    cfg.URL,
+		secret: cfg.Secret,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (n *WebhookNotifier) Name() string { return ChannelWebhook }
+
+func (n *WebhookNotifier) Notify(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.secret != "" {
+		mac := hmac.New(sha256.New, []byte(n.secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
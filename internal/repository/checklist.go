@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/PisaListBE/internal/model"
+	"gorm.io/gorm"
+)
+
+// ChecklistRepository 任务清单项数据访问接口
+type ChecklistRepository interface {
+	Create(ctx context.Context, item *model.ChecklistItem) error
+	// FindByID 查询任务下的清单项，不存在时返回 ErrNotFound
+	FindByID(ctx context.Context, taskID, id uint) (*model.ChecklistItem, error)
+	// ListByTask 返回任务的清单项，按 position、ID 升序
+	ListByTask(ctx context.Context, taskID uint) ([]model.ChecklistItem, error)
+	Update(ctx context.Context, item *model.ChecklistItem, updates map[string]interface{}) error
+	Delete(ctx context.Context, item *model.ChecklistItem) error
+	// MaxPosition 返回任务清单项的最大 position，没有清单项时为 0
+	MaxPosition(ctx context.Context, taskID uint) (int, error)
+}
+
+type checklistRepository struct {
+	db *gorm.DB
+}
+
+// NewChecklistRepository 创建基于 GORM 的清单项仓储
+func NewChecklistRepository(db *gorm.DB) ChecklistRepository {
+	return &checklistRepository{db: db}
+}
+
+func (r *checklistRepository) Create(ctx context.Context, item *model.ChecklistItem) error {
+	return conn(ctx, r.db).Create(item).Error
+}
+
+func (r *checklistRepository) FindByID(ctx context.Context, taskID, id uint) (*model.ChecklistItem, error) {
+	var item model.ChecklistItem
+	if err := conn(ctx, r.db).Where("id = ? AND task_id = ?", id, taskID).First(&item).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &item, nil
+}
+
+func (r *checklistRepository) ListByTask(ctx context.Context, taskID uint) ([]model.ChecklistItem, error) {
+	var items []model.ChecklistItem
+	err := conn(ctx, r.db).Where("task_id = ?", taskID).Order("position asc, id asc").Find(&items).Error
+	return items, err
+}
+
+func (r *checklistRepository) Update(ctx context.Context, item *model.ChecklistItem, updates map[string]interface{}) error {
+	return conn(ctx, r.db).Model(item).Updates(updates).Error
+}
+
+func (r *checklistRepository) Delete(ctx context.Context, item *model.ChecklistItem) error {
+	return conn(ctx, r.db).Delete(item).Error
+}
+
+func (r *checklistRepository) MaxPosition(ctx context.Context, taskID uint) (int, error) {
+	var max *int
+	err := conn(ctx, r.db).Model(&model.ChecklistItem{}).
+		Where("task_id = ?", taskID).
+		Select("MAX(position)").
+		Scan(&max).Error
+	if err != nil || max == nil {
+		return 0, err
+	}
+	return *max, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/PisaListBE/internal/model"
+	"gorm.io/gorm"
+)
+
+// CompletionRepository 任务完成记录数据访问接口
+type CompletionRepository interface {
+	Create(ctx context.Context, completion *model.TaskCompletion) error
+	// Delete 删除任务某一天的完成记录
+	Delete(ctx context.Context, taskID uint, date string) error
+	// DeleteByTask 删除任务的全部完成记录
+	DeleteByTask(ctx context.Context, taskID uint) error
+	// ListByTask 返回任务在 [from, to] 日期范围内的完成记录，按日期升序
+	ListByTask(ctx context.Context, taskID uint, from, to string) ([]model.TaskCompletion, error)
+	// ListByTasks 返回多个任务的全部完成记录
+	ListByTasks(ctx context.Context, taskIDs []uint) ([]model.TaskCompletion, error)
+	// ListByUser 按条件分页返回用户未删除任务的完成记录，按日期、ID 倒序
+	ListByUser(ctx context.Context, userID uint, filter CompletionFilter) ([]model.TaskCompletion, error)
+	// CountByDay 返回用户未删除任务在 [from, to] 日期范围内每天的完成数量，按日期倒序
+	CountByDay(ctx context.Context, userID uint, from, to string) ([]DayCount, error)
+}
+
+// CompletionFilter 完成记录查询条件，日期格式为 YYYY-MM-DD 且包含两端。
+// AfterDate/AfterID 为上一页最后一条记录的位置，为空时从第一页开始。
+type CompletionFilter struct {
+	From      string
+	To        string
+	AfterDate string
+	AfterID   uint
+	Limit     int
+}
+
+// DayCount 某一天的完成数量
+type DayCount struct {
+	Date  string
+	Count int64
+}
+
+type completionRepository struct {
+	db *gorm.DB
+}
+
+// NewCompletionRepository 创建基于 GORM 的完成记录仓储
+func NewCompletionRepository(db *gorm.DB) CompletionRepository {
+	return &completionRepository{db: db}
+}
+
+func (r *completionRepository) Create(ctx context.Context, completion *model.TaskCompletion) error {
+	return conn(ctx, r.db).Create(completion).Error
+}
+
+func (r *completionRepository) Delete(ctx context.Context, taskID uint, date string) error {
+	return conn(ctx, r.db).
+		Where("task_id = ? AND occurrence_date = ?", taskID, date).
+		Delete(&model.TaskCompletion{}).Error
+}
+
+func (r *completionRepository) DeleteByTask(ctx context.Context, taskID uint) error {
+	return conn(ctx, r.db).Where("task_id = ?", taskID).Delete(&model.TaskCompletion{}).Error
+}
+
+func (r *completionRepository) ListByTask(ctx context.Context, taskID uint, from, to string) ([]model.TaskCompletion, error) {
+	var completions []model.TaskCompletion
+	err := conn(ctx, r.db).
+		Where("task_id = ? AND occurrence_date >= ? AND occurrence_date <= ?", taskID, from, to).
+		Order("occurrence_date asc").
+		Find(&completions).Error
+	return completions, err
+}
+
+func (r *completionRepository) ListByTasks(ctx context.Context, taskIDs []uint) ([]model.TaskCompletion, error) {
+	var completions []model.TaskCompletion
+	if len(taskIDs) == 0 {
+		return completions, nil
+	}
+	err := conn(ctx, r.db).Where("task_id IN ?", taskIDs).Find(&completions).Error
+	return completions, err
+}
+
+// userCompletions 返回用户未删除任务的完成记录查询，已进入回收站的任务不计入时间线
+func (r *completionRepository) userCompletions(ctx context.Context, userID uint) *gorm.DB {
+	return conn(ctx, r.db).Model(&model.TaskCompletion{}).
+		Joins("JOIN tasks ON tasks.id = task_completions.task_id AND tasks.deleted_at IS NULL").
+		Where("task_completions.user_id = ?", userID)
+}
+
+func (r *completionRepository) ListByUser(ctx context.Context, userID uint, filter CompletionFilter) ([]model.TaskCompletion, error) {
+	q := r.userCompletions(ctx, userID).
+		Where("task_completions.occurrence_date >= ? AND task_completions.occurrence_date <= ?", filter.From, filter.To)
+	if filter.AfterDate != "" {
+		q = q.Where(
+			"(task_completions.occurrence_date < ? OR (task_completions.occurrence_date = ? AND task_completions.id < ?))",
+			filter.AfterDate, filter.AfterDate, filter.AfterID,
+		)
+	}
+
+	var completions []model.TaskCompletion
+	err := q.Select("task_completions.*").
+		Order("task_completions.occurrence_date desc, task_completions.id desc").
+		Limit(filter.Limit).
+		Find(&completions).Error
+	return completions, err
+}
+
+func (r *completionRepository) CountByDay(ctx context.Context, userID uint, from, to string) ([]DayCount, error) {
+	var counts []DayCount
+	err := r.userCompletions(ctx, userID).
+		Where("task_completions.occurrence_date >= ? AND task_completions.occurrence_date <= ?", from, to).
+		Select("task_completions.occurrence_date AS date, COUNT(*) AS count").
+		Group("task_completions.occurrence_date").
+		Order("task_completions.occurrence_date desc").
+		Scan(&counts).Error
+	return counts, err
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"gorm.io/gorm"
+)
+
+// ListRepository 任务清单数据访问接口
+type ListRepository interface {
+	Create(ctx context.Context, list *model.List) error
+	// FindByID 查询属于 userID 的清单，不存在时返回 ErrNotFound
+	FindByID(ctx context.Context, userID, id uint) (*model.List, error)
+	// ListByUser 返回用户的清单，按 sort_order、ID 升序；archived 为 false 时不包含已归档的清单
+	ListByUser(ctx context.Context, userID uint, archived bool) ([]model.List, error)
+	Update(ctx context.Context, list *model.List, updates map[string]interface{}) error
+	Delete(ctx context.Context, list *model.List) error
+	// MaxSortOrder 返回用户清单的最大 sort_order，没有清单时为 0
+	MaxSortOrder(ctx context.Context, userID uint) (int, error)
+	// PurgeDeletedBefore 彻底删除 before 之前删除的清单，仍指向这些清单的任务移出清单，返回删除数量
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+	// FindByClientID 按客户端ID查询属于 userID 的清单，包括已删除的，不存在时返回 ErrNotFound
+	FindByClientID(ctx context.Context, userID uint, clientID string) (*model.List, error)
+	// ListChangedSince 返回 since 之后创建、修改或删除的清单，按ID升序，已删除的清单也会返回；
+	// since 为零值时返回全部未删除的清单
+	ListChangedSince(ctx context.Context, userID uint, since time.Time) ([]model.List, error)
+}
+
+type listRepository struct {
+	db *gorm.DB
+}
+
+// NewListRepository 创建基于 GORM 的清单仓储
+func NewListRepository(db *gorm.DB) ListRepository {
+	return &listRepository{db: db}
+}
+
+func (r *listRepository) Create(ctx context.Context, list *model.List) error {
+	return conn(ctx, r.db).Create(list).Error
+}
+
+func (r *listRepository) FindByID(ctx context.Context, userID, id uint) (*model.List, error) {
+	var list model.List
+	if err := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).First(&list).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &list, nil
+}
+
+func (r *listRepository) ListByUser(ctx context.Context, userID uint, archived bool) ([]model.List, error) {
+	var lists []model.List
+	db := conn(ctx, r.db).Where("user_id = ?", userID)
+	if !archived {
+		db = db.Where("archived = ?", false)
+	}
+	err := db.Order("sort_order asc, id asc").Find(&lists).Error
+	return lists, err
+}
+
+func (r *listRepository) Update(ctx context.Context, list *model.List, updates map[string]interface{}) error {
+	return conn(ctx, r.db).Model(list).Updates(updates).Error
+}
+
+func (r *listRepository) Delete(ctx context.Context, list *model.List) error {
+	return conn(ctx, r.db).Delete(list).Error
+}
+
+func (r *listRepository) MaxSortOrder(ctx context.Context, userID uint) (int, error) {
+	var max *int
+	err := conn(ctx, r.db).Model(&model.List{}).
+		Where("user_id = ?", userID).
+		Select("MAX(sort_order)").
+		Scan(&max).Error
+	if err != nil || max == nil {
+		return 0, err
+	}
+	return *max, nil
+}
+
+func (r *listRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().Model(&model.List{}).
+			Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+
+		err := tx.Unscoped().Model(&model.Task{}).
+			Where("list_id IN (?)", expired).
+			Updates(map[string]interface{}{"list_id": nil, "version": nextVersion()}).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Delete(&model.List{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
+func (r *listRepository) FindByClientID(ctx context.Context, userID uint, clientID string) (*model.List, error) {
+	var list model.List
+	err := conn(ctx, r.db).Unscoped().Where("user_id = ? AND client_id = ?", userID, clientID).First(&list).Error
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &list, nil
+}
+
+func (r *listRepository) ListChangedSince(ctx context.Context, userID uint, since time.Time) ([]model.List, error) {
+	var lists []model.List
+	err := changedSince(conn(ctx, r.db), since).Where("user_id = ?", userID).Order("id asc").Find(&lists).Error
+	return lists, err
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepository 站内通知数据访问接口
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *model.Notification) error
+	// CreateOnce 按 (user_id, dedup_key) 去重写入通知，已存在时返回 false
+	CreateOnce(ctx context.Context, notification *model.Notification) (bool, error)
+	// List 按 ID 倒序返回用户的通知，beforeID 不为 0 时只返回更早的通知
+	List(ctx context.Context, userID uint, unreadOnly bool, beforeID uint, limit int) ([]model.Notification, error)
+	// MarkRead 将通知标记为已读，不存在时返回 ErrNotFound
+	MarkRead(ctx context.Context, userID, id uint, at time.Time) error
+	// MarkAllRead 将用户所有未读通知标记为已读，返回更新数量
+	MarkAllRead(ctx context.Context, userID uint, at time.Time) (int64, error)
+	// Delete 删除通知，不存在时返回 ErrNotFound
+	Delete(ctx context.Context, userID, id uint) error
+	CountUnread(ctx context.Context, userID uint) (int64, error)
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository 创建基于 GORM 的站内通知仓储
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) Create(ctx context.Context, notification *model.Notification) error {
+	return conn(ctx, r.db).Create(notification).Error
+}
+
+func (r *notificationRepository) CreateOnce(ctx context.Context, notification *model.Notification) (bool, error) {
+	// 先查询一次，避免周期任务反复插入冲突行消耗自增ID；并发写入由唯一索引兜底
+	var count int64
+	err := conn(ctx, r.db).Model(&model.Notification{}).
+		Where("user_id = ? AND dedup_key = ?", notification.UserID, notification.DedupKey).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return false, err
+	}
+
+	result := conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(notification)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *notificationRepository) List(ctx context.Context, userID uint, unreadOnly bool, beforeID uint, limit int) ([]model.Notification, error) {
+	q := conn(ctx, r.db).Where("user_id = ?", userID)
+	if unreadOnly {
+		q = q.Where("read_at IS NULL")
+	}
+	if beforeID > 0 {
+		q = q.Where("id < ?", beforeID)
+	}
+
+	var notifications []model.Notification
+	err := q.Order("id desc").Limit(limit).Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, userID, id uint, at time.Time) error {
+	var notification model.Notification
+	if err := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		return translate(err)
+	}
+	if notification.ReadAt != nil {
+		return nil
+	}
+	return conn(ctx, r.db).Model(&notification).Update("read_at", at).Error
+}
+
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID uint, at time.Time) (int64, error) {
+	result := conn(ctx, r.db).Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", at)
+	return result.RowsAffected, result.Error
+}
+
+func (r *notificationRepository) Delete(ctx context.Context, userID, id uint) error {
+	result := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).Delete(&model.Notification{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
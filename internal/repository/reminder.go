@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"gorm.io/gorm"
+)
+
+// ReminderRepository 任务提醒数据访问接口
+type ReminderRepository interface {
+	Create(ctx context.Context, reminder *model.Reminder) error
+	// FindByID 查询用户的提醒，不存在时返回 ErrNotFound
+	FindByID(ctx context.Context, userID, id uint) (*model.Reminder, error)
+	// ListByTask 返回任务的全部提醒，按创建顺序
+	ListByTask(ctx context.Context, userID, taskID uint) ([]model.Reminder, error)
+	Update(ctx context.Context, reminder *model.Reminder, updates map[string]interface{}) error
+	Delete(ctx context.Context, reminder *model.Reminder) error
+	// ListDue 返回 fire_at 不晚于 now、尚未发送且尝试次数小于 maxAttempts 的提醒。
+	// 已删除任务和已完成的非循环任务的提醒不返回，任务恢复或重新打开后照常发送
+	ListDue(ctx context.Context, now time.Time, maxAttempts, limit int) ([]model.Reminder, error)
+	// Claim 领取一条待发送的提醒：尝试次数加一并将 fire_at 推迟到 leaseUntil。
+	// attempts 与数据库中的值不一致（已被其他实例领取）时返回 false。
+	Claim(ctx context.Context, id uint, attempts int, leaseUntil time.Time) (bool, error)
+}
+
+type reminderRepository struct {
+	db *gorm.DB
+}
+
+// NewReminderRepository 创建基于 GORM 的提醒仓储
+func NewReminderRepository(db *gorm.DB) ReminderRepository {
+	return &reminderRepository{db: db}
+}
+
+func (r *reminderRepository) Create(ctx context.Context, reminder *model.Reminder) error {
+	return conn(ctx, r.db).Create(reminder).Error
+}
+
+func (r *reminderRepository) FindByID(ctx context.Context, userID, id uint) (*model.Reminder, error) {
+	var reminder model.Reminder
+	if err := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).First(&reminder).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &reminder, nil
+}
+
+func (r *reminderRepository) ListByTask(ctx context.Context, userID, taskID uint) ([]model.Reminder, error) {
+	var reminders []model.Reminder
+	err := conn(ctx, r.db).
+		Where("task_id = ? AND user_id = ?", taskID, userID).
+		Order("id asc").
+		Find(&reminders).Error
+	return reminders, err
+}
+
+func (r *reminderRepository) Update(ctx context.Context, reminder *model.Reminder, updates map[string]interface{}) error {
+	return conn(ctx, r.db).Model(reminder).Updates(updates).Error
+}
+
+func (r *reminderRepository) Delete(ctx context.Context, reminder *model.Reminder) error {
+	return conn(ctx, r.db).Delete(reminder).Error
+}
+
+func (r *reminderRepository) ListDue(ctx context.Context, now time.Time, maxAttempts, limit int) ([]model.Reminder, error) {
+	var reminders []model.Reminder
+	err := conn(ctx, r.db).
+		Joins("JOIN tasks ON tasks.id = reminders.task_id AND tasks.deleted_at IS NULL AND (tasks.completed = ? OR tasks.recurrence <> ?)", false, "").
+		Where("reminders.sent_at IS NULL AND reminders.fire_at <= ? AND reminders.attempts < ?", now, maxAttempts).
+		Order("reminders.fire_at asc").
+		Limit(limit).
+		Find(&reminders).Error
+	return reminders, err
+}
+
+func (r *reminderRepository) Claim(ctx context.Context, id uint, attempts int, leaseUntil time.Time) (bool, error) {
+	result := conn(ctx, r.db).Model(&model.Reminder{}).
+		Where("id = ? AND attempts = ? AND sent_at IS NULL", id, attempts).
+		Updates(map[string]interface{}{
+			"attempts": attempts + 1,
+			"fire_at":  leaseUntil,
+		})
+	return result.RowsAffected == 1, result.Error
+}
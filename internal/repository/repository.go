@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNotFound 记录不存在或不属于当前用户
+var ErrNotFound = errors.New("record not found")
+
+// ErrConflict 记录在读取之后已被其他请求修改，版本号不一致
+var ErrConflict = errors.New("record modified concurrently")
+
+// nextVersion 版本号加一的更新表达式
+func nextVersion() clause.Expr {
+	return gorm.Expr("version + 1")
+}
+
+// updateVersioned 仅在记录的版本号仍为 *version 时执行更新，同时把版本号加一并写回 *version。
+// 版本号已变化时返回 ErrConflict
+func updateVersioned(db *gorm.DB, value interface{}, version *uint, updates map[string]interface{}) error {
+	values := make(map[string]interface{}, len(updates)+1)
+	for k, v := range updates {
+		values[k] = v
+	}
+	values["version"] = nextVersion()
+
+	result := db.Model(value).Where("version = ?", *version).Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrConflict
+	}
+	*version++
+	return nil
+}
+
+// changedSince 筛选 since 之后创建、修改或删除的记录，软删除的记录也会返回，作为同步时的删除标记。
+// 软删除不会更新 updated_at，因此同时比较 deleted_at。since 为零值时返回全部未删除的记录
+func changedSince(db *gorm.DB, since time.Time) *gorm.DB {
+	if since.IsZero() {
+		return db
+	}
+	return db.Unscoped().Where("(updated_at >= ? OR deleted_at >= ?)", since, since)
+}
+
+// translate 将 gorm 的错误转换为仓储层错误
+func translate(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"gorm.io/gorm"
+)
+
+// TagRepository 标签数据访问接口
+type TagRepository interface {
+	Create(ctx context.Context, tag *model.Tag) error
+	// FindByID 查询属于 userID 的标签，不存在时返回 ErrNotFound
+	FindByID(ctx context.Context, userID, id uint) (*model.Tag, error)
+	// FindByName 按名称查询属于 userID 的标签，不存在时返回 ErrNotFound
+	FindByName(ctx context.Context, userID uint, name string) (*model.Tag, error)
+	// FindByIDs 批量查询属于 userID 的标签，已删除或不存在的标签会被忽略
+	FindByIDs(ctx context.Context, userID uint, ids []uint) ([]model.Tag, error)
+	// ListByUser 返回用户的所有标签，按名称排序
+	ListByUser(ctx context.Context, userID uint) ([]model.Tag, error)
+	Update(ctx context.Context, tag *model.Tag, updates map[string]interface{}) error
+	// Delete 删除标签，并从所有任务和心愿上移除
+	Delete(ctx context.Context, tag *model.Tag) error
+	// PurgeDeletedBefore 彻底删除 before 之前删除的标签，返回删除数量
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+	// FindByClientID 按客户端ID查询属于 userID 的标签，包括已删除的，不存在时返回 ErrNotFound
+	FindByClientID(ctx context.Context, userID uint, clientID string) (*model.Tag, error)
+	// ListChangedSince 返回 since 之后创建、修改或删除的标签，按ID升序，已删除的标签也会返回；
+	// since 为零值时返回全部未删除的标签
+	ListChangedSince(ctx context.Context, userID uint, since time.Time) ([]model.Tag, error)
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+// NewTagRepository 创建基于 GORM 的标签仓储
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+// withTags 预加载任务或心愿的标签，按名称排序
+func withTags(db *gorm.DB) *gorm.DB {
+	return db.Preload("Tags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name asc")
+	})
+}
+
+func (r *tagRepository) Create(ctx context.Context, tag *model.Tag) error {
+	return conn(ctx, r.db).Create(tag).Error
+}
+
+func (r *tagRepository) FindByID(ctx context.Context, userID, id uint) (*model.Tag, error) {
+	var tag model.Tag
+	if err := conn(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).First(&tag).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) FindByName(ctx context.Context, userID uint, name string) (*model.Tag, error) {
+	var tag model.Tag
+	if err := conn(ctx, r.db).Where("user_id = ? AND name = ?", userID, name).First(&tag).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) FindByIDs(ctx context.Context, userID uint, ids []uint) ([]model.Tag, error) {
+	var tags []model.Tag
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	err := conn(ctx, r.db).Where("user_id = ? AND id IN ?", userID, ids).Order("name asc").Find(&tags).Error
+	return tags, err
+}
+
+func (r *tagRepository) ListByUser(ctx context.Context, userID uint) ([]model.Tag, error) {
+	var tags []model.Tag
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Order("name asc").Find(&tags).Error
+	return tags, err
+}
+
+func (r *tagRepository) Update(ctx context.Context, tag *model.Tag, updates map[string]interface{}) error {
+	return conn(ctx, r.db).Model(tag).Updates(updates).Error
+}
+
+func (r *tagRepository) Delete(ctx context.Context, tag *model.Tag) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, table := range []string{"task_tags", "wish_tags"} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE tag_id = ?", tag.ID).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(tag).Error
+	})
+}
+
+func (r *tagRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(&model.Tag{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *tagRepository) FindByClientID(ctx context.Context, userID uint, clientID string) (*model.Tag, error) {
+	var tag model.Tag
+	err := conn(ctx, r.db).Unscoped().Where("user_id = ? AND client_id = ?", userID, clientID).First(&tag).Error
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) ListChangedSince(ctx context.Context, userID uint, since time.Time) ([]model.Tag, error) {
+	var tags []model.Tag
+	err := changedSince(conn(ctx, r.db), since).Where("user_id = ?", userID).Order("id asc").Find(&tags).Error
+	return tags, err
+}
@@ -0,0 +1,351 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"gorm.io/gorm"
+)
+
+// TaskRepository 任务数据访问接口
+type TaskRepository interface {
+	Create(ctx context.Context, task *model.Task) error
+	// FindByID 查询属于 userID 的任务，不存在时返回 ErrNotFound
+	FindByID(ctx context.Context, userID, id uint) (*model.Task, error)
+	Update(ctx context.Context, task *model.Task, updates map[string]interface{}) error
+	// SetTags 将任务的标签替换为 tags
+	SetTags(ctx context.Context, task *model.Task, tags []model.Tag) error
+	// Delete 将任务及其所有子任务一起移入回收站
+	Delete(ctx context.Context, task *model.Task) error
+	// MoveList 将清单 from 中的所有任务（包括回收站中的）移到清单 to，to 为 nil 时移出清单
+	MoveList(ctx context.Context, userID, from uint, to *uint) error
+	// DeleteByList 将清单中的任务及其所有子任务一起移入回收站
+	DeleteByList(ctx context.Context, userID, listID uint) error
+	// ListChildren 返回任务的直接子任务，按ID升序
+	ListChildren(ctx context.Context, userID, parentID uint) ([]model.Task, error)
+	// FindByIDs 批量查询属于 userID 的任务，已删除或不存在的任务会被忽略
+	FindByIDs(ctx context.Context, userID uint, ids []uint) ([]model.Task, error)
+	// ListByUser 返回用户的所有任务，按 position 排序
+	ListByUser(ctx context.Context, userID uint) ([]model.Task, error)
+	// Query 按 q 筛选、排序并分页返回任务
+	Query(ctx context.Context, userID uint, q TaskQuery) ([]model.Task, error)
+	// Count 返回满足 q 中筛选条件的任务数量，忽略排序和分页
+	Count(ctx context.Context, userID uint, q TaskQuery) (int64, error)
+	// ListRecurring 返回用户的所有循环任务
+	ListRecurring(ctx context.Context, userID uint) ([]model.Task, error)
+	// ListForDay 返回已开始且未完成、在 [dayStart, dayEnd) 内完成或循环的任务，按 position 排序
+	ListForDay(ctx context.Context, userID uint, dayStart, dayEnd time.Time) ([]model.Task, error)
+	// ScanRecurring 按 ID 升序分批返回时区为 q.Timezone 的用户已开始、当天尚未发送通知的循环任务
+	ScanRecurring(ctx context.Context, q RecurringScan) ([]model.Task, error)
+	// ListDueBetween 返回截止时间在 [from, to) 内的未完成任务，按截止时间升序
+	ListDueBetween(ctx context.Context, userID uint, from, to time.Time) ([]model.Task, error)
+	// ListOverdue 返回截止时间早于 now 的未完成任务，按截止时间升序
+	ListOverdue(ctx context.Context, userID uint, now time.Time) ([]model.Task, error)
+	// FindDeleted 查询属于 userID 且在回收站中的任务，不存在时返回 ErrNotFound
+	FindDeleted(ctx context.Context, userID, id uint) (*model.Task, error)
+	// ListDeleted 返回回收站中的任务，按删除时间倒序
+	ListDeleted(ctx context.Context, userID uint) ([]model.Task, error)
+	// Restore 恢复回收站中的任务以及与它一起删除的子任务，不在回收站时返回 ErrNotFound，
+	// version 不为 nil 且与任务的当前版本不一致时返回 ErrConflict
+	Restore(ctx context.Context, userID, id uint, version *uint) error
+	// PurgeDeletedBefore 彻底删除 before 之前进入回收站的任务及其完成记录、提醒、清单项和标签关联，返回删除的任务数量
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+	// MaxPosition 返回用户任务中最大的 position，没有任务时为空
+	MaxPosition(ctx context.Context, userID uint) (string, error)
+	// NeighborPosition 返回除 excludeID 外紧挨着 position 的任务的 position：next 为 true 时取后一个，
+	// 否则取前一个，不存在时为空
+	NeighborPosition(ctx context.Context, userID, excludeID uint, position string, next bool) (string, error)
+	// FindByClientID 按客户端ID查询属于 userID 的任务，包括已删除的，不存在时返回 ErrNotFound
+	FindByClientID(ctx context.Context, userID uint, clientID string) (*model.Task, error)
+	// ListChangedSince 返回 since 之后创建、修改或删除的任务，按ID升序，已删除的任务也会返回；
+	// since 为零值时返回全部未删除的任务
+	ListChangedSince(ctx context.Context, userID uint, since time.Time) ([]model.Task, error)
+}
+
+type taskRepository struct {
+	db *gorm.DB
+}
+
+// NewTaskRepository 创建基于 GORM 的任务仓储
+func NewTaskRepository(db *gorm.DB) TaskRepository {
+	return &taskRepository{db: db}
+}
+
+func (r *taskRepository) Create(ctx context.Context, task *model.Task) error {
+	return conn(ctx, r.db).Create(task).Error
+}
+
+func (r *taskRepository) FindByID(ctx context.Context, userID, id uint) (*model.Task, error) {
+	var task model.Task
+	if err := conn(ctx, r.db).Scopes(withTags).Where("id = ? AND user_id = ?", id, userID).First(&task).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &task, nil
+}
+
+func (r *taskRepository) Update(ctx context.Context, task *model.Task, updates map[string]interface{}) error {
+	return updateVersioned(conn(ctx, r.db), task, &task.Version, updates)
+}
+
+func (r *taskRepository) SetTags(ctx context.Context, task *model.Task, tags []model.Tag) error {
+	return conn(ctx, r.db).Model(task).Association("Tags").Replace(tags)
+}
+
+func (r *taskRepository) Delete(ctx context.Context, task *model.Task) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// 先按版本号更新一次，读取之后任务被修改过时放弃删除
+		if err := updateVersioned(tx, task, &task.Version, nil); err != nil {
+			return err
+		}
+		return deleteTrees(tx, task.UserID, []uint{task.ID})
+	})
+}
+
+func (r *taskRepository) MoveList(ctx context.Context, userID, from uint, to *uint) error {
+	return conn(ctx, r.db).Unscoped().Model(&model.Task{}).
+		Where("user_id = ? AND list_id = ?", userID, from).
+		Updates(map[string]interface{}{"list_id": to, "version": nextVersion()}).Error
+}
+
+func (r *taskRepository) DeleteByList(ctx context.Context, userID, listID uint) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		err := tx.Model(&model.Task{}).Where("user_id = ? AND list_id = ?", userID, listID).Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		return deleteTrees(tx, userID, ids)
+	})
+}
+
+// deleteTrees 将 roots 及其所有子任务移入回收站
+func deleteTrees(tx *gorm.DB, userID uint, roots []uint) error {
+	ids := roots
+	for frontier := ids; len(frontier) > 0; {
+		var children []uint
+		err := tx.Model(&model.Task{}).
+			Where("user_id = ? AND parent_id IN ?", userID, frontier).
+			Pluck("id", &children).Error
+		if err != nil {
+			return err
+		}
+		ids = append(ids, children...)
+		frontier = children
+	}
+	// 用一条语句删除，整棵子树的 deleted_at 相同，恢复时据此找回一起删除的子任务
+	return tx.Where("id IN ?", ids).Delete(&model.Task{}).Error
+}
+
+func (r *taskRepository) ListChildren(ctx context.Context, userID, parentID uint) ([]model.Task, error) {
+	var tasks []model.Task
+	err := conn(ctx, r.db).Scopes(withTags).Where("user_id = ? AND parent_id = ?", userID, parentID).Order("id asc").Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepository) FindByIDs(ctx context.Context, userID uint, ids []uint) ([]model.Task, error) {
+	var tasks []model.Task
+	if len(ids) == 0 {
+		return tasks, nil
+	}
+	err := conn(ctx, r.db).Scopes(withTags).Where("user_id = ? AND id IN ?", userID, ids).Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepository) ListByUser(ctx context.Context, userID uint) ([]model.Task, error) {
+	var tasks []model.Task
+	err := conn(ctx, r.db).Scopes(withTags).Where("user_id = ?", userID).Order("position asc, id asc").Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepository) ListRecurring(ctx context.Context, userID uint) ([]model.Task, error) {
+	var tasks []model.Task
+	err := conn(ctx, r.db).Where("user_id = ? AND recurrence <> ?", userID, "").Order("id asc").Find(&tasks).Error
+	return tasks, err
+}
+
+// RecurringScan 分批扫描循环任务的条件
+type RecurringScan struct {
+	// Timezone 只返回时区设置为该值的用户的任务，空字符串表示未设置时区的用户
+	Timezone string
+	// Now 排除开始时间晚于 Now 的任务
+	Now time.Time
+	// NotifiedSince 排除此时间之后已写入当天循环任务通知的任务
+	NotifiedSince time.Time
+	// AfterID 上一批最后一个任务的ID
+	AfterID uint
+	Limit   int
+}
+
+func (r *taskRepository) ScanRecurring(ctx context.Context, q RecurringScan) ([]model.Task, error) {
+	db := conn(ctx, r.db)
+	users := db.Model(&model.User{}).Select("id").Where("timezone = ?", q.Timezone)
+
+	var tasks []model.Task
+	err := db.
+		Where("tasks.id > ? AND tasks.recurrence <> ?", q.AfterID, "").
+		Where("tasks.user_id IN (?)", users).
+		Where("tasks.start_at IS NULL OR tasks.start_at <= ?", q.Now).
+		Where(`NOT EXISTS (SELECT 1 FROM notifications WHERE notifications.user_id = tasks.user_id
+			AND notifications.resource_id = tasks.id AND notifications.type = ? AND notifications.created_at >= ?)`,
+			model.NotificationTaskDue, q.NotifiedSince).
+		Order("tasks.id asc").
+		Limit(q.Limit).
+		Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepository) ListForDay(ctx context.Context, userID uint, dayStart, dayEnd time.Time) ([]model.Task, error) {
+	// 用区间比较代替 DATE()，兼容所有数据库驱动
+	var tasks []model.Task
+	err := conn(ctx, r.db).Scopes(withTags).Where(
+		"user_id = ? AND (start_at IS NULL OR start_at < ?) AND (completed = ? OR (completed = ? AND completed_date >= ? AND completed_date < ?) OR (completed = ? AND is_cycle = ?))",
+		userID,
+		dayEnd,
+		false,
+		true, dayStart, dayEnd,
+		true, true,
+	).Order("position asc, id asc").Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepository) ListDueBetween(ctx context.Context, userID uint, from, to time.Time) ([]model.Task, error) {
+	var tasks []model.Task
+	err := conn(ctx, r.db).Scopes(withTags).
+		Where("user_id = ? AND completed = ? AND due_at >= ? AND due_at < ?", userID, false, from, to).
+		Order("due_at asc").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepository) ListOverdue(ctx context.Context, userID uint, now time.Time) ([]model.Task, error) {
+	var tasks []model.Task
+	err := conn(ctx, r.db).Scopes(withTags).
+		Where("user_id = ? AND completed = ? AND due_at < ?", userID, false, now).
+		Order("due_at asc").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepository) FindDeleted(ctx context.Context, userID, id uint) (*model.Task, error) {
+	var task model.Task
+	err := conn(ctx, r.db).Unscoped().Scopes(withTags).
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+		First(&task).Error
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &task, nil
+}
+
+func (r *taskRepository) ListDeleted(ctx context.Context, userID uint) ([]model.Task, error) {
+	var tasks []model.Task
+	err := conn(ctx, r.db).Unscoped().Scopes(withTags).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at desc").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepository) Restore(ctx context.Context, userID, id uint, version *uint) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var root model.Task
+		err := tx.Unscoped().Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).First(&root).Error
+		if err != nil {
+			return translate(err)
+		}
+		if version != nil && *version != root.Version {
+			return ErrConflict
+		}
+
+		ids := []uint{root.ID}
+		for frontier := ids; len(frontier) > 0; {
+			var children []model.Task
+			err := tx.Unscoped().
+				Where("user_id = ? AND parent_id IN ? AND deleted_at IS NOT NULL", userID, frontier).
+				Find(&children).Error
+			if err != nil {
+				return err
+			}
+			frontier = nil
+			for _, child := range children {
+				// 之前单独删除的子任务仍留在回收站
+				if child.DeletedAt.Time.Equal(root.DeletedAt.Time) {
+					frontier = append(frontier, child.ID)
+				}
+			}
+			ids = append(ids, frontier...)
+		}
+
+		return tx.Unscoped().Model(&model.Task{}).Where("id IN ?", ids).
+			Updates(map[string]interface{}{"deleted_at": nil, "version": nextVersion()}).Error
+	})
+}
+
+func (r *taskRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().Model(&model.Task{}).
+			Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+
+		for _, related := range []interface{}{&model.TaskCompletion{}, &model.Reminder{}, &model.ChecklistItem{}} {
+			if err := tx.Where("task_id IN (?)", expired).Delete(related).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec("DELETE FROM task_tags WHERE task_id IN (?)", expired).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Delete(&model.Task{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
+func (r *taskRepository) MaxPosition(ctx context.Context, userID uint) (string, error) {
+	var positions []string
+	err := conn(ctx, r.db).Model(&model.Task{}).
+		Where("user_id = ?", userID).
+		Order("position desc").
+		Limit(1).
+		Pluck("position", &positions).Error
+	if err != nil || len(positions) == 0 {
+		return "", err
+	}
+	return positions[0], nil
+}
+
+func (r *taskRepository) NeighborPosition(ctx context.Context, userID, excludeID uint, position string, next bool) (string, error) {
+	db := conn(ctx, r.db).Model(&model.Task{}).Where("user_id = ? AND id <> ?", userID, excludeID)
+	if next {
+		db = db.Where("position > ?", position).Order("position asc")
+	} else {
+		db = db.Where("position < ?", position).Order("position desc")
+	}
+
+	var positions []string
+	if err := db.Limit(1).Pluck("position", &positions).Error; err != nil || len(positions) == 0 {
+		return "", err
+	}
+	return positions[0], nil
+}
+
+func (r *taskRepository) FindByClientID(ctx context.Context, userID uint, clientID string) (*model.Task, error) {
+	var task model.Task
+	err := conn(ctx, r.db).Unscoped().Scopes(withTags).Where("user_id = ? AND client_id = ?", userID, clientID).First(&task).Error
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &task, nil
+}
+
+func (r *taskRepository) ListChangedSince(ctx context.Context, userID uint, since time.Time) ([]model.Task, error) {
+	var tasks []model.Task
+	err := changedSince(conn(ctx, r.db), since).Scopes(withTags).Where("user_id = ?", userID).Order("id asc").Find(&tasks).Error
+	return tasks, err
+}
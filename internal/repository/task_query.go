@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"gorm.io/gorm"
+)
+
+// 任务列表可排序的列
+const (
+	TaskOrderPosition   = "position"
+	TaskOrderImportance = "importance_level"
+	TaskOrderDueAt      = "due_at"
+	TaskOrderCreatedAt  = "created_at"
+)
+
+// TaskQuery 任务列表的筛选、排序和分页条件，为 nil 或零值的筛选条件不生效
+type TaskQuery struct {
+	// Completed 对循环任务表示当前这一次是否已完成，由 CompletedRecurring 给出
+	Completed *bool
+	// CompletedRecurring 当前这一次已完成的循环任务ID，配合 Completed 使用
+	CompletedRecurring []uint
+	IsCycle            *bool
+	// MinImportance/MaxImportance 重要性范围，包含两端
+	MinImportance *int
+	MaxImportance *int
+	// ListID 为 0 时只返回不属于任何清单的任务
+	ListID *uint
+	TagID  *uint
+	// CreatedFrom/CreatedTo 创建时间范围 [from, to)
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// CompletedFrom/CompletedTo 完成时间范围 [from, to)，按完成记录筛选，循环任务任一次完成在范围内即可
+	CompletedFrom *time.Time
+	CompletedTo   *time.Time
+	// Text 在内容和描述中模糊匹配，不区分大小写
+	Text string
+
+	// OrderBy 排序列，为空时按 position；相同时按 ID 排序，没有截止时间的任务总在最后
+	OrderBy string
+	Desc    bool
+	// After 上一页最后一个任务的排序键，为 nil 时从头开始
+	After *TaskKey
+	Limit int
+}
+
+// TaskKey 任务在列表中的排序位置，Value 为排序列的值，截止时间为空时 Value 为 nil
+type TaskKey struct {
+	Value interface{}
+	ID    uint
+}
+
+// likeEscaper 转义 LIKE 的通配符，配合 ESCAPE '!' 使用，各数据库行为一致
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+func (r *taskRepository) filtered(ctx context.Context, userID uint, q TaskQuery) *gorm.DB {
+	db := conn(ctx, r.db).Model(&model.Task{}).Where("tasks.user_id = ?", userID)
+
+	if q.Completed != nil {
+		// 空的 IN 列表在各数据库中的行为不一致，单独处理
+		switch {
+		case *q.Completed && len(q.CompletedRecurring) == 0:
+			db = db.Where("recurrence = ? AND completed = ?", "", true)
+		case *q.Completed:
+			db = db.Where("((recurrence = ? AND completed = ?) OR tasks.id IN ?)", "", true, q.CompletedRecurring)
+		case len(q.CompletedRecurring) == 0:
+			db = db.Where("(recurrence <> ? OR completed = ?)", "", false)
+		default:
+			db = db.Where("((recurrence = ? AND completed = ?) OR (recurrence <> ? AND tasks.id NOT IN ?))", "", false, "", q.CompletedRecurring)
+		}
+	}
+	if q.IsCycle != nil {
+		db = db.Where("is_cycle = ?", *q.IsCycle)
+	}
+	if q.MinImportance != nil {
+		db = db.Where("importance_level >= ?", *q.MinImportance)
+	}
+	if q.MaxImportance != nil {
+		db = db.Where("importance_level <= ?", *q.MaxImportance)
+	}
+	if q.ListID != nil {
+		if *q.ListID == 0 {
+			db = db.Where("list_id IS NULL")
+		} else {
+			db = db.Where("list_id = ?", *q.ListID)
+		}
+	}
+	if q.TagID != nil {
+		db = db.Where("tasks.id IN (?)", conn(ctx, r.db).Table("task_tags").Select("task_id").Where("tag_id = ?", *q.TagID))
+	}
+	if q.CreatedFrom != nil {
+		db = db.Where("tasks.created_at >= ?", *q.CreatedFrom)
+	}
+	if q.CreatedTo != nil {
+		db = db.Where("tasks.created_at < ?", *q.CreatedTo)
+	}
+	if q.CompletedFrom != nil || q.CompletedTo != nil {
+		completions := conn(ctx, r.db).Model(&model.TaskCompletion{}).Select("task_id").Where("user_id = ?", userID)
+		if q.CompletedFrom != nil {
+			completions = completions.Where("completed_at >= ?", *q.CompletedFrom)
+		}
+		if q.CompletedTo != nil {
+			completions = completions.Where("completed_at < ?", *q.CompletedTo)
+		}
+		db = db.Where("tasks.id IN (?)", completions)
+	}
+	if q.Text != "" {
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(q.Text)) + "%"
+		db = db.Where("(LOWER(event) LIKE ? ESCAPE '!' OR LOWER(description) LIKE ? ESCAPE '!')", pattern, pattern)
+	}
+	return db
+}
+
+func (r *taskRepository) Query(ctx context.Context, userID uint, q TaskQuery) ([]model.Task, error) {
+	column := q.OrderBy
+	if column == "" {
+		column = TaskOrderPosition
+	}
+	dir, cmp := "asc", ">"
+	if q.Desc {
+		dir, cmp = "desc", "<"
+	}
+
+	db := r.filtered(ctx, userID, q).Scopes(withTags)
+	if column == TaskOrderDueAt {
+		// 不同数据库对 NULL 的排序不一致，显式把没有截止时间的任务排在最后
+		db = db.Order("CASE WHEN due_at IS NULL THEN 1 ELSE 0 END asc")
+	}
+	db = db.Order(column + " " + dir).Order("tasks.id " + dir)
+
+	if after := q.After; after != nil {
+		switch {
+		case column == TaskOrderDueAt && after.Value == nil:
+			db = db.Where("due_at IS NULL AND tasks.id "+cmp+" ?", after.ID)
+		case column == TaskOrderDueAt:
+			db = db.Where("((due_at IS NOT NULL AND (due_at "+cmp+" ? OR (due_at = ? AND tasks.id "+cmp+" ?))) OR due_at IS NULL)",
+				after.Value, after.Value, after.ID)
+		default:
+			db = db.Where("("+column+" "+cmp+" ? OR ("+column+" = ? AND tasks.id "+cmp+" ?))", after.Value, after.Value, after.ID)
+		}
+	}
+
+	var tasks []model.Task
+	err := db.Limit(q.Limit).Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepository) Count(ctx context.Context, userID uint, q TaskQuery) (int64, error) {
+	var count int64
+	err := r.filtered(ctx, userID, q).Count(&count).Error
+	return count, err
+}
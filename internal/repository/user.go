@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/PisaListBE/internal/model"
+	"gorm.io/gorm"
+)
+
+// UserRepository 用户数据访问接口
+type UserRepository interface {
+	Create(ctx context.Context, user *model.User) error
+	FindByID(ctx context.Context, id uint) (*model.User, error)
+	// FindByUsername 按用户名查询，不存在时返回 ErrNotFound
+	FindByUsername(ctx context.Context, username string) (*model.User, error)
+	Update(ctx context.Context, user *model.User, updates map[string]interface{}) error
+	// Timezones 返回用户设置过的所有时区，未设置时区的用户对应空字符串
+	Timezones(ctx context.Context) ([]string, error)
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository 创建基于 GORM 的用户仓储
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *model.User) error {
+	return conn(ctx, r.db).Create(user).Error
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id uint) (*model.User, error) {
+	var user model.User
+	if err := conn(ctx, r.db).First(&user, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByUsername(ctx context.Context, username string) (*model.User, error) {
+	var user model.User
+	if err := conn(ctx, r.db).Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) Update(ctx context.Context, user *model.User, updates map[string]interface{}) error {
+	return conn(ctx, r.db).Model(user).Updates(updates).Error
+}
+
+func (r *userRepository) Timezones(ctx context.Context) ([]string, error) {
+	var timezones []string
+	err := conn(ctx, r.db).Model(&model.User{}).Distinct("timezone").Order("timezone asc").Pluck("timezone", &timezones).Error
+	return timezones, err
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WishRepository 心愿及心愿社区数据访问接口
+type WishRepository interface {
+	Create(ctx context.Context, wish *model.Wish) error
+	// FindByID 查询属于 userID 的心愿，不存在时返回 ErrNotFound
+	FindByID(ctx context.Context, userID, id uint) (*model.Wish, error)
+	Update(ctx context.Context, wish *model.Wish, updates map[string]interface{}) error
+	// SetTags 将心愿的标签替换为 tags
+	SetTags(ctx context.Context, wish *model.Wish, tags []model.Tag) error
+	Delete(ctx context.Context, wish *model.Wish) error
+	ListByUser(ctx context.Context, userID uint) ([]model.Wish, error)
+	// FindDeleted 查询属于 userID 且在回收站中的心愿，不存在时返回 ErrNotFound
+	FindDeleted(ctx context.Context, userID, id uint) (*model.Wish, error)
+	// ListDeleted 返回回收站中的心愿，按删除时间倒序
+	ListDeleted(ctx context.Context, userID uint) ([]model.Wish, error)
+	// Restore 恢复回收站中的心愿，不在回收站时返回 ErrNotFound，
+	// version 不为 nil 且与心愿的当前版本不一致时返回 ErrConflict
+	Restore(ctx context.Context, userID, id uint, version *uint) error
+	// PurgeDeletedBefore 彻底删除 before 之前进入回收站的心愿，返回删除数量
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+	// Share 在一个事务内写入社区心愿及其标签并标记原心愿为已分享
+	Share(ctx context.Context, wish *model.Wish, shared *model.SharedWish) error
+	// ListShared 返回所有社区心愿，tag 不为空时只返回带有该名称标签的心愿
+	ListShared(ctx context.Context, tag string) ([]model.SharedWish, error)
+	CountShared(ctx context.Context) (int64, error)
+	// SharedAt 按偏移量返回一条社区心愿
+	SharedAt(ctx context.Context, offset int) (*model.SharedWish, error)
+	// FindShared 查询社区心愿，不存在时返回 ErrNotFound
+	FindShared(ctx context.Context, id uint) (*model.SharedWish, error)
+	// Like 点赞社区心愿并增加点赞数，已点赞过时返回 false
+	Like(ctx context.Context, sharedWishID, userID uint) (bool, error)
+	// Unlike 取消点赞并减少点赞数，未点赞过时返回 false
+	Unlike(ctx context.Context, sharedWishID, userID uint) (bool, error)
+	// FindByClientID 按客户端ID查询属于 userID 的心愿，包括已删除的，不存在时返回 ErrNotFound
+	FindByClientID(ctx context.Context, userID uint, clientID string) (*model.Wish, error)
+	// ListChangedSince 返回 since 之后创建、修改或删除的心愿，按ID升序，已删除的心愿也会返回；
+	// since 为零值时返回全部未删除的心愿
+	ListChangedSince(ctx context.Context, userID uint, since time.Time) ([]model.Wish, error)
+}
+
+type wishRepository struct {
+	db *gorm.DB
+}
+
+// NewWishRepository 创建基于 GORM 的心愿仓储
+func NewWishRepository(db *gorm.DB) WishRepository {
+	return &wishRepository{db: db}
+}
+
+func (r *wishRepository) Create(ctx context.Context, wish *model.Wish) error {
+	return conn(ctx, r.db).Create(wish).Error
+}
+
+func (r *wishRepository) FindByID(ctx context.Context, userID, id uint) (*model.Wish, error) {
+	var wish model.Wish
+	if err := conn(ctx, r.db).Scopes(withTags).Where("id = ? AND user_id = ?", id, userID).First(&wish).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &wish, nil
+}
+
+func (r *wishRepository) Update(ctx context.Context, wish *model.Wish, updates map[string]interface{}) error {
+	return updateVersioned(conn(ctx, r.db), wish, &wish.Version, updates)
+}
+
+func (r *wishRepository) SetTags(ctx context.Context, wish *model.Wish, tags []model.Tag) error {
+	return conn(ctx, r.db).Model(wish).Association("Tags").Replace(tags)
+}
+
+func (r *wishRepository) Delete(ctx context.Context, wish *model.Wish) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// 先按版本号更新一次，读取之后心愿被修改过时放弃删除
+		if err := updateVersioned(tx, wish, &wish.Version, nil); err != nil {
+			return err
+		}
+		return tx.Delete(wish).Error
+	})
+}
+
+func (r *wishRepository) ListByUser(ctx context.Context, userID uint) ([]model.Wish, error) {
+	var wishes []model.Wish
+	err := conn(ctx, r.db).Scopes(withTags).Where("user_id = ?", userID).Find(&wishes).Error
+	return wishes, err
+}
+
+func (r *wishRepository) FindDeleted(ctx context.Context, userID, id uint) (*model.Wish, error) {
+	var wish model.Wish
+	err := conn(ctx, r.db).Unscoped().Scopes(withTags).
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+		First(&wish).Error
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &wish, nil
+}
+
+func (r *wishRepository) ListDeleted(ctx context.Context, userID uint) ([]model.Wish, error) {
+	var wishes []model.Wish
+	err := conn(ctx, r.db).Unscoped().Scopes(withTags).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at desc").
+		Find(&wishes).Error
+	return wishes, err
+}
+
+func (r *wishRepository) Restore(ctx context.Context, userID, id uint, version *uint) error {
+	db := conn(ctx, r.db)
+	var wish model.Wish
+	err := db.Unscoped().Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).First(&wish).Error
+	if err != nil {
+		return translate(err)
+	}
+	if version != nil && *version != wish.Version {
+		return ErrConflict
+	}
+	// 查询条件不能带到更新语句中，需要重新开始一条语句
+	return updateVersioned(db.Unscoped(), &wish, &wish.Version, map[string]interface{}{"deleted_at": nil})
+}
+
+func (r *wishRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().Model(&model.Wish{}).
+			Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+		if err := tx.Exec("DELETE FROM wish_tags WHERE wish_id IN (?)", expired).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Delete(&model.Wish{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
+func (r *wishRepository) Share(ctx context.Context, wish *model.Wish, shared *model.SharedWish) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(shared).Error; err != nil {
+			return err
+		}
+		return updateVersioned(tx, wish, &wish.Version, map[string]interface{}{"is_shared": true})
+	})
+}
+
+func (r *wishRepository) ListShared(ctx context.Context, tag string) ([]model.SharedWish, error) {
+	var sharedWishes []model.SharedWish
+	db := conn(ctx, r.db).Preload("Tags")
+	if tag != "" {
+		db = db.Where("id IN (?)", conn(ctx, r.db).Model(&model.SharedWishTag{}).Select("shared_wish_id").Where("name = ?", tag))
+	}
+	err := db.Find(&sharedWishes).Error
+	return sharedWishes, err
+}
+
+func (r *wishRepository) CountShared(ctx context.Context) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.SharedWish{}).Count(&count).Error
+	return count, err
+}
+
+func (r *wishRepository) SharedAt(ctx context.Context, offset int) (*model.SharedWish, error) {
+	var wish model.SharedWish
+	if err := conn(ctx, r.db).Preload("Tags").Offset(offset).First(&wish).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &wish, nil
+}
+
+func (r *wishRepository) FindShared(ctx context.Context, id uint) (*model.SharedWish, error) {
+	var wish model.SharedWish
+	if err := conn(ctx, r.db).Preload("Tags").First(&wish, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &wish, nil
+}
+
+func (r *wishRepository) Like(ctx context.Context, sharedWishID, userID uint) (bool, error) {
+	liked := false
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		like := &model.SharedWishLike{SharedWishID: sharedWishID, UserID: userID}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(like)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		liked = true
+		return tx.Model(&model.SharedWish{}).
+			Where("id = ?", sharedWishID).
+			Update("like_count", gorm.Expr("like_count + 1")).Error
+	})
+	return liked, err
+}
+
+func (r *wishRepository) Unlike(ctx context.Context, sharedWishID, userID uint) (bool, error) {
+	unliked := false
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("shared_wish_id = ? AND user_id = ?", sharedWishID, userID).Delete(&model.SharedWishLike{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		unliked = true
+		return tx.Model(&model.SharedWish{}).
+			Where("id = ? AND like_count > 0", sharedWishID).
+			Update("like_count", gorm.Expr("like_count - 1")).Error
+	})
+	return unliked, err
+}
+
+func (r *wishRepository) FindByClientID(ctx context.Context, userID uint, clientID string) (*model.Wish, error) {
+	var wish model.Wish
+	err := conn(ctx, r.db).Unscoped().Scopes(withTags).Where("user_id = ? AND client_id = ?", userID, clientID).First(&wish).Error
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &wish, nil
+}
+
+func (r *wishRepository) ListChangedSince(ctx context.Context, userID uint, since time.Time) ([]model.Wish, error) {
+	var wishes []model.Wish
+	err := changedSince(conn(ctx, r.db), since).Scopes(withTags).Where("user_id = ?", userID).Order("id asc").Find(&wishes).Error
+	return wishes, err
+}
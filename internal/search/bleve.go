@@ -0,0 +1,252 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/lang/cjk"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/highlight/highlighter/html"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"gorm.io/gorm"
+)
+
+const (
+	// syncBatchSize 同步时每批读取的记录数
+	syncBatchSize = 500
+	// syncOverlap 每次同步时回看的时长，避免漏掉同步期间尚未提交、更新时间早于水位的事务
+	syncOverlap = time.Minute
+)
+
+// syncedKey 索引中保存同步水位的内部键，索引目录重建后从头同步
+var syncedKey = []byte("synced_at")
+
+// bleveDocument 索引中的文档，ID 为 "类型:记录ID"
+type bleveDocument struct {
+	Type string `json:"type"`
+	// UserID 任务和心愿的所有者，共享心愿为空
+	UserID      string `json:"user_id"`
+	Event       string `json:"event"`
+	Description string `json:"description"`
+}
+
+// bleveSearcher 基于内嵌 Bleve 索引的搜索后端，使用 CJK 二元分词，支持所有数据库。
+// 索引由后台任务按 updated_at / deleted_at 定期增量同步，搜索时不访问数据库，
+// 刚创建或修改的内容在下次同步后才能搜到。
+type bleveSearcher struct {
+	db    *gorm.DB
+	index bleve.Index
+
+	// mu 保证同一时间只有一个同步在执行，搜索不需要加锁
+	mu     sync.Mutex
+	synced time.Time
+}
+
+// NewBleveSearcher 打开或创建 path 下的索引，path 为空时使用内存索引
+func NewBleveSearcher(db *gorm.DB, path string) (Searcher, error) {
+	var index bleve.Index
+	var err error
+	if path == "" {
+		index, err = bleve.NewMemOnly(bleveMapping())
+	} else {
+		index, err = bleve.Open(path)
+		if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+			index, err = bleve.New(path, bleveMapping())
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开搜索索引失败: %w", err)
+	}
+
+	s := &bleveSearcher{db: db, index: index}
+	raw, err := index.GetInternal(syncedKey)
+	if err != nil {
+		index.Close()
+		return nil, fmt.Errorf("读取搜索索引同步时间失败: %w", err)
+	}
+	if len(raw) > 0 {
+		if t, err := time.Parse(time.RFC3339Nano, string(raw)); err == nil {
+			s.synced = t
+		}
+	}
+	return s, nil
+}
+
+func bleveMapping() mapping.IndexMapping {
+	keywordField := bleve.NewTextFieldMapping()
+	keywordField.Analyzer = keyword.Name
+	keywordField.Store = false
+	keywordField.IncludeTermVectors = false
+
+	textField := bleve.NewTextFieldMapping()
+	textField.Analyzer = cjk.AnalyzerName
+
+	doc := bleve.NewDocumentStaticMapping()
+	doc.AddFieldMappingsAt("type", keywordField)
+	doc.AddFieldMappingsAt("user_id", keywordField)
+	doc.AddFieldMappingsAt("event", textField)
+	doc.AddFieldMappingsAt("description", textField)
+
+	m := bleve.NewIndexMapping()
+	m.DefaultMapping = doc
+	m.DefaultAnalyzer = cjk.AnalyzerName
+	return m
+}
+
+func (s *bleveSearcher) Search(ctx context.Context, q Query) ([]Hit, error) {
+	event := bleve.NewMatchQuery(q.Text)
+	event.SetField("event")
+	description := bleve.NewMatchQuery(q.Text)
+	description.SetField("description")
+	text := bleve.NewDisjunctionQuery(event, description)
+
+	scope := bleve.NewDisjunctionQuery(bleve.NewConjunctionQuery(
+		termQuery("user_id", strconv.FormatUint(uint64(q.UserID), 10)),
+		bleve.NewDisjunctionQuery(termQuery("type", TypeTask), termQuery("type", TypeWish)),
+	))
+	if q.Community {
+		scope.AddQuery(termQuery("type", TypeSharedWish))
+	}
+
+	req := bleve.NewSearchRequestOptions(bleve.NewConjunctionQuery(text, scope), q.Limit, 0, false)
+	req.Fields = []string{"event", "description"}
+	req.Highlight = bleve.NewHighlightWithStyle(html.Name)
+	req.Highlight.AddField("event")
+	req.Highlight.AddField("description")
+
+	result, err := s.index.SearchInContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]Hit, 0, len(result.Hits))
+	for _, h := range result.Hits {
+		typ, rawID, _ := strings.Cut(h.ID, ":")
+		id, err := strconv.ParseUint(rawID, 10, 64)
+		if err != nil {
+			continue
+		}
+		hits = append(hits, Hit{
+			Type:        typ,
+			ID:          uint(id),
+			Score:       h.Score,
+			Event:       fragment(h.Fragments["event"], h.Fields["event"]),
+			Description: fragment(h.Fragments["description"], h.Fields["description"]),
+		})
+	}
+	return hits, nil
+}
+
+func termQuery(field, term string) *query.TermQuery {
+	q := bleve.NewTermQuery(term)
+	q.SetField(field)
+	return q
+}
+
+// fragment 返回字段的第一个高亮片段，字段没有匹配时返回原文开头
+func fragment(fragments []string, stored interface{}) string {
+	if len(fragments) > 0 {
+		return fragments[0]
+	}
+	text, _ := stored.(string)
+	return plain(text)
+}
+
+// syncSource 需要同步到索引的一张表
+type syncSource struct {
+	typ   string
+	model interface{}
+	// userColumn 所有者列，为空表示共享内容
+	userColumn string
+}
+
+var syncSources = []syncSource{
+	{typ: TypeTask, model: &model.Task{}, userColumn: "user_id"},
+	{typ: TypeWish, model: &model.Wish{}, userColumn: "user_id"},
+	{typ: TypeSharedWish, model: &model.SharedWish{}},
+}
+
+type syncRow struct {
+	ID          uint
+	UserID      uint
+	Event       string
+	Description string
+	DeletedAt   *time.Time
+}
+
+// Sync 将上次同步后更新或删除的记录写入索引，第一次同步时重建全部内容
+func (s *bleveSearcher) Sync(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	started := time.Now().UTC()
+	since := s.synced.Add(-syncOverlap)
+	for _, src := range syncSources {
+		if err := s.syncSource(ctx, src, since); err != nil {
+			return err
+		}
+	}
+
+	if err := s.index.SetInternal(syncedKey, []byte(started.Format(time.RFC3339Nano))); err != nil {
+		return fmt.Errorf("保存搜索索引同步时间失败: %w", err)
+	}
+	s.synced = started
+	return nil
+}
+
+func (s *bleveSearcher) syncSource(ctx context.Context, src syncSource, since time.Time) error {
+	columns := "id, event, description, deleted_at"
+	if src.userColumn != "" {
+		columns += ", " + src.userColumn + " AS user_id"
+	}
+
+	var lastID uint
+	for {
+		var rows []syncRow
+		err := s.db.WithContext(ctx).Unscoped().Model(src.model).
+			Select(columns).
+			Where("id > ?", lastID).
+			Where("(updated_at >= ? OR deleted_at >= ?)", since, since).
+			Order("id asc").
+			Limit(syncBatchSize).
+			Find(&rows).Error
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		batch := s.index.NewBatch()
+		for _, row := range rows {
+			id := src.typ + ":" + strconv.FormatUint(uint64(row.ID), 10)
+			if row.DeletedAt != nil {
+				batch.Delete(id)
+				continue
+			}
+			doc := bleveDocument{Type: src.typ, Event: row.Event, Description: row.Description}
+			if src.userColumn != "" {
+				doc.UserID = strconv.FormatUint(uint64(row.UserID), 10)
+			}
+			if err := batch.Index(id, doc); err != nil {
+				return err
+			}
+		}
+		if err := s.index.Batch(batch); err != nil {
+			return err
+		}
+		lastID = rows[len(rows)-1].ID
+	}
+}
+
+func (s *bleveSearcher) Close() error {
+	return s.index.Close()
+}
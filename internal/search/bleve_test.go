@@ -0,0 +1,145 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/pkg/migrate"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestBleve 在内存 SQLite 上创建使用内存索引的 Bleve 后端
+func newTestBleve(t *testing.T) (*gorm.DB, *bleveSearcher) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if _, err := migrate.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewBleveSearcher(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return db, s.(*bleveSearcher)
+}
+
+func TestBleveSearchChinese(t *testing.T) {
+	db, s := newTestBleve(t)
+	ctx := context.Background()
+
+	mine := model.Task{UserID: 1, Event: "周末去超市买牛奶和鸡蛋", Description: "<b>全脂</b>牛奶两盒"}
+	others := model.Task{UserID: 2, Event: "买牛奶"}
+	wish := model.Wish{UserID: 1, Event: "去草原喝新鲜牛奶"}
+	shared := model.SharedWish{Event: "每天一杯牛奶", SharedByUserID: 2}
+	for _, v := range []interface{}{&mine, &others, &wish, &shared} {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	hits, err := s.Search(ctx, Query{UserID: 1, Text: "牛奶", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]Hit{}
+	for _, h := range hits {
+		got[h.Type] = h
+	}
+	if len(hits) != 2 || got[TypeTask].ID != mine.ID || got[TypeWish].ID != wish.ID {
+		t.Fatalf("搜索结果 %+v, want 自己的任务 %d 和心愿 %d", hits, mine.ID, wish.ID)
+	}
+
+	task := got[TypeTask]
+	if !strings.Contains(task.Event, "<mark>牛奶</mark>") {
+		t.Errorf("event 片段没有高亮: %q", task.Event)
+	}
+	if !strings.Contains(task.Description, "<mark>牛奶</mark>") {
+		t.Errorf("description 片段没有高亮: %q", task.Description)
+	}
+	if strings.Contains(task.Description, "<b>") || !strings.Contains(task.Description, "&lt;b&gt;") {
+		t.Errorf("description 中的 HTML 没有转义: %q", task.Description)
+	}
+
+	// 多字词按二元组匹配
+	hits, err = s.Search(ctx, Query{UserID: 1, Text: "超市", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 1 || hits[0].ID != mine.ID {
+		t.Fatalf("搜索“超市” = %+v", hits)
+	}
+
+	hits, err = s.Search(ctx, Query{UserID: 1, Text: "牛奶", Community: true, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 3 {
+		t.Fatalf("包含社区心愿的搜索结果 %+v, want 3 条", hits)
+	}
+}
+
+func TestBleveSyncDeletes(t *testing.T) {
+	db, s := newTestBleve(t)
+	ctx := context.Background()
+
+	task := model.Task{UserID: 1, Event: "背英语单词"}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&task).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// 同步之前仍能搜到，搜索本身不访问数据库
+	if hits, err := s.Search(ctx, Query{UserID: 1, Text: "单词", Limit: 10}); err != nil || len(hits) != 1 {
+		t.Fatalf("同步前搜索 = %+v, %v, want 1 条", hits, err)
+	}
+	if err := s.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if hits, err := s.Search(ctx, Query{UserID: 1, Text: "单词", Limit: 10}); err != nil || len(hits) != 0 {
+		t.Fatalf("删除后搜索 = %+v, %v, want 0 条", hits, err)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text, query, want string
+	}{
+		{"买牛奶", "牛奶", "买<mark>牛奶</mark>"},
+		{"Buy MILK today", "milk", "Buy <mark>MILK</mark> today"},
+		{"<b>牛奶</b>", "牛奶", "&lt;b&gt;<mark>牛奶</mark>&lt;/b&gt;"},
+		{"周末买牛奶", "买牛奶啊", "周末<mark>买牛奶</mark>"},
+		{"没有匹配 <i>", "牛奶", "没有匹配 &lt;i&gt;"},
+	}
+	for _, tt := range tests {
+		if got := highlight(tt.text, terms(tt.query)); got != tt.want {
+			t.Errorf("highlight(%q, %q) = %q, want %q", tt.text, tt.query, got, tt.want)
+		}
+	}
+
+	long := strings.Repeat("字", 200) + "牛奶"
+	if got := highlight(long, terms("牛奶")); !strings.HasPrefix(got, "…") || !strings.Contains(got, "<mark>牛奶</mark>") {
+		t.Errorf("长文本的片段 = %q", got)
+	}
+}
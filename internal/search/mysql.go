@@ -0,0 +1,78 @@
+package search
+
+import (
+	"context"
+	"sort"
+
+	"github.com/PisaListBE/internal/model"
+	"gorm.io/gorm"
+)
+
+// matchAgainst 使用迁移 0014 创建的 FULLTEXT (event, description) WITH PARSER ngram 索引。
+// 自然语言模式不解析查询中的运算符，用户输入的 +、-、* 等字符不会导致语法错误。
+const matchAgainst = "MATCH(event, description) AGAINST (? IN NATURAL LANGUAGE MODE)"
+
+// mysqlSearcher 基于 MySQL FULLTEXT ngram 索引的搜索后端，数据即时可见，不需要同步
+type mysqlSearcher struct {
+	db *gorm.DB
+}
+
+// NewMySQLSearcher 创建 MySQL 搜索后端
+func NewMySQLSearcher(db *gorm.DB) Searcher {
+	return &mysqlSearcher{db: db}
+}
+
+type mysqlRow struct {
+	ID          uint
+	Event       string
+	Description string
+	Score       float64
+}
+
+func (s *mysqlSearcher) Search(ctx context.Context, q Query) ([]Hit, error) {
+	words := terms(q.Text)
+	hits := []Hit{}
+
+	collect := func(typ string, db *gorm.DB) error {
+		var rows []mysqlRow
+		err := db.Select("id, event, description, "+matchAgainst+" AS score", q.Text).
+			Where(matchAgainst, q.Text).
+			Order("score desc").
+			Limit(q.Limit).
+			Find(&rows).Error
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			hits = append(hits, Hit{
+				Type:        typ,
+				ID:          row.ID,
+				Score:       row.Score,
+				Event:       highlight(row.Event, words),
+				Description: highlight(row.Description, words),
+			})
+		}
+		return nil
+	}
+
+	db := s.db.WithContext(ctx)
+	if err := collect(TypeTask, db.Model(&model.Task{}).Where("user_id = ?", q.UserID)); err != nil {
+		return nil, err
+	}
+	if err := collect(TypeWish, db.Model(&model.Wish{}).Where("user_id = ?", q.UserID)); err != nil {
+		return nil, err
+	}
+	if q.Community {
+		if err := collect(TypeSharedWish, db.Model(&model.SharedWish{}).Where("deleted_at IS NULL")); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	return hits, nil
+}
+
+func (s *mysqlSearcher) Close() error { return nil }
@@ -0,0 +1,154 @@
+package search
+
+import (
+	"context"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// 搜索结果类型
+const (
+	TypeTask       = "task"
+	TypeWish       = "wish"
+	TypeSharedWish = "shared_wish"
+)
+
+// snippetSize 高亮片段的最大字符数
+const snippetSize = 100
+
+// Query 搜索条件
+type Query struct {
+	UserID uint
+	Text   string
+	// Community 同时搜索社区中的共享心愿
+	Community bool
+	Limit     int
+}
+
+// Hit 一条搜索结果，按相关度从高到低排列。
+// Event 和 Description 为高亮片段：匹配部分用 <mark></mark> 包裹，其余内容已做 HTML 转义。
+type Hit struct {
+	// Type 结果类型：task、wish 或 shared_wish
+	Type        string  `json:"type" example:"task"`
+	ID          uint    `json:"id" example:"1"`
+	Score       float64 `json:"score" example:"1.25"`
+	Event       string  `json:"event" example:"<mark>买菜</mark>"`
+	Description string  `json:"description" example:"牛奶、<mark>鸡蛋</mark>、面包"`
+}
+
+// Searcher 全文搜索后端，只返回用户自己未删除的任务和心愿，Community 时包含共享心愿
+type Searcher interface {
+	Search(ctx context.Context, q Query) ([]Hit, error)
+	Close() error
+}
+
+// Syncer 需要自行维护索引的后端，由后台任务定期调用 Sync 同步数据库中的变更
+type Syncer interface {
+	Sync(ctx context.Context) error
+}
+
+// plain 没有匹配时返回的片段：截取开头并转义
+func plain(text string) string {
+	runes := []rune(text)
+	if len(runes) <= snippetSize {
+		return html.EscapeString(text)
+	}
+	return html.EscapeString(string(runes[:snippetSize])) + "…"
+}
+
+// terms 拆分查询词用于高亮，包含中文的词额外拆成二元组，与 ngram 分词的匹配方式一致
+func terms(text string) [][]rune {
+	var result [][]rune
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		word := []rune(strings.ToLower(field))
+		result = append(result, word)
+		if len(word) > 2 && hasHan(word) {
+			for i := 0; i+2 <= len(word); i++ {
+				result = append(result, word[i:i+2])
+			}
+		}
+	}
+	return result
+}
+
+func hasHan(word []rune) bool {
+	for _, r := range word {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// highlight 在 text 中标记 words 的所有出现位置（不区分大小写），
+// 返回从第一个匹配附近开始的片段，没有匹配时返回 plain(text)
+func highlight(text string, words [][]rune) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	marked := make([]bool, len(runes))
+	first := -1
+	for _, word := range words {
+		for i := 0; i+len(word) <= len(lower); i++ {
+			if !equalRunes(lower[i:i+len(word)], word) {
+				continue
+			}
+			for j := i; j < i+len(word); j++ {
+				marked[j] = true
+			}
+			if first < 0 || i < first {
+				first = i
+			}
+		}
+	}
+	if first < 0 {
+		return plain(text)
+	}
+
+	start := first - snippetSize/4
+	if start < 0 {
+		start = 0
+	}
+	end := start + snippetSize
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; {
+		j := i
+		for j < end && marked[j] == marked[i] {
+			j++
+		}
+		if marked[i] {
+			b.WriteString("<mark>")
+			b.WriteString(html.EscapeString(string(runes[i:j])))
+			b.WriteString("</mark>")
+		} else {
+			b.WriteString(html.EscapeString(string(runes[i:j])))
+		}
+		i = j
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+func equalRunes(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
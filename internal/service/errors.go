@@ -0,0 +1,39 @@
+package service
+
+import "errors"
+
+var (
+	ErrTaskNotFound          = errors.New("任务不存在")
+	ErrWishNotFound          = errors.New("心愿不存在")
+	ErrInvalidRecurrence     = errors.New("无效的重复规则")
+	ErrInvalidTaskDates      = errors.New("截止时间不能早于开始时间")
+	ErrTaskNotInTrash        = errors.New("回收站中不存在该任务")
+	ErrWishNotInTrash        = errors.New("回收站中不存在该心愿")
+	ErrNoSharedWish          = errors.New("暂无共享心愿")
+	ErrUsernameTaken         = errors.New("用户名已存在")
+	ErrInvalidCredentials    = errors.New("用户名或密码错误")
+	ErrUserNotFound          = errors.New("用户不存在")
+	ErrInvalidTimezone       = errors.New("无效的时区")
+	ErrReminderNotFound      = errors.New("提醒不存在")
+	ErrInvalidReminder       = errors.New("remind_at 和 offset_minutes 必须且只能指定一个")
+	ErrTaskHasNoDueDate      = errors.New("相对截止时间的提醒需要任务设置截止时间")
+	ErrReminderInPast        = errors.New("提醒时间已过")
+	ErrInvalidChannel        = errors.New("无效的通知渠道")
+	ErrNotificationNotFound  = errors.New("通知不存在")
+	ErrSharedWishNotFound    = errors.New("社区心愿不存在")
+	ErrWishAlreadyShared     = errors.New("心愿已分享到社区")
+	ErrInvalidParent         = errors.New("无效的父任务")
+	ErrChecklistItemNotFound = errors.New("清单项不存在")
+	ErrInvalidChecklistItem  = errors.New("清单项内容不能为空")
+	ErrTagNotFound           = errors.New("标签不存在")
+	ErrTagExists             = errors.New("标签已存在")
+	ErrInvalidTagName        = errors.New("标签名称不能为空")
+	ErrListNotFound          = errors.New("清单不存在")
+	ErrInvalidListName       = errors.New("清单名称不能为空")
+	ErrInvalidDeleteMode     = errors.New("mode 应为 move 或 cascade")
+	ErrInvalidMoveTarget     = errors.New("目标清单无效")
+	ErrInvalidPosition       = errors.New("相邻任务无效，需要指定 after_id 或 before_id，且 after_id 排在 before_id 之前")
+	ErrInvalidSearchQuery    = errors.New("搜索关键词不能为空，且不能超过 100 个字符")
+	ErrVersionConflict       = errors.New("数据已被修改，请获取最新版本后重试")
+	ErrInvalidSyncType       = errors.New("type 应为 task、wish、list 或 tag")
+)
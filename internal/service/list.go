@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/repository"
+)
+
+// 删除清单时清单中任务的处理方式
+const (
+	// DeleteListMove 将任务移到另一个清单，未指定目标清单时移出清单
+	DeleteListMove = "move"
+	// DeleteListCascade 将任务一起移入回收站
+	DeleteListCascade = "cascade"
+)
+
+// ListInput 创建或更新清单的参数
+type ListInput struct {
+	Name  string
+	Color string
+	Icon  string
+	// SortOrder 排列顺序，创建时为空则排在最后，更新时为空则保持不变
+	SortOrder *int
+	Archived  bool
+	// ClientID 离线客户端生成的ID，只在创建时使用，同一用户内重复创建时返回已有的清单，已有的清单已删除时返回 ErrListNotFound
+	ClientID string
+}
+
+// ListService 任务清单相关业务逻辑
+type ListService struct {
+	tx    repository.Transactor
+	lists repository.ListRepository
+	tasks repository.TaskRepository
+}
+
+// NewListService 创建清单服务
+func NewListService(tx repository.Transactor, lists repository.ListRepository, tasks repository.TaskRepository) *ListService {
+	return &ListService{tx: tx, lists: lists, tasks: tasks}
+}
+
+func (s *ListService) get(ctx context.Context, userID, id uint) (*model.List, error) {
+	list, err := s.lists.FindByID(ctx, userID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrListNotFound
+	}
+	return list, err
+}
+
+// Get 返回清单详情
+func (s *ListService) Get(ctx context.Context, userID, id uint) (*model.List, error) {
+	return s.get(ctx, userID, id)
+}
+
+// List 返回用户的清单，archived 为 true 时包含已归档的清单
+func (s *ListService) List(ctx context.Context, userID uint, archived bool) ([]model.List, error) {
+	return s.lists.ListByUser(ctx, userID, archived)
+}
+
+// Create 创建清单
+func (s *ListService) Create(ctx context.Context, userID uint, in ListInput) (*model.List, error) {
+	if in.ClientID != "" {
+		existing, err := s.lists.FindByClientID(ctx, userID, in.ClientID)
+		if err == nil && existing.DeletedAt.Valid {
+			// 同一个 client_id 创建的记录已被删除，不再重复创建
+			return nil, ErrListNotFound
+		}
+		if err == nil {
+			return existing, nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return nil, ErrInvalidListName
+	}
+
+	list := &model.List{
+		UserID:   userID,
+		Name:     name,
+		Color:    strings.ToUpper(in.Color),
+		Icon:     in.Icon,
+		Archived: in.Archived,
+		ClientID: clientID(in.ClientID),
+	}
+	if in.SortOrder != nil {
+		list.SortOrder = *in.SortOrder
+	} else {
+		max, err := s.lists.MaxSortOrder(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		list.SortOrder = max + 1
+	}
+	if err := s.lists.Create(ctx, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Update 更新清单
+func (s *ListService) Update(ctx context.Context, userID, id uint, in ListInput) (*model.List, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return nil, ErrInvalidListName
+	}
+	list, err := s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"name":     name,
+		"color":    strings.ToUpper(in.Color),
+		"icon":     in.Icon,
+		"archived": in.Archived,
+	}
+	if in.SortOrder != nil {
+		updates["sort_order"] = *in.SortOrder
+	}
+	if err := s.lists.Update(ctx, list, updates); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Delete 删除清单。mode 为 DeleteListMove 时把任务移到 target 清单（为 nil 时移出清单），
+// 为 DeleteListCascade 时任务及其子任务一起移入回收站。
+func (s *ListService) Delete(ctx context.Context, userID, id uint, mode string, target *uint) error {
+	if mode != DeleteListMove && mode != DeleteListCascade {
+		return ErrInvalidDeleteMode
+	}
+	if target != nil && (mode != DeleteListMove || *target == id) {
+		return ErrInvalidMoveTarget
+	}
+
+	return s.tx.Transaction(ctx, func(ctx context.Context) error {
+		list, err := s.get(ctx, userID, id)
+		if err != nil {
+			return err
+		}
+		if target != nil {
+			if _, err := s.get(ctx, userID, *target); errors.Is(err, ErrListNotFound) {
+				return ErrInvalidMoveTarget
+			} else if err != nil {
+				return err
+			}
+		}
+
+		if mode == DeleteListCascade {
+			if err := s.tasks.DeleteByList(ctx, userID, id); err != nil {
+				return err
+			}
+		} else if err := s.tasks.MoveList(ctx, userID, id, target); err != nil {
+			return err
+		}
+		return s.lists.Delete(ctx, list)
+	})
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/repository"
+	"github.com/PisaListBE/pkg/pagination"
+)
+
+// dueScanBatch 检查当天循环任务时每批读取的任务数量
+const dueScanBatch = 200
+
+// NotificationQuery 通知列表查询参数
+type NotificationQuery struct {
+	UnreadOnly bool
+	// Cursor 上一页返回的 next_cursor，为空时返回第一页
+	Cursor string
+	Limit  int
+}
+
+// NotificationPage 通知列表的一页，NextCursor 为空表示没有更多通知
+type NotificationPage struct {
+	Items      []model.Notification `json:"items"`
+	NextCursor string               `json:"next_cursor" example:"eyJpIjo0Mn0"`
+}
+
+// notificationCursor 通知列表游标，记录上一页最后一条通知的ID
+type notificationCursor struct {
+	ID uint `json:"i"`
+}
+
+// NotificationService 站内通知收件箱，以及由业务事件产生的通知
+type NotificationService struct {
+	notifications repository.NotificationRepository
+	tasks         repository.TaskRepository
+	users         *UserService
+	// dueHour 用户时区几点开始发送当天循环任务的通知
+	dueHour int
+}
+
+// NewNotificationService 创建通知服务
+func NewNotificationService(notifications repository.NotificationRepository, tasks repository.TaskRepository, users *UserService, dueHour int) *NotificationService {
+	return &NotificationService{notifications: notifications, tasks: tasks, users: users, dueHour: dueHour}
+}
+
+// List 按时间倒序分页返回用户的通知
+func (s *NotificationService) List(ctx context.Context, userID uint, q NotificationQuery) (*NotificationPage, error) {
+	if q.Limit <= 0 {
+		q.Limit = pagination.DefaultLimit
+	}
+	var cur notificationCursor
+	if q.Cursor != "" {
+		if err := pagination.Decode(q.Cursor, &cur); err != nil {
+			return nil, err
+		}
+	}
+
+	// 多查一条用于判断是否还有下一页
+	items, err := s.notifications.List(ctx, userID, q.UnreadOnly, cur.ID, q.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &NotificationPage{Items: items}
+	if len(items) > q.Limit {
+		page.Items = items[:q.Limit]
+		page.NextCursor = pagination.Encode(notificationCursor{ID: page.Items[q.Limit-1].ID})
+	}
+	return page, nil
+}
+
+// MarkRead 将通知标记为已读
+func (s *NotificationService) MarkRead(ctx context.Context, userID, id uint) error {
+	err := s.notifications.MarkRead(ctx, userID, id, time.Now().UTC())
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrNotificationNotFound
+	}
+	return err
+}
+
+// MarkAllRead 将所有未读通知标记为已读，返回标记的数量
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID uint) (int64, error) {
+	return s.notifications.MarkAllRead(ctx, userID, time.Now().UTC())
+}
+
+// Delete 删除通知
+func (s *NotificationService) Delete(ctx context.Context, userID, id uint) error {
+	err := s.notifications.Delete(ctx, userID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrNotificationNotFound
+	}
+	return err
+}
+
+// UnreadCount 返回未读通知数量
+func (s *NotificationService) UnreadCount(ctx context.Context, userID uint) (int64, error) {
+	return s.notifications.CountUnread(ctx, userID)
+}
+
+// NotifyWishLiked 通知分享者其社区心愿被点赞，自己点赞或系统初始数据不通知
+func (s *NotificationService) NotifyWishLiked(ctx context.Context, shared *model.SharedWish, likerID uint) error {
+	if shared.SharedByUserID == 0 || shared.SharedByUserID == likerID {
+		return nil
+	}
+	liker, err := s.users.Get(ctx, likerID)
+	if err != nil {
+		return err
+	}
+
+	// 同一用户对同一心愿反复点赞只通知一次
+	key := fmt.Sprintf("wish_liked:%d:%d", shared.ID, likerID)
+	_, err = s.notifications.CreateOnce(ctx, &model.Notification{
+		UserID:     shared.SharedByUserID,
+		Type:       model.NotificationWishLiked,
+		Title:      liker.Username + " 点赞了你分享的心愿",
+		Body:       shared.Event,
+		Resource:   "shared_wish",
+		ResourceID: shared.ID,
+		DedupKey:   &key,
+	})
+	return err
+}
+
+// NotifyDueRecurring 为今天需要完成且尚未完成的循环任务写入通知，返回新写入的数量。
+// “今天”按任务所属用户的时区计算，并且只在当地时间 dueHour 点之后发送；
+// 每个任务每天最多一条，可以放心重复执行。
+// 按用户时区分组扫描：还没到 dueHour 的时区直接跳过，已开始且当天已发送过通知的任务不再读取。
+func (s *NotificationService) NotifyDueRecurring(ctx context.Context) (int, error) {
+	timezones, err := s.users.Timezones(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, timezone := range timezones {
+		now := nowIn(s.users.locationOf(timezone))
+		if now.Hour() < s.dueHour {
+			continue
+		}
+		q := repository.RecurringScan{
+			Timezone:      timezone,
+			Now:           now.UTC(),
+			NotifiedSince: startOfDay(now).UTC(),
+			Limit:         dueScanBatch,
+		}
+		for {
+			tasks, err := s.tasks.ScanRecurring(ctx, q)
+			if err != nil {
+				return created, err
+			}
+			for i := range tasks {
+				ok, err := s.notifyDue(ctx, &tasks[i], now)
+				if err != nil {
+					return created, err
+				}
+				if ok {
+					created++
+				}
+			}
+			if len(tasks) < dueScanBatch {
+				break
+			}
+			q.AfterID = tasks[len(tasks)-1].ID
+		}
+	}
+	return created, nil
+}
+
+// notifyDue 在 now 为任务的重复日且尚未完成时写入通知
+func (s *NotificationService) notifyDue(ctx context.Context, task *model.Task, now time.Time) (bool, error) {
+	if now.Hour() < s.dueHour {
+		return false, nil
+	}
+	rule, ok := taskRule(task)
+	if !ok || !rule.Occurs(recurrenceStart(task, now.Location()), now) {
+		return false, nil
+	}
+	if task.StartAt != nil && task.StartAt.After(now) {
+		return false, nil
+	}
+	if completedForOccurrence(task, rule, now) {
+		return false, nil
+	}
+
+	today := now.Format(DateLayout)
+	key := fmt.Sprintf("task_due:%d:%s", task.ID, today)
+	return s.notifications.CreateOnce(ctx, &model.Notification{
+		UserID:     task.UserID,
+		Type:       model.NotificationTaskDue,
+		Title:      "今天的循环任务：" + task.Event,
+		Body:       task.Description,
+		Resource:   "task",
+		ResourceID: task.ID,
+		DedupKey:   &key,
+	})
+}
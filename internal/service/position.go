@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/pkg/ordering"
+)
+
+// 任务列表的排序字段
+const (
+	SortPosition   = "position"
+	SortImportance = "importance"
+	SortDueAt      = "due_at"
+	SortCreatedAt  = "created_at"
+)
+
+// TaskSort 任务列表的排序方式，Field 为空时使用列表的默认顺序
+type TaskSort struct {
+	Field string
+	Desc  bool
+}
+
+// ValidSortField 判断 field 是否为支持的排序字段
+func ValidSortField(field string) bool {
+	switch field {
+	case SortPosition, SortImportance, SortDueAt, SortCreatedAt:
+		return true
+	}
+	return false
+}
+
+// sortTasks 按 by 排序，字段相同时按 position 排序；按截止时间排序时没有截止时间的任务总在最后
+func sortTasks(tasks []model.Task, by TaskSort) {
+	less := func(a, b *model.Task) bool { return false }
+	switch by.Field {
+	case SortImportance:
+		less = func(a, b *model.Task) bool { return a.ImportanceLevel < b.ImportanceLevel }
+	case SortDueAt:
+		less = func(a, b *model.Task) bool { return a.DueAt != nil && b.DueAt != nil && a.DueAt.Before(*b.DueAt) }
+	case SortCreatedAt:
+		less = func(a, b *model.Task) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	case SortPosition:
+		less = func(a, b *model.Task) bool { return a.Position < b.Position }
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := &tasks[i], &tasks[j]
+		if by.Field == SortDueAt && (a.DueAt == nil) != (b.DueAt == nil) {
+			return b.DueAt == nil
+		}
+		if by.Desc {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		if tasks[i].Position != tasks[j].Position {
+			return tasks[i].Position < tasks[j].Position
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+}
+
+// MoveInput 移动任务的参数，至少指定一个相邻任务
+type MoveInput struct {
+	// AfterID 移动后排在该任务之后
+	AfterID *uint
+	// BeforeID 移动后排在该任务之前
+	BeforeID *uint
+	// Version 客户端期望的任务版本，为 nil 时不检查
+	Version *uint
+}
+
+// Move 把任务移到相邻的两个任务之间，只改写被移动任务的 position。
+// 只指定一侧时，另一侧取该任务当前的实际相邻任务。
+func (s *TaskService) Move(ctx context.Context, userID, id uint, in MoveInput, loc *time.Location) (*model.Task, error) {
+	if in.AfterID == nil && in.BeforeID == nil {
+		return nil, ErrInvalidPosition
+	}
+	if (in.AfterID != nil && *in.AfterID == id) || (in.BeforeID != nil && *in.BeforeID == id) {
+		return nil, ErrInvalidPosition
+	}
+
+	var task *model.Task
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if task, err = s.get(ctx, userID, id); err != nil {
+			return err
+		}
+		if err := checkVersion(task.Version, in.Version); err != nil {
+			return err
+		}
+
+		var lower, upper string
+		if in.AfterID != nil {
+			if lower, err = s.neighborPosition(ctx, userID, *in.AfterID); err != nil {
+				return err
+			}
+		}
+		if in.BeforeID != nil {
+			if upper, err = s.neighborPosition(ctx, userID, *in.BeforeID); err != nil {
+				return err
+			}
+		}
+		if in.BeforeID == nil {
+			if upper, err = s.tasks.NeighborPosition(ctx, userID, id, lower, true); err != nil {
+				return err
+			}
+		}
+		if in.AfterID == nil {
+			if lower, err = s.tasks.NeighborPosition(ctx, userID, id, upper, false); err != nil {
+				return err
+			}
+		}
+
+		position, err := ordering.Between(lower, upper)
+		if err != nil {
+			return ErrInvalidPosition
+		}
+		return s.tasks.Update(ctx, task, map[string]interface{}{"position": position})
+	})
+	if err != nil {
+		return nil, versionError(err)
+	}
+	presentTask(task, nowIn(loc))
+	return task, nil
+}
+
+// neighborPosition 返回相邻任务的 position，任务不存在时返回 ErrInvalidPosition
+func (s *TaskService) neighborPosition(ctx context.Context, userID, id uint) (string, error) {
+	neighbor, err := s.get(ctx, userID, id)
+	if errors.Is(err, ErrTaskNotFound) {
+		return "", ErrInvalidPosition
+	}
+	if err != nil {
+		return "", err
+	}
+	return neighbor.Position, nil
+}
+
+// nextPosition 返回排在用户所有任务最后的 position
+func (s *TaskService) nextPosition(ctx context.Context, userID uint) (string, error) {
+	last, err := s.tasks.MaxPosition(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return ordering.Between(last, "")
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/pkg/recurrence"
+)
+
+// DateLayout 完成记录等按天存储的日期格式
+const DateLayout = "2006-01-02"
+
+// nowIn 返回用户时区下的当前时间，按天计算的逻辑都基于它
+func nowIn(loc *time.Location) time.Time {
+	return time.Now().In(loc)
+}
+
+// utcPtr 将可选时间转换为 UTC 后保存
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+// startOfDay 返回 t 所在日期的零点
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// resolveRecurrence 根据请求计算任务应保存的重复规则。
+// 只传 is_cycle=true 的旧客户端保留原有规则，原来没有规则时按每天重复处理。
+func resolveRecurrence(in TaskInput, current string) (string, error) {
+	if in.Recurrence != "" {
+		rule, err := recurrence.Normalize(in.Recurrence)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+		}
+		return rule, nil
+	}
+	if !in.IsCycle {
+		return "", nil
+	}
+	if current != "" {
+		return current, nil
+	}
+	daily := recurrence.Rule{Freq: recurrence.Daily, Interval: 1}
+	return daily.String(), nil
+}
+
+// taskRule 解析任务的重复规则，非循环任务或规则无效时返回 false
+func taskRule(task *model.Task) (*recurrence.Rule, bool) {
+	if task.Recurrence == "" {
+		return nil, false
+	}
+	rule, err := recurrence.Parse(task.Recurrence)
+	if err != nil {
+		return nil, false
+	}
+	return rule, true
+}
+
+// recurrenceStart 返回循环任务的起始日期，设置了开始时间时以开始时间为准
+func recurrenceStart(task *model.Task, loc *time.Location) time.Time {
+	if task.StartAt != nil {
+		return startOfDay(task.StartAt.In(loc))
+	}
+	return startOfDay(task.CreatedAt.In(loc))
+}
+
+// completedForOccurrence 判断循环任务在 now 所属的这一次是否已完成。
+// 完成状态只对当前这一次有效，上一次的完成不会延续到下一次。
+func completedForOccurrence(task *model.Task, rule *recurrence.Rule, now time.Time) bool {
+	if !task.Completed || task.CompletedDate.IsZero() {
+		return false
+	}
+	current, ok := rule.Prev(recurrenceStart(task, now.Location()), now)
+	if !ok {
+		return false
+	}
+	return !task.CompletedDate.In(now.Location()).Before(current)
+}
+
+// presentTask 按 now 计算循环任务的当前完成状态和下一次出现日期
+func presentTask(task *model.Task, now time.Time) {
+	rule, ok := taskRule(task)
+	if !ok {
+		return
+	}
+
+	task.Completed = completedForOccurrence(task, rule, now)
+
+	from := startOfDay(now)
+	if task.Completed {
+		from = from.AddDate(0, 0, 1)
+	}
+	if next, ok := rule.Next(recurrenceStart(task, now.Location()), from); ok {
+		task.NextOccurrence = &next
+	}
+}
+
+// occurrenceDate 返回 now 时刻任务所属那一次的日期，非循环任务为当天
+func occurrenceDate(task *model.Task, now time.Time) string {
+	if rule, ok := taskRule(task); ok {
+		if day, ok := rule.Prev(recurrenceStart(task, now.Location()), now); ok {
+			return day.Format(DateLayout)
+		}
+	}
+	return now.Format(DateLayout)
+}
+
+func presentTasks(tasks []model.Task, now time.Time) {
+	for i := range tasks {
+		presentTask(&tasks[i], now)
+	}
+}
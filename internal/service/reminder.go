@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/notify"
+	"github.com/PisaListBE/internal/repository"
+)
+
+// 提醒调度参数
+const (
+	// reminderBatch 每次轮询最多处理的提醒数量
+	reminderBatch = 100
+	// reminderLease 领取提醒后的租约时长，进程在发送途中退出时，租约到期后会被重新领取
+	reminderLease = 5 * time.Minute
+	// reminderMaxBackoff 发送失败后重试间隔的上限
+	reminderMaxBackoff = time.Hour
+)
+
+// ReminderInput 创建提醒的参数，RemindAt 与 OffsetMinutes 必须且只能指定一个
+type ReminderInput struct {
+	RemindAt      *time.Time
+	OffsetMinutes *int
+	// Channels 逗号分隔的发送渠道，为空时使用默认渠道
+	Channels string
+	// Version 客户端期望的任务版本，为 nil 时不检查
+	Version *uint
+}
+
+// ReminderService 任务提醒的管理和发送
+type ReminderService struct {
+	reminders   repository.ReminderRepository
+	tasks       repository.TaskRepository
+	users       *UserService
+	dispatcher  *notify.Dispatcher
+	maxAttempts int
+}
+
+// NewReminderService 创建提醒服务，maxAttempts 为每个提醒的最大发送尝试次数
+func NewReminderService(reminders repository.ReminderRepository, tasks repository.TaskRepository, users *UserService, dispatcher *notify.Dispatcher, maxAttempts int) *ReminderService {
+	return &ReminderService{
+		reminders:   reminders,
+		tasks:       tasks,
+		users:       users,
+		dispatcher:  dispatcher,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// reminderFireAt 计算提醒的触发时间，相对提醒在任务没有截止时间时返回 nil
+func reminderFireAt(reminder *model.Reminder, task *model.Task) *time.Time {
+	if reminder.RemindAt != nil {
+		return utcPtr(reminder.RemindAt)
+	}
+	if reminder.OffsetMinutes == nil || task.DueAt == nil {
+		return nil
+	}
+	at := task.DueAt.Add(-time.Duration(*reminder.OffsetMinutes) * time.Minute).UTC()
+	return &at
+}
+
+func (s *ReminderService) task(ctx context.Context, userID, taskID uint) (*model.Task, error) {
+	task, err := s.tasks.FindByID(ctx, userID, taskID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrTaskNotFound
+	}
+	return task, err
+}
+
+// Create 为任务添加提醒
+func (s *ReminderService) Create(ctx context.Context, userID, taskID uint, in ReminderInput) (*model.Reminder, error) {
+	if (in.RemindAt == nil) == (in.OffsetMinutes == nil) {
+		return nil, ErrInvalidReminder
+	}
+	channels, err := s.dispatcher.ParseChannels(in.Channels)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidChannel, err)
+	}
+	task, err := s.task(ctx, userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(task.Version, in.Version); err != nil {
+		return nil, err
+	}
+	if in.OffsetMinutes != nil && task.DueAt == nil {
+		return nil, ErrTaskHasNoDueDate
+	}
+
+	reminder := &model.Reminder{
+		TaskID:        task.ID,
+		UserID:        userID,
+		RemindAt:      utcPtr(in.RemindAt),
+		OffsetMinutes: in.OffsetMinutes,
+		Channels:      strings.Join(channels, ","),
+	}
+	reminder.FireAt = reminderFireAt(reminder, task)
+	if reminder.FireAt.Before(time.Now()) {
+		return nil, ErrReminderInPast
+	}
+
+	if err := s.reminders.Create(ctx, reminder); err != nil {
+		return nil, err
+	}
+	return reminder, nil
+}
+
+// List 返回任务的全部提醒
+func (s *ReminderService) List(ctx context.Context, userID, taskID uint) ([]model.Reminder, error) {
+	if _, err := s.task(ctx, userID, taskID); err != nil {
+		return nil, err
+	}
+	return s.reminders.ListByTask(ctx, userID, taskID)
+}
+
+// Delete 删除任务的提醒，version 不为 nil 时必须与任务的版本一致
+func (s *ReminderService) Delete(ctx context.Context, userID, taskID, id uint, version *uint) error {
+	if version != nil {
+		task, err := s.task(ctx, userID, taskID)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(task.Version, version); err != nil {
+			return err
+		}
+	}
+	reminder, err := s.reminders.FindByID(ctx, userID, id)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && reminder.TaskID != taskID) {
+		return ErrReminderNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.reminders.Delete(ctx, reminder)
+}
+
+// rescheduleReminders 在任务截止时间变化后重新计算相对提醒的触发时间。
+// 新的触发时间在未来时重新启用已发送过的提醒。
+func rescheduleReminders(ctx context.Context, reminders repository.ReminderRepository, task *model.Task) error {
+	list, err := reminders.ListByTask(ctx, task.UserID, task.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range list {
+		reminder := &list[i]
+		if reminder.OffsetMinutes == nil {
+			continue
+		}
+		fireAt := reminderFireAt(reminder, task)
+		if fireAt != nil && reminder.FireAt != nil && fireAt.Equal(*reminder.FireAt) {
+			continue
+		}
+
+		updates := map[string]interface{}{"fire_at": fireAt}
+		if fireAt != nil && fireAt.After(now) {
+			updates["sent_at"] = nil
+			updates["attempts"] = 0
+			updates["delivered"] = ""
+			updates["last_error"] = ""
+		}
+		if err := reminders.Update(ctx, reminder, updates); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FireDue 发送所有已到时间的提醒，返回发送成功的数量。
+// 提醒的状态都保存在数据库中，服务重启后会补发停机期间到期的提醒。
+func (s *ReminderService) FireDue(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	due, err := s.reminders.ListDue(ctx, now, s.maxAttempts, reminderBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		reminder := &due[i]
+		claimed, err := s.reminders.Claim(ctx, reminder.ID, reminder.Attempts, now.Add(reminderLease))
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue // 已被其他实例领取
+		}
+		reminder.Attempts++
+
+		ok, err := s.deliver(ctx, reminder)
+		if err != nil {
+			fmt.Printf("发送提醒 %d 失败（第 %d 次）: %v\n", reminder.ID, reminder.Attempts, err)
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// deliver 发送一条已领取的提醒并记录结果，只重试上次失败的渠道
+func (s *ReminderService) deliver(ctx context.Context, reminder *model.Reminder) (bool, error) {
+	now := time.Now().UTC()
+	task, err := s.tasks.FindByID(ctx, reminder.UserID, reminder.TaskID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil // 任务已删除，恢复后由下一次轮询处理
+	}
+	if err != nil {
+		return false, s.fail(ctx, reminder, err)
+	}
+	if _, recurring := taskRule(task); task.Completed && !recurring {
+		// 领取后任务被完成：归还领取，保持待发送，任务重新打开后照常发送
+		return false, s.reminders.Update(ctx, reminder, map[string]interface{}{
+			"fire_at":  reminder.FireAt,
+			"attempts": reminder.Attempts - 1,
+		})
+	}
+
+	msg, err := s.message(ctx, task)
+	if err != nil {
+		return false, s.fail(ctx, reminder, err)
+	}
+
+	channels := splitChannels(reminder.Channels)
+	if len(channels) == 0 {
+		channels = s.dispatcher.Defaults()
+	}
+	delivered := splitChannels(reminder.Delivered)
+	pending := make([]string, 0, len(channels))
+	for _, ch := range channels {
+		if !slices.Contains(delivered, ch) {
+			pending = append(pending, ch)
+		}
+	}
+
+	ok, sendErr := s.dispatcher.Send(ctx, pending, msg)
+	delivered = append(delivered, ok...)
+	if sendErr != nil {
+		if err := s.reminders.Update(ctx, reminder, map[string]interface{}{"delivered": strings.Join(delivered, ",")}); err != nil {
+			return false, err
+		}
+		return false, s.fail(ctx, reminder, sendErr)
+	}
+	return true, s.reminders.Update(ctx, reminder, map[string]interface{}{
+		"fire_at":    reminder.FireAt, // 恢复领取前的触发时间
+		"sent_at":    now,
+		"delivered":  strings.Join(delivered, ","),
+		"last_error": "",
+	})
+}
+
+// fail 记录发送失败，按尝试次数指数退避后重试，达到最大次数后不再发送
+func (s *ReminderService) fail(ctx context.Context, reminder *model.Reminder, cause error) error {
+	backoff := time.Minute << (reminder.Attempts - 1)
+	if backoff <= 0 || backoff > reminderMaxBackoff {
+		backoff = reminderMaxBackoff
+	}
+	msg := cause.Error()
+	if len(msg) > 512 {
+		msg = msg[:512]
+	}
+	if err := s.reminders.Update(ctx, reminder, map[string]interface{}{
+		"fire_at":    time.Now().UTC().Add(backoff),
+		"last_error": msg,
+	}); err != nil {
+		return err
+	}
+	return cause
+}
+
+// message 生成提醒内容，截止时间按用户时区显示
+func (s *ReminderService) message(ctx context.Context, task *model.Task) (notify.Message, error) {
+	user, err := s.users.Get(ctx, task.UserID)
+	if err != nil {
+		return notify.Message{}, err
+	}
+	loc, err := s.users.Location(ctx, task.UserID)
+	if err != nil {
+		return notify.Message{}, err
+	}
+
+	var body []string
+	if task.Description != "" {
+		body = append(body, task.Description)
+	}
+	if task.DueAt != nil {
+		body = append(body, "截止时间："+task.DueAt.In(loc).Format("2006-01-02 15:04"))
+	}
+	return notify.Message{
+		UserID:     user.ID,
+		Email:      user.Email,
+		Type:       model.NotificationReminder,
+		Title:      "任务提醒：" + task.Event,
+		Body:       strings.Join(body, "\n"),
+		Resource:   "task",
+		ResourceID: task.ID,
+	}, nil
+}
+
+func splitChannels(s string) []string {
+	var channels []string
+	for _, ch := range strings.Split(s, ",") {
+		if ch = strings.TrimSpace(ch); ch != "" {
+			channels = append(channels, ch)
+		}
+	}
+	return channels
+}
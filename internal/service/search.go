@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/PisaListBE/internal/search"
+)
+
+// maxSearchQuery 搜索关键词的最大字符数
+const maxSearchQuery = 100
+
+// SearchService 全文搜索
+type SearchService struct {
+	searcher search.Searcher
+}
+
+// NewSearchService 创建搜索服务
+func NewSearchService(searcher search.Searcher) *SearchService {
+	return &SearchService{searcher: searcher}
+}
+
+// Search 在用户的任务和心愿中搜索 text，community 为 true 时同时搜索社区共享心愿，
+// 结果按相关度排序，最多返回 limit 条
+func (s *SearchService) Search(ctx context.Context, userID uint, text string, community bool, limit int) ([]search.Hit, error) {
+	text = strings.TrimSpace(text)
+	if text == "" || len([]rune(text)) > maxSearchQuery {
+		return nil, ErrInvalidSearchQuery
+	}
+	return s.searcher.Search(ctx, search.Query{UserID: userID, Text: text, Community: community, Limit: limit})
+}
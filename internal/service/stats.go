@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/repository"
+	"github.com/PisaListBE/pkg/recurrence"
+)
+
+// CompletionRate 一段时间内的完成率，Scheduled 为应完成次数
+type CompletionRate struct {
+	Completed int     `json:"completed" example:"5"`
+	Scheduled int     `json:"scheduled" example:"7"`
+	Rate      float64 `json:"rate" example:"0.714"`
+}
+
+// HabitStats 循环任务的连续完成和完成率统计，连续次数按重复出现的次数计算
+type HabitStats struct {
+	TaskID        uint           `json:"task_id" example:"1"`
+	Event         string         `json:"event" example:"背单词"`
+	Recurrence    string         `json:"recurrence" example:"FREQ=DAILY"`
+	CurrentStreak int            `json:"current_streak" example:"3"`
+	LongestStreak int            `json:"longest_streak" example:"12"`
+	Rate7d        CompletionRate `json:"rate_7d"`
+	Rate30d       CompletionRate `json:"rate_30d"`
+	Rate365d      CompletionRate `json:"rate_365d"`
+}
+
+// StatsService 习惯统计
+type StatsService struct {
+	tasks       repository.TaskRepository
+	completions repository.CompletionRepository
+}
+
+// NewStatsService 创建统计服务
+func NewStatsService(tasks repository.TaskRepository, completions repository.CompletionRepository) *StatsService {
+	return &StatsService{tasks: tasks, completions: completions}
+}
+
+// Habits 返回用户每个循环任务的统计，日期按 loc 划分
+func (s *StatsService) Habits(ctx context.Context, userID uint, loc *time.Location) ([]HabitStats, error) {
+	tasks, err := s.tasks.ListRecurring(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+	completions, err := s.completions.ListByTasks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[uint]map[string]bool, len(tasks))
+	for _, c := range completions {
+		if done[c.TaskID] == nil {
+			done[c.TaskID] = make(map[string]bool)
+		}
+		done[c.TaskID][c.OccurrenceDate] = true
+	}
+
+	now := nowIn(loc)
+	result := make([]HabitStats, 0, len(tasks))
+	for i := range tasks {
+		task := &tasks[i]
+		rule, ok := taskRule(task)
+		if !ok {
+			continue
+		}
+		result = append(result, habitStats(task, rule, done[task.ID], now))
+	}
+	return result, nil
+}
+
+// habitStats 从起始日期到今天逐次检查是否完成。
+// 今天这一次尚未完成时不计入连续次数和完成率，避免一天还没结束就中断连续记录。
+func habitStats(task *model.Task, rule *recurrence.Rule, done map[string]bool, now time.Time) HabitStats {
+	stats := HabitStats{TaskID: task.ID, Event: task.Event, Recurrence: task.Recurrence}
+
+	start := recurrenceStart(task, now.Location())
+	today := startOfDay(now)
+	windows := []struct {
+		since time.Time
+		rate  *CompletionRate
+	}{
+		{today.AddDate(0, 0, -6), &stats.Rate7d},
+		{today.AddDate(0, 0, -29), &stats.Rate30d},
+		{today.AddDate(0, 0, -364), &stats.Rate365d},
+	}
+
+	run := 0
+	for day, ok := rule.Next(start, start); ok && !day.After(today); day, ok = rule.Next(start, day.AddDate(0, 0, 1)) {
+		completed := done[day.Format(DateLayout)]
+		if !completed && day.Equal(today) {
+			break
+		}
+
+		if completed {
+			run++
+			if run > stats.LongestStreak {
+				stats.LongestStreak = run
+			}
+		} else {
+			run = 0
+		}
+
+		for _, w := range windows {
+			if day.Before(w.since) {
+				continue
+			}
+			w.rate.Scheduled++
+			if completed {
+				w.rate.Completed++
+			}
+		}
+	}
+	stats.CurrentStreak = run
+
+	for _, w := range windows {
+		if w.rate.Scheduled > 0 {
+			w.rate.Rate = float64(w.rate.Completed) / float64(w.rate.Scheduled)
+		}
+	}
+	return stats
+}
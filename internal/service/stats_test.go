@@ -0,0 +1,163 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+)
+
+func TestHabitStats(t *testing.T) {
+	utc := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	shanghai := time.FixedZone("UTC+8", 8*3600)
+
+	tests := []struct {
+		name       string
+		recurrence string
+		now        time.Time
+		created    time.Time
+		startAt    *time.Time
+		done       []string
+		current    int
+		longest    int
+		rate7d     CompletionRate
+		rate30d    CompletionRate
+		rate365d   CompletionRate
+	}{
+		{
+			name:       "今天未完成不计入",
+			recurrence: "FREQ=DAILY",
+			now:        utc,
+			created:    utc.AddDate(0, 0, -3),
+			done:       dates(utc, -3, -2, -1),
+			current:    3, longest: 3,
+			rate7d:   CompletionRate{3, 3, 1},
+			rate30d:  CompletionRate{3, 3, 1},
+			rate365d: CompletionRate{3, 3, 1},
+		},
+		{
+			name:       "今天已完成计入",
+			recurrence: "FREQ=DAILY",
+			now:        utc,
+			created:    utc.AddDate(0, 0, -3),
+			done:       dates(utc, -3, -2, -1, 0),
+			current:    4, longest: 4,
+			rate7d:   CompletionRate{4, 4, 1},
+			rate30d:  CompletionRate{4, 4, 1},
+			rate365d: CompletionRate{4, 4, 1},
+		},
+		{
+			name:       "漏掉一天后重新计数",
+			recurrence: "FREQ=DAILY",
+			now:        utc,
+			created:    utc.AddDate(0, 0, -5),
+			done:       dates(utc, -5, -4, -3, -1, 0),
+			current:    2, longest: 3,
+			rate7d:   CompletionRate{5, 6, 5.0 / 6},
+			rate30d:  CompletionRate{5, 6, 5.0 / 6},
+			rate365d: CompletionRate{5, 6, 5.0 / 6},
+		},
+		{
+			name:       "昨天漏掉时连续次数为零",
+			recurrence: "FREQ=DAILY",
+			now:        utc,
+			created:    utc.AddDate(0, 0, -5),
+			done:       dates(utc, -5, -4, -3, -2),
+			current:    0, longest: 4,
+			rate7d:   CompletionRate{4, 5, 0.8},
+			rate30d:  CompletionRate{4, 5, 0.8},
+			rate365d: CompletionRate{4, 5, 0.8},
+		},
+		{
+			// 7/30/365 天窗口包含今天，今天未完成时分别覆盖之前 6、29、364 天
+			name:       "统计窗口边界",
+			recurrence: "FREQ=DAILY",
+			now:        utc,
+			created:    utc.AddDate(0, 0, -400),
+			done:       dates(utc, -365, -364, -30, -29, -7, -6),
+			current:    0, longest: 2,
+			rate7d:   CompletionRate{1, 6, 1.0 / 6},
+			rate30d:  CompletionRate{3, 29, 3.0 / 29},
+			rate365d: CompletionRate{5, 364, 5.0 / 364},
+		},
+		{
+			// 每周一次且今天未完成时，7 天窗口内没有计入的次数
+			name:       "窗口内没有应完成的次数",
+			recurrence: "FREQ=WEEKLY;BYDAY=" + weekday(utc),
+			now:        utc,
+			created:    utc.AddDate(0, 0, -21),
+			done:       dates(utc, -21, -14, -7),
+			current:    3, longest: 3,
+			rate7d:   CompletionRate{0, 0, 0},
+			rate30d:  CompletionRate{3, 3, 1},
+			rate365d: CompletionRate{3, 3, 1},
+		},
+		{
+			name:       "开始日期在将来",
+			recurrence: "FREQ=DAILY",
+			now:        utc,
+			created:    utc.AddDate(0, 0, -10),
+			startAt:    timePtr(utc.AddDate(0, 0, 3)),
+			done:       dates(utc, -2, -1),
+		},
+		{
+			// 上海 3 月 10 日 01:00 是 UTC 3 月 9 日 17:00，按 UTC 划分时起始日期和今天都会早一天
+			name:       "按用户时区划分日期",
+			recurrence: "FREQ=DAILY",
+			now:        time.Date(2026, 3, 10, 1, 0, 0, 0, shanghai),
+			created:    time.Date(2026, 3, 8, 20, 0, 0, 0, time.UTC),
+			done:       []string{"2026-03-09", "2026-03-10"},
+			current:    2, longest: 2,
+			rate7d:   CompletionRate{2, 2, 1},
+			rate30d:  CompletionRate{2, 2, 1},
+			rate365d: CompletionRate{2, 2, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &model.Task{Event: "背单词", Recurrence: tt.recurrence, StartAt: tt.startAt}
+			task.CreatedAt = tt.created
+			rule, ok := taskRule(task)
+			if !ok {
+				t.Fatalf("无效的重复规则 %q", tt.recurrence)
+			}
+			done := make(map[string]bool, len(tt.done))
+			for _, d := range tt.done {
+				done[d] = true
+			}
+
+			got := habitStats(task, rule, done, tt.now)
+			if got.CurrentStreak != tt.current || got.LongestStreak != tt.longest {
+				t.Errorf("连续次数 %d/%d, want %d/%d", got.CurrentStreak, got.LongestStreak, tt.current, tt.longest)
+			}
+			for name, pair := range map[string][2]CompletionRate{
+				"7d":   {got.Rate7d, tt.rate7d},
+				"30d":  {got.Rate30d, tt.rate30d},
+				"365d": {got.Rate365d, tt.rate365d},
+			} {
+				if pair[0] != pair[1] {
+					t.Errorf("rate_%s = %+v, want %+v", name, pair[0], pair[1])
+				}
+			}
+		})
+	}
+}
+
+// dates 返回 now 所在日期前后若干天的日期字符串
+func dates(now time.Time, offsets ...int) []string {
+	today := startOfDay(now)
+	list := make([]string, 0, len(offsets))
+	for _, o := range offsets {
+		list = append(list, today.AddDate(0, 0, o).Format(DateLayout))
+	}
+	return list
+}
+
+// weekday 返回 RRULE 中 t 对应的星期
+func weekday(t time.Time) string {
+	return []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}[t.Weekday()]
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
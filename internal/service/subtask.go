@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/repository"
+)
+
+// maxTaskDepth 子任务的最大嵌套层数，防止数据异常时沿父任务无限回溯
+const maxTaskDepth = 32
+
+// SubtaskList 任务的清单项和直接子任务
+type SubtaskList struct {
+	Checklist []model.ChecklistItem `json:"checklist"`
+	Tasks     []model.Task          `json:"tasks"`
+}
+
+// ChecklistInput 创建或更新清单项的参数，为 nil 的字段保持不变
+type ChecklistInput struct {
+	Content   *string
+	Completed *bool
+	// Position 排序位置，创建时为空则排在最后
+	Position *int
+	// Version 客户端期望的所属任务版本，为 nil 时不检查
+	Version *uint
+}
+
+// Subtasks 返回任务的清单项和直接子任务
+func (s *TaskService) Subtasks(ctx context.Context, userID, id uint, loc *time.Location) (*SubtaskList, error) {
+	if _, err := s.get(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	items, err := s.checklist.ListByTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	children, err := s.tasks.ListChildren(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	presentTasks(children, nowIn(loc))
+	return &SubtaskList{Checklist: items, Tasks: children}, nil
+}
+
+// AddChecklistItem 在任务下添加清单项
+func (s *TaskService) AddChecklistItem(ctx context.Context, userID, taskID uint, in ChecklistInput, loc *time.Location) (*model.ChecklistItem, error) {
+	if in.Content == nil || strings.TrimSpace(*in.Content) == "" {
+		return nil, ErrInvalidChecklistItem
+	}
+
+	item := &model.ChecklistItem{TaskID: taskID, Content: *in.Content}
+	now := nowIn(loc)
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		task, err := s.get(ctx, userID, taskID)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(task.Version, in.Version); err != nil {
+			return err
+		}
+		if in.Position != nil {
+			item.Position = *in.Position
+		} else {
+			max, err := s.checklist.MaxPosition(ctx, taskID)
+			if err != nil {
+				return err
+			}
+			item.Position = max + 1
+		}
+		if in.Completed != nil && *in.Completed {
+			item.Completed = true
+			item.CompletedAt = utcPtr(&now)
+		}
+		if err := s.checklist.Create(ctx, item); err != nil {
+			return err
+		}
+		return s.syncParent(ctx, userID, &taskID, now)
+	})
+	if err != nil {
+		return nil, versionError(err)
+	}
+	return item, nil
+}
+
+// UpdateChecklistItem 更新清单项的内容、完成状态或位置
+func (s *TaskService) UpdateChecklistItem(ctx context.Context, userID, taskID, id uint, in ChecklistInput, loc *time.Location) (*model.ChecklistItem, error) {
+	if in.Content != nil && strings.TrimSpace(*in.Content) == "" {
+		return nil, ErrInvalidChecklistItem
+	}
+
+	var item *model.ChecklistItem
+	now := nowIn(loc)
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if item, err = s.getChecklistItem(ctx, userID, taskID, id, in.Version); err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if in.Content != nil {
+			updates["content"] = *in.Content
+		}
+		if in.Position != nil {
+			updates["position"] = *in.Position
+		}
+		if in.Completed != nil && *in.Completed != item.Completed {
+			updates["completed"] = *in.Completed
+			if *in.Completed {
+				updates["completed_at"] = now.UTC()
+			} else {
+				updates["completed_at"] = nil
+			}
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		if err := s.checklist.Update(ctx, item, updates); err != nil {
+			return err
+		}
+		return s.syncParent(ctx, userID, &taskID, now)
+	})
+	if err != nil {
+		return nil, versionError(err)
+	}
+	return item, nil
+}
+
+// DeleteChecklistItem 删除清单项，version 不为 nil 时必须与所属任务的版本一致
+func (s *TaskService) DeleteChecklistItem(ctx context.Context, userID, taskID, id uint, version *uint, loc *time.Location) error {
+	return versionError(s.tx.Transaction(ctx, func(ctx context.Context) error {
+		item, err := s.getChecklistItem(ctx, userID, taskID, id, version)
+		if err != nil {
+			return err
+		}
+		if err := s.checklist.Delete(ctx, item); err != nil {
+			return err
+		}
+		return s.syncParent(ctx, userID, &taskID, nowIn(loc))
+	}))
+}
+
+// getChecklistItem 查询任务的清单项，version 不为 nil 时检查所属任务的版本
+func (s *TaskService) getChecklistItem(ctx context.Context, userID, taskID, id uint, version *uint) (*model.ChecklistItem, error) {
+	task, err := s.get(ctx, userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(task.Version, version); err != nil {
+		return nil, err
+	}
+	item, err := s.checklist.FindByID(ctx, taskID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrChecklistItemNotFound
+	}
+	return item, err
+}
+
+// validateParent 检查 parentID 是否可以作为任务 id 的父任务：必须属于同一用户，且不能是任务自身或它的子孙。
+// 新建任务时 id 为 0。
+func (s *TaskService) validateParent(ctx context.Context, userID, id uint, parentID *uint) error {
+	for depth := 0; parentID != nil; depth++ {
+		if depth >= maxTaskDepth || *parentID == id {
+			return ErrInvalidParent
+		}
+		parent, err := s.get(ctx, userID, *parentID)
+		if errors.Is(err, ErrTaskNotFound) {
+			return ErrInvalidParent
+		}
+		if err != nil {
+			return err
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+// syncParent 从 parentID 开始逐级向上，按子任务和清单项的完成情况更新开启了自动完成的任务。
+// 在子任务或清单项的完成状态、数量变化之后调用。没有子任务和清单项的任务保持手动设置的完成状态；
+// 循环任务每一次都需要手动完成，不参与自动完成。
+func (s *TaskService) syncParent(ctx context.Context, userID uint, parentID *uint, now time.Time) error {
+	for depth := 0; parentID != nil && depth < maxTaskDepth; depth++ {
+		parent, err := s.get(ctx, userID, *parentID)
+		if errors.Is(err, ErrTaskNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, recurring := taskRule(parent); parent.AutoComplete && !recurring {
+			done, has, err := s.subtasksDone(ctx, userID, parent.ID, now)
+			if err != nil {
+				return err
+			}
+			if has && done != parent.Completed {
+				if err := s.setCompleted(ctx, parent, done, now); err != nil {
+					return err
+				}
+			}
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+// subtasksDone 返回任务的子任务和清单项是否全部已完成，has 为 false 表示任务没有子任务和清单项
+func (s *TaskService) subtasksDone(ctx context.Context, userID, id uint, now time.Time) (done, has bool, err error) {
+	items, err := s.checklist.ListByTask(ctx, id)
+	if err != nil {
+		return false, false, err
+	}
+	children, err := s.tasks.ListChildren(ctx, userID, id)
+	if err != nil {
+		return false, false, err
+	}
+	if len(items)+len(children) == 0 {
+		return false, false, nil
+	}
+
+	for _, item := range items {
+		if !item.Completed {
+			return false, true, nil
+		}
+	}
+	presentTasks(children, now)
+	for _, child := range children {
+		if !child.Completed {
+			return false, true, nil
+		}
+	}
+	return true, true, nil
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/repository"
+	"github.com/PisaListBE/pkg/pagination"
+)
+
+// 同步的记录类型
+const (
+	SyncTask = "task"
+	SyncWish = "wish"
+	SyncList = "list"
+	SyncTag  = "tag"
+)
+
+// syncOverlap 增量同步时从游标往前回看的时长，避免漏掉上次同步时尚未提交、更新时间早于游标的事务。
+// 回看范围内的记录会重复返回，客户端按 ID 和 version 覆盖即可
+const syncOverlap = time.Minute
+
+// SyncDeleted 游标之后删除的记录ID，包括移入回收站的任务和心愿
+type SyncDeleted struct {
+	Tasks  []uint `json:"tasks"`
+	Wishes []uint `json:"wishes"`
+	Lists  []uint `json:"lists"`
+	Tags   []uint `json:"tags"`
+}
+
+// SyncChanges 游标之后创建、修改和删除的记录
+type SyncChanges struct {
+	Tasks   []model.Task `json:"tasks"`
+	Wishes  []model.Wish `json:"wishes"`
+	Lists   []model.List `json:"lists"`
+	Tags    []model.Tag  `json:"tags"`
+	Deleted SyncDeleted  `json:"deleted"`
+	// Cursor 下次同步时传入的游标
+	Cursor string `json:"cursor" example:"eyJ0IjoiMjAyNS0wMS0xMFQwNzowNDowNVoifQ"`
+	// Reset 为 true 时返回的是全部数据且不含删除记录，客户端应清空本地数据后重建。
+	// 首次同步或游标早于回收站保留期限（之后删除的记录可能已被彻底清除）时出现
+	Reset bool `json:"reset" example:"false"`
+}
+
+// syncCursor 同步游标，记录上次同步开始的时间
+type syncCursor struct {
+	Since time.Time `json:"t"`
+}
+
+// SyncService 离线客户端的增量同步
+type SyncService struct {
+	tasks  repository.TaskRepository
+	wishes repository.WishRepository
+	lists  repository.ListRepository
+	tags   repository.TagRepository
+	// retention 回收站保留时长，早于此的游标无法拿到期间被彻底删除的记录
+	retention time.Duration
+}
+
+// NewSyncService 创建同步服务，retention 为回收站中数据的保留时长
+func NewSyncService(tasks repository.TaskRepository, wishes repository.WishRepository, lists repository.ListRepository, tags repository.TagRepository, retention time.Duration) *SyncService {
+	return &SyncService{tasks: tasks, wishes: wishes, lists: lists, tags: tags, retention: retention}
+}
+
+// Changes 返回 cursor 之后创建、修改或删除的任务、心愿、清单和标签以及新的游标。
+// cursor 为空或早于回收站保留期限时返回全部数据并设置 Reset；格式错误时返回 pagination.ErrInvalidCursor
+func (s *SyncService) Changes(ctx context.Context, userID uint, cursor string, loc *time.Location) (*SyncChanges, error) {
+	started := time.Now().UTC()
+	var since time.Time
+	if cursor != "" {
+		var cur syncCursor
+		if err := pagination.Decode(cursor, &cur); err != nil {
+			return nil, err
+		}
+		if cur.Since.IsZero() {
+			return nil, pagination.ErrInvalidCursor
+		}
+		if cur.Since.After(started.Add(-s.retention)) {
+			since = cur.Since.Add(-syncOverlap)
+		}
+	}
+
+	changes := &SyncChanges{
+		Tasks:   []model.Task{},
+		Wishes:  []model.Wish{},
+		Lists:   []model.List{},
+		Tags:    []model.Tag{},
+		Deleted: SyncDeleted{Tasks: []uint{}, Wishes: []uint{}, Lists: []uint{}, Tags: []uint{}},
+		Cursor:  pagination.Encode(syncCursor{Since: started}),
+		Reset:   since.IsZero(),
+	}
+
+	tasks, err := s.tasks.ListChangedSince(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		if task.DeletedAt.Valid {
+			changes.Deleted.Tasks = append(changes.Deleted.Tasks, task.ID)
+		} else {
+			changes.Tasks = append(changes.Tasks, task)
+		}
+	}
+	presentTasks(changes.Tasks, started.In(loc))
+
+	wishes, err := s.wishes.ListChangedSince(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, wish := range wishes {
+		if wish.DeletedAt.Valid {
+			changes.Deleted.Wishes = append(changes.Deleted.Wishes, wish.ID)
+		} else {
+			changes.Wishes = append(changes.Wishes, wish)
+		}
+	}
+
+	lists, err := s.lists.ListChangedSince(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range lists {
+		if list.DeletedAt.Valid {
+			changes.Deleted.Lists = append(changes.Deleted.Lists, list.ID)
+		} else {
+			changes.Lists = append(changes.Lists, list)
+		}
+	}
+
+	tags, err := s.tags.ListChangedSince(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		if tag.DeletedAt.Valid {
+			changes.Deleted.Tags = append(changes.Deleted.Tags, tag.ID)
+		} else {
+			changes.Tags = append(changes.Tags, tag)
+		}
+	}
+	return changes, nil
+}
+
+// Resolve 返回客户端ID对应记录的服务端ID，记录不存在时返回对应类型的 NotFound 错误
+func (s *SyncService) Resolve(ctx context.Context, userID uint, typ, clientID string) (uint, error) {
+	switch typ {
+	case SyncTask:
+		task, err := s.tasks.FindByClientID(ctx, userID, clientID)
+		if err != nil {
+			return 0, notFoundAs(err, ErrTaskNotFound)
+		}
+		return task.ID, nil
+	case SyncWish:
+		wish, err := s.wishes.FindByClientID(ctx, userID, clientID)
+		if err != nil {
+			return 0, notFoundAs(err, ErrWishNotFound)
+		}
+		return wish.ID, nil
+	case SyncList:
+		list, err := s.lists.FindByClientID(ctx, userID, clientID)
+		if err != nil {
+			return 0, notFoundAs(err, ErrListNotFound)
+		}
+		return list.ID, nil
+	case SyncTag:
+		tag, err := s.tags.FindByClientID(ctx, userID, clientID)
+		if err != nil {
+			return 0, notFoundAs(err, ErrTagNotFound)
+		}
+		return tag.ID, nil
+	}
+	return 0, ErrInvalidSyncType
+}
+
+// notFoundAs 把仓储层的 ErrNotFound 转换为 target
+func notFoundAs(err, target error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return target
+	}
+	return err
+}
+
+// clientID 返回写入数据库的客户端ID，为空时写入 NULL，不受唯一索引限制
+func clientID(id string) *string {
+	if id == "" {
+		return nil
+	}
+	return &id
+}
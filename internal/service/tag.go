@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/repository"
+)
+
+// TagInput 创建或更新标签的参数
+type TagInput struct {
+	Name string
+	// Color 十六进制颜色，如 #FF8800，可为空
+	Color string
+	// ClientID 离线客户端生成的ID，只在创建时使用，同一用户内重复创建时返回已有的标签，已有的标签已删除时返回 ErrTagNotFound
+	ClientID string
+}
+
+// TagService 标签相关业务逻辑
+type TagService struct {
+	tags repository.TagRepository
+}
+
+// NewTagService 创建标签服务
+func NewTagService(tags repository.TagRepository) *TagService {
+	return &TagService{tags: tags}
+}
+
+func (s *TagService) get(ctx context.Context, userID, id uint) (*model.Tag, error) {
+	tag, err := s.tags.FindByID(ctx, userID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrTagNotFound
+	}
+	return tag, err
+}
+
+// checkName 校验标签名称，同一用户下不能重名。更新时 id 为被更新的标签
+func (s *TagService) checkName(ctx context.Context, userID, id uint, name string) error {
+	if name == "" {
+		return ErrInvalidTagName
+	}
+	existing, err := s.tags.FindByName(ctx, userID, name)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != id {
+		return ErrTagExists
+	}
+	return nil
+}
+
+// Get 返回标签详情
+func (s *TagService) Get(ctx context.Context, userID, id uint) (*model.Tag, error) {
+	return s.get(ctx, userID, id)
+}
+
+// List 返回用户的所有标签
+func (s *TagService) List(ctx context.Context, userID uint) ([]model.Tag, error) {
+	return s.tags.ListByUser(ctx, userID)
+}
+
+// Create 创建标签
+func (s *TagService) Create(ctx context.Context, userID uint, in TagInput) (*model.Tag, error) {
+	if in.ClientID != "" {
+		existing, err := s.tags.FindByClientID(ctx, userID, in.ClientID)
+		if err == nil && existing.DeletedAt.Valid {
+			// 同一个 client_id 创建的记录已被删除，不再重复创建
+			return nil, ErrTagNotFound
+		}
+		if err == nil {
+			return existing, nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+	}
+	name := strings.TrimSpace(in.Name)
+	if err := s.checkName(ctx, userID, 0, name); err != nil {
+		return nil, err
+	}
+
+	tag := &model.Tag{UserID: userID, Name: name, Color: strings.ToUpper(in.Color), ClientID: clientID(in.ClientID)}
+	if err := s.tags.Create(ctx, tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// Update 更新标签名称和颜色
+func (s *TagService) Update(ctx context.Context, userID, id uint, in TagInput) (*model.Tag, error) {
+	tag, err := s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(in.Name)
+	if err := s.checkName(ctx, userID, id, name); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"name":  name,
+		"color": strings.ToUpper(in.Color),
+	}
+	if err := s.tags.Update(ctx, tag, updates); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// Delete 删除标签，已打上该标签的任务和心愿会移除这个标签
+func (s *TagService) Delete(ctx context.Context, userID, id uint) error {
+	tag, err := s.get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	return s.tags.Delete(ctx, tag)
+}
+
+// resolveTags 查询 ids 对应的标签，任一标签不属于用户时返回 ErrTagNotFound
+func resolveTags(ctx context.Context, tags repository.TagRepository, userID uint, ids []uint) ([]model.Tag, error) {
+	if len(ids) == 0 {
+		return []model.Tag{}, nil
+	}
+	unique := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		unique[id] = struct{}{}
+	}
+	found, err := tags.FindByIDs(ctx, userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) != len(unique) {
+		return nil, ErrTagNotFound
+	}
+	return found, nil
+}
+
+// matchTag 返回按标签名称筛选的判断函数，name 为空时全部匹配，用户没有该标签时全部不匹配
+func matchTag(ctx context.Context, tags repository.TagRepository, userID uint, name string) (func([]model.Tag) bool, error) {
+	if name == "" {
+		return func([]model.Tag) bool { return true }, nil
+	}
+	tag, err := tags.FindByName(ctx, userID, name)
+	if errors.Is(err, repository.ErrNotFound) {
+		return func([]model.Tag) bool { return false }, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func(attached []model.Tag) bool {
+		for _, t := range attached {
+			if t.ID == tag.ID {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
@@ -0,0 +1,688 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/repository"
+	"github.com/PisaListBE/pkg/pagination"
+)
+
+// TaskInput 创建或更新任务的参数
+type TaskInput struct {
+	Event           string
+	Description     string
+	IsCycle         bool
+	ImportanceLevel int
+	// Recurrence 重复规则，为空且 IsCycle 为 true 时按每天重复
+	Recurrence string
+	// StartAt 开始时间，之前不出现在今日任务中
+	StartAt *time.Time
+	// DueAt 截止时间
+	DueAt *time.Time
+	// ParentID 父任务，为空时是顶层任务
+	ParentID *uint
+	// AutoComplete 所有子任务和清单项完成后自动完成
+	AutoComplete bool
+	// TagIDs 任务的标签，更新时为 nil 表示保持不变
+	TagIDs []uint
+	// ListID 任务所属清单，为空时不属于任何清单；创建子任务时为空则沿用父任务的清单
+	ListID *uint
+	// Version 更新时期望的当前版本号（If-Match），为 nil 时不校验
+	Version *uint
+	// ClientID 离线客户端生成的ID，只在创建时使用，同一用户内重复创建时返回已有的任务，已有的任务已删除时返回 ErrTaskNotFound
+	ClientID string
+}
+
+// TaskFilter 任务列表的筛选条件
+type TaskFilter struct {
+	// Tag 标签名称，为空时不筛选
+	Tag string
+	// ListID 清单ID，为 nil 时不筛选，为 0 时只返回不属于任何清单的任务
+	ListID *uint
+}
+
+func (in TaskInput) validate() error {
+	if in.StartAt != nil && in.DueAt != nil && in.DueAt.Before(*in.StartAt) {
+		return ErrInvalidTaskDates
+	}
+	return nil
+}
+
+// TimelineEntry 时间线中的一次完成，CompletedDate 为这一次的完成时间
+type TimelineEntry struct {
+	model.Task
+	OccurrenceDate string `json:"occurrence_date" example:"2025-01-10"`
+}
+
+// CompletionCalendar 任务在一段日期内的完成记录
+type CompletionCalendar struct {
+	TaskID      uint                   `json:"task_id" example:"1"`
+	From        string                 `json:"from" example:"2025-01-01"`
+	To          string                 `json:"to" example:"2025-01-31"`
+	Completions []model.TaskCompletion `json:"completions"`
+}
+
+// TaskService 任务相关业务逻辑
+type TaskService struct {
+	tx          repository.Transactor
+	tasks       repository.TaskRepository
+	completions repository.CompletionRepository
+	reminders   repository.ReminderRepository
+	checklist   repository.ChecklistRepository
+	tags        repository.TagRepository
+	lists       repository.ListRepository
+}
+
+// NewTaskService 创建任务服务
+func NewTaskService(tx repository.Transactor, tasks repository.TaskRepository, completions repository.CompletionRepository, reminders repository.ReminderRepository, checklist repository.ChecklistRepository, tags repository.TagRepository, lists repository.ListRepository) *TaskService {
+	return &TaskService{tx: tx, tasks: tasks, completions: completions, reminders: reminders, checklist: checklist, tags: tags, lists: lists}
+}
+
+func (s *TaskService) get(ctx context.Context, userID, id uint) (*model.Task, error) {
+	task, err := s.tasks.FindByID(ctx, userID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrTaskNotFound
+	}
+	return task, err
+}
+
+// checkList 检查清单属于该用户
+func (s *TaskService) checkList(ctx context.Context, userID uint, listID *uint) error {
+	if listID == nil {
+		return nil
+	}
+	_, err := s.lists.FindByID(ctx, userID, *listID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrListNotFound
+	}
+	return err
+}
+
+// matcher 返回按 f 筛选任务的判断函数，任务需要已加载标签
+func (s *TaskService) matcher(ctx context.Context, userID uint, f TaskFilter) (func(*model.Task) bool, error) {
+	matchTags, err := matchTag(ctx, s.tags, userID, f.Tag)
+	if err != nil {
+		return nil, err
+	}
+	return func(task *model.Task) bool {
+		if f.ListID != nil {
+			if *f.ListID == 0 && task.ListID != nil {
+				return false
+			}
+			if *f.ListID != 0 && (task.ListID == nil || *task.ListID != *f.ListID) {
+				return false
+			}
+		}
+		return matchTags(task.Tags)
+	}, nil
+}
+
+// filterTasks 返回 tasks 中满足 match 的任务
+func filterTasks(tasks []model.Task, match func(*model.Task) bool) []model.Task {
+	filtered := make([]model.Task, 0, len(tasks))
+	for i := range tasks {
+		if match(&tasks[i]) {
+			filtered = append(filtered, tasks[i])
+		}
+	}
+	return filtered
+}
+
+// Create 创建任务
+func (s *TaskService) Create(ctx context.Context, userID uint, in TaskInput, loc *time.Location) (*model.Task, error) {
+	if in.ClientID != "" {
+		existing, err := s.tasks.FindByClientID(ctx, userID, in.ClientID)
+		if err == nil && existing.DeletedAt.Valid {
+			// 同一个 client_id 创建的记录已被删除，不再重复创建
+			return nil, ErrTaskNotFound
+		}
+		if err == nil {
+			presentTask(existing, nowIn(loc))
+			return existing, nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+	}
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	rule, err := resolveRecurrence(in, "")
+	if err != nil {
+		return nil, err
+	}
+
+	task := &model.Task{
+		Version:         1,
+		UserID:          userID,
+		Event:           in.Event,
+		Description:     in.Description,
+		IsCycle:         rule != "",
+		ImportanceLevel: in.ImportanceLevel,
+		Recurrence:      rule,
+		StartAt:         utcPtr(in.StartAt),
+		DueAt:           utcPtr(in.DueAt),
+		ParentID:        in.ParentID,
+		AutoComplete:    in.AutoComplete,
+		ListID:          in.ListID,
+		ClientID:        clientID(in.ClientID),
+	}
+	now := nowIn(loc)
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.validateParent(ctx, userID, 0, in.ParentID); err != nil {
+			return err
+		}
+		if task.ListID == nil && in.ParentID != nil {
+			parent, err := s.get(ctx, userID, *in.ParentID)
+			if err != nil {
+				return err
+			}
+			task.ListID = parent.ListID
+		}
+		if err := s.checkList(ctx, userID, task.ListID); err != nil {
+			return err
+		}
+		tags, err := resolveTags(ctx, s.tags, userID, in.TagIDs)
+		if err != nil {
+			return err
+		}
+		task.Tags = tags
+		// 新任务排在最后
+		if task.Position, err = s.nextPosition(ctx, userID); err != nil {
+			return err
+		}
+		if err := s.tasks.Create(ctx, task); err != nil {
+			return err
+		}
+		// 新增未完成的子任务后，已自动完成的父任务需要恢复为未完成
+		return s.syncParent(ctx, userID, task.ParentID, now)
+	})
+	if err != nil {
+		return nil, versionError(err)
+	}
+	presentTask(task, now)
+	return task, nil
+}
+
+// Update 更新任务的内容、描述、循环规则和开始/截止时间
+func (s *TaskService) Update(ctx context.Context, userID, id uint, in TaskInput, loc *time.Location) (*model.Task, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	task, err := s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(task.Version, in.Version); err != nil {
+		return nil, err
+	}
+
+	rule, err := resolveRecurrence(in, task.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	// 整体替换任务内容，为 nil 的时间、父任务和清单表示清除；只修改部分字段时由调用方先合并（PATCH）
+	updates := map[string]interface{}{
+		"event":            in.Event,
+		"description":      in.Description,
+		"is_cycle":         rule != "",
+		"recurrence":       rule,
+		"start_at":         utcPtr(in.StartAt),
+		"due_at":           utcPtr(in.DueAt),
+		"parent_id":        in.ParentID,
+		"list_id":          in.ListID,
+		"importance_level": in.ImportanceLevel,
+		"auto_complete":    in.AutoComplete,
+	}
+	oldParent := task.ParentID
+	moved := !sameID(oldParent, in.ParentID)
+	// 只在刚开启自动完成时按子任务重新判断，编辑其他字段不改变手动设置的完成状态
+	enabled := in.AutoComplete && !task.AutoComplete
+	now := nowIn(loc)
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.validateParent(ctx, userID, task.ID, in.ParentID); err != nil {
+			return err
+		}
+		if err := s.checkList(ctx, userID, in.ListID); err != nil {
+			return err
+		}
+		if err := s.tasks.Update(ctx, task, updates); err != nil {
+			return err
+		}
+		if in.TagIDs != nil {
+			tags, err := resolveTags(ctx, s.tags, userID, in.TagIDs)
+			if err != nil {
+				return err
+			}
+			if err := s.tasks.SetTags(ctx, task, tags); err != nil {
+				return err
+			}
+		}
+		// 截止时间可能变化，重新计算相对提醒的触发时间
+		if err := rescheduleReminders(ctx, s.reminders, task); err != nil {
+			return err
+		}
+		// 任务移动后新旧父任务的子任务都有变化，完成状态需要重新判断
+		if moved {
+			if err := s.syncParent(ctx, userID, oldParent, now); err != nil {
+				return err
+			}
+			if err := s.syncParent(ctx, userID, in.ParentID, now); err != nil {
+				return err
+			}
+		}
+		if enabled {
+			return s.syncParent(ctx, userID, &task.ID, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, versionError(err)
+	}
+
+	task, err = s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	presentTask(task, now)
+	return task, nil
+}
+
+// Get 返回任务详情
+func (s *TaskService) Get(ctx context.Context, userID, id uint, loc *time.Location) (*model.Task, error) {
+	task, err := s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	presentTask(task, nowIn(loc))
+	return task, nil
+}
+
+// GetDeleted 获取回收站中的任务，不在回收站时返回 ErrTaskNotInTrash
+func (s *TaskService) GetDeleted(ctx context.Context, userID, id uint, loc *time.Location) (*model.Task, error) {
+	task, err := s.tasks.FindDeleted(ctx, userID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrTaskNotInTrash
+	}
+	if err != nil {
+		return nil, err
+	}
+	presentTask(task, nowIn(loc))
+	return task, nil
+}
+
+// Delete 删除任务，子任务一起进入回收站。version 为期望的当前版本号（If-Match），为 nil 时不校验
+func (s *TaskService) Delete(ctx context.Context, userID, id uint, version *uint, loc *time.Location) error {
+	task, err := s.get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(task.Version, version); err != nil {
+		return err
+	}
+	return versionError(s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.tasks.Delete(ctx, task); err != nil {
+			return err
+		}
+		// 剩下的子任务可能已经全部完成
+		return s.syncParent(ctx, userID, task.ParentID, nowIn(loc))
+	}))
+}
+
+// ToggleComplete 切换任务完成状态，返回更新后的任务。
+// 每次完成都会写入一条完成记录；循环任务只切换当前这一次的完成状态，下一次到期时会重新变为未完成。
+// 完成记录所属的日期按 loc 计算。
+func (s *TaskService) ToggleComplete(ctx context.Context, userID, id uint, version *uint, loc *time.Location) (*model.Task, error) {
+	task, err := s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(task.Version, version); err != nil {
+		return nil, err
+	}
+
+	now := nowIn(loc)
+	current := *task
+	presentTask(&current, now)
+
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.setCompleted(ctx, task, !current.Completed, now); err != nil {
+			return err
+		}
+		return s.syncParent(ctx, userID, task.ParentID, now)
+	})
+	if err != nil {
+		return nil, versionError(err)
+	}
+
+	// 重新获取更新后的任务信息
+	task, err = s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	presentTask(task, now)
+	return task, nil
+}
+
+// setCompleted 将任务在 now 所属的这一次标记为完成或未完成，并写入或删除对应的完成记录。
+// task 为数据库中读取的原始任务，尚未经过 presentTask 处理。
+func (s *TaskService) setCompleted(ctx context.Context, task *model.Task, completed bool, now time.Time) error {
+	date := occurrenceDate(task, now)
+	_, recurring := taskRule(task)
+
+	updates := map[string]interface{}{
+		"completed": completed,
+	}
+	if completed {
+		updates["completed_date"] = now.UTC()
+	} else {
+		updates["completed_date"] = nil // 取消完成时清空完成日期
+	}
+
+	return s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.tasks.Update(ctx, task, updates); err != nil {
+			return err
+		}
+		// 非循环任务只有一条完成记录，其日期可能按旧的时区计算（例如迁移 0004 按服务器本地时间生成），
+		// 因此整体删除而不按日期匹配
+		var err error
+		if recurring {
+			err = s.completions.Delete(ctx, task.ID, date)
+		} else {
+			err = s.completions.DeleteByTask(ctx, task.ID)
+		}
+		if err != nil {
+			return err
+		}
+		if !completed {
+			return nil
+		}
+		return s.completions.Create(ctx, &model.TaskCompletion{
+			TaskID:         task.ID,
+			UserID:         task.UserID,
+			OccurrenceDate: date,
+			CompletedAt:    now.UTC(),
+		})
+	})
+}
+
+// Completions 返回任务在 [from, to] 日期范围内的完成记录，日期格式为 YYYY-MM-DD
+func (s *TaskService) Completions(ctx context.Context, userID, id uint, from, to string) (*CompletionCalendar, error) {
+	if _, err := s.get(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	completions, err := s.completions.ListByTask(ctx, id, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &CompletionCalendar{TaskID: id, From: from, To: to, Completions: completions}, nil
+}
+
+// Timeline 按日期倒序分页返回 [q.From, q.To] 内的完成记录，循环任务的每一次完成各占一条。
+// 未指定日期范围时返回最近7天（含今天，按 loc 划分日期）。
+func (s *TaskService) Timeline(ctx context.Context, userID uint, q TimelineQuery, loc *time.Location) (*TimelinePage, error) {
+	q = q.withDefaults(loc)
+	filter := repository.CompletionFilter{From: q.From, To: q.To, Limit: q.Limit + 1}
+	if q.Cursor != "" {
+		var cur timelineCursor
+		if err := pagination.Decode(q.Cursor, &cur); err != nil {
+			return nil, err
+		}
+		filter.AfterDate, filter.AfterID = cur.Date, cur.ID
+	}
+
+	completions, err := s.completions.ListByUser(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// 多查一条用于判断是否还有下一页
+	page := &TimelinePage{}
+	if len(completions) > q.Limit {
+		completions = completions[:q.Limit]
+		last := completions[len(completions)-1]
+		page.NextCursor = pagination.Encode(timelineCursor{Date: last.OccurrenceDate, ID: last.ID})
+	}
+	page.Items, err = s.timelineEntries(ctx, userID, completions)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// TimelineGroups 返回 [q.From, q.To] 内按天、周或月分组的完成数量，按时间倒序，不分页
+func (s *TaskService) TimelineGroups(ctx context.Context, userID uint, q TimelineQuery, groupBy string, loc *time.Location) ([]TimelineBucket, error) {
+	q = q.withDefaults(loc)
+	counts, err := s.completions.CountByDay(ctx, userID, q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+	return groupTimeline(counts, groupBy)
+}
+
+// timelineEntries 将完成记录与任务关联，已删除任务的记录会被跳过
+func (s *TaskService) timelineEntries(ctx context.Context, userID uint, completions []model.TaskCompletion) ([]TimelineEntry, error) {
+	ids := make([]uint, 0, len(completions))
+	seen := make(map[uint]bool, len(completions))
+	for _, c := range completions {
+		if !seen[c.TaskID] {
+			seen[c.TaskID] = true
+			ids = append(ids, c.TaskID)
+		}
+	}
+
+	tasks, err := s.tasks.FindByIDs(ctx, userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]model.Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+
+	entries := make([]TimelineEntry, 0, len(completions))
+	for _, c := range completions {
+		task, ok := byID[c.TaskID]
+		if !ok {
+			continue
+		}
+		task.Completed = true
+		task.CompletedDate = c.CompletedAt
+		entries = append(entries, TimelineEntry{Task: task, OccurrenceDate: c.OccurrenceDate})
+	}
+	return entries, nil
+}
+
+// Today 返回今天需要展示的任务：已开始且未完成的、今天完成的以及今天需要重复的循环任务。
+// 默认逾期任务排在最前面，其余按 position 排序；指定 by 时完全按 by 排序。“今天”按 loc 划分，按 f 筛选。
+func (s *TaskService) Today(ctx context.Context, userID uint, f TaskFilter, by TaskSort, loc *time.Location) ([]model.Task, error) {
+	match, err := s.matcher(ctx, userID, f)
+	if err != nil {
+		return nil, err
+	}
+	now := nowIn(loc)
+	todayStart := startOfDay(now)
+	candidates, err := s.tasks.ListForDay(ctx, userID, todayStart.UTC(), todayStart.AddDate(0, 0, 1).UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]model.Task, 0, len(candidates))
+	for _, task := range candidates {
+		if !match(&task) {
+			continue
+		}
+		if rule, ok := taskRule(&task); ok && !rule.Occurs(recurrenceStart(&task, now.Location()), now) {
+			continue // 今天不是这个循环任务的重复日
+		}
+		presentTask(&task, now)
+		tasks = append(tasks, task)
+	}
+
+	if by.Field != "" {
+		sortTasks(tasks, by)
+		return tasks, nil
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return isOverdue(&tasks[i], now) && !isOverdue(&tasks[j], now)
+	})
+	return tasks, nil
+}
+
+// Upcoming 返回未来 days 天内到期的未完成任务
+func (s *TaskService) Upcoming(ctx context.Context, userID uint, days int, f TaskFilter, loc *time.Location) ([]model.Task, error) {
+	match, err := s.matcher(ctx, userID, f)
+	if err != nil {
+		return nil, err
+	}
+	now := nowIn(loc)
+	all, err := s.tasks.ListDueBetween(ctx, userID, now.UTC(), now.AddDate(0, 0, days).UTC())
+	if err != nil {
+		return nil, err
+	}
+	tasks := filterTasks(all, match)
+	presentTasks(tasks, now)
+	return tasks, nil
+}
+
+// Overdue 返回已过截止时间的未完成任务
+func (s *TaskService) Overdue(ctx context.Context, userID uint, f TaskFilter, loc *time.Location) ([]model.Task, error) {
+	match, err := s.matcher(ctx, userID, f)
+	if err != nil {
+		return nil, err
+	}
+	now := nowIn(loc)
+	all, err := s.tasks.ListOverdue(ctx, userID, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	tasks := filterTasks(all, match)
+	presentTasks(tasks, now)
+	return tasks, nil
+}
+
+// isOverdue 判断任务是否已过截止时间且未完成
+func isOverdue(task *model.Task, now time.Time) bool {
+	return !task.Completed && task.DueAt != nil && task.DueAt.Before(now)
+}
+
+// ImportanceInput 批量更新重要性时单个任务的参数
+type ImportanceInput struct {
+	ID    uint
+	Level int
+	// Version 客户端期望的任务版本，为 nil 时不检查
+	Version *uint
+}
+
+// UpdateImportance 在一个事务内批量更新重要性，任一任务不存在或版本不一致时整体回滚，
+// 版本不一致时返回 *BatchVersionError
+func (s *TaskService) UpdateImportance(ctx context.Context, userID uint, items []ImportanceInput) error {
+	return s.tx.Transaction(ctx, func(ctx context.Context) error {
+		for _, item := range items {
+			task, err := s.get(ctx, userID, item.ID)
+			if err != nil {
+				return err
+			}
+			if err := checkVersion(task.Version, item.Version); err != nil {
+				return &BatchVersionError{ID: item.ID}
+			}
+			err = s.tasks.Update(ctx, task, map[string]interface{}{"importance_level": item.Level})
+			if errors.Is(err, repository.ErrConflict) {
+				return &BatchVersionError{ID: item.ID}
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Restore 从回收站恢复任务及与它一起删除的子任务。父任务仍在回收站时，恢复为顶层任务；所属清单已删除时移出清单。
+// version 不为 nil 时必须与回收站中任务的版本一致
+func (s *TaskService) Restore(ctx context.Context, userID, id uint, version *uint, loc *time.Location) (*model.Task, error) {
+	now := nowIn(loc)
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.tasks.Restore(ctx, userID, id, version); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrTaskNotInTrash
+			}
+			return err
+		}
+		task, err := s.get(ctx, userID, id)
+		if err != nil {
+			return err
+		}
+		if task.ListID != nil {
+			// 所属清单已删除时，把恢复的任务及一起恢复的子任务移出该清单
+			if err := s.checkList(ctx, userID, task.ListID); errors.Is(err, ErrListNotFound) {
+				if err := s.leaveList(ctx, userID, task, *task.ListID); err != nil {
+					return err
+				}
+			} else if err != nil {
+				return err
+			}
+		}
+		if task.ParentID == nil {
+			return nil
+		}
+		if _, err := s.get(ctx, userID, *task.ParentID); errors.Is(err, ErrTaskNotFound) {
+			return s.tasks.Update(ctx, task, map[string]interface{}{"parent_id": nil})
+		} else if err != nil {
+			return err
+		}
+		return s.syncParent(ctx, userID, task.ParentID, now)
+	})
+	if err != nil {
+		return nil, versionError(err)
+	}
+
+	task, err := s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	presentTask(task, now)
+	return task, nil
+}
+
+// leaveList 将 task 及其未删除的子任务中属于清单 listID 的移出清单，其他任务不受影响
+func (s *TaskService) leaveList(ctx context.Context, userID uint, task *model.Task, listID uint) error {
+	if err := s.tasks.Update(ctx, task, map[string]interface{}{"list_id": nil}); err != nil {
+		return err
+	}
+	task.ListID = nil
+	for parents := []uint{task.ID}; len(parents) > 0; {
+		var next []uint
+		for _, parentID := range parents {
+			children, err := s.tasks.ListChildren(ctx, userID, parentID)
+			if err != nil {
+				return err
+			}
+			for i := range children {
+				child := &children[i]
+				if child.ListID != nil && *child.ListID == listID {
+					if err := s.tasks.Update(ctx, child, map[string]interface{}{"list_id": nil}); err != nil {
+						return err
+					}
+				}
+				next = append(next, child.ID)
+			}
+		}
+		parents = next
+	}
+	return nil
+}
+
+// sameID 两个可为空的ID是否相同
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/repository"
+	"github.com/PisaListBE/pkg/pagination"
+)
+
+// TaskListQuery 任务列表查询参数，为 nil 或零值的筛选条件不生效。日期格式为 YYYY-MM-DD 且包含两端
+type TaskListQuery struct {
+	// Completed 对循环任务表示今天是否已完成
+	Completed     *bool
+	IsCycle       *bool
+	MinImportance *int
+	MaxImportance *int
+	// ListID 为 0 时只返回不属于任何清单的任务
+	ListID *uint
+	// Tag 标签名称
+	Tag           string
+	CreatedFrom   string
+	CreatedTo     string
+	CompletedFrom string
+	CompletedTo   string
+	// Text 在内容和描述中搜索
+	Text string
+	Sort TaskSort
+	// Cursor 上一页返回的 next_cursor，为空时返回第一页
+	Cursor string
+	Limit  int
+}
+
+// TaskPage 任务列表的一页，NextCursor 为空表示没有更多记录，Total 为满足筛选条件的任务总数
+type TaskPage struct {
+	Items      []model.Task `json:"items"`
+	NextCursor string       `json:"next_cursor" example:"eyJzIjoicG9zaXRpb24iLCJwIjoiaSIsImlkIjo0Mn0"`
+	Total      int64        `json:"total" example:"42"`
+}
+
+// taskCursor 任务列表游标，记录上一页最后一个任务的排序键。排序方式变化后旧游标无效
+type taskCursor struct {
+	Sort       string     `json:"s"`
+	Desc       bool       `json:"d,omitempty"`
+	Position   string     `json:"p,omitempty"`
+	Importance int        `json:"i,omitempty"`
+	Time       *time.Time `json:"t,omitempty"`
+	ID         uint       `json:"id"`
+}
+
+// taskOrderColumns 排序字段对应的数据库列
+var taskOrderColumns = map[string]string{
+	SortPosition:   repository.TaskOrderPosition,
+	SortImportance: repository.TaskOrderImportance,
+	SortDueAt:      repository.TaskOrderDueAt,
+	SortCreatedAt:  repository.TaskOrderCreatedAt,
+}
+
+func newTaskCursor(task *model.Task, by TaskSort) taskCursor {
+	cur := taskCursor{Sort: by.Field, Desc: by.Desc, ID: task.ID}
+	switch by.Field {
+	case SortImportance:
+		cur.Importance = task.ImportanceLevel
+	case SortDueAt:
+		cur.Time = task.DueAt
+	case SortCreatedAt:
+		created := task.CreatedAt
+		cur.Time = &created
+	default:
+		cur.Position = task.Position
+	}
+	return cur
+}
+
+// key 返回游标对应的排序键
+func (cur taskCursor) key() *repository.TaskKey {
+	key := &repository.TaskKey{ID: cur.ID}
+	switch cur.Sort {
+	case SortImportance:
+		key.Value = cur.Importance
+	case SortDueAt, SortCreatedAt:
+		if cur.Time != nil {
+			key.Value = *cur.Time
+		}
+	default:
+		key.Value = cur.Position
+	}
+	return key
+}
+
+// dayRange 将包含两端的日期范围转换为 loc 中的时间区间 [from, to)，日期为空时对应一端不限
+func dayRange(from, to string, loc *time.Location) (start, end *time.Time, err error) {
+	if from != "" {
+		t, err := time.ParseInLocation(DateLayout, from, loc)
+		if err != nil {
+			return nil, nil, err
+		}
+		t = t.UTC()
+		start = &t
+	}
+	if to != "" {
+		t, err := time.ParseInLocation(DateLayout, to, loc)
+		if err != nil {
+			return nil, nil, err
+		}
+		t = t.AddDate(0, 0, 1).UTC()
+		end = &t
+	}
+	return start, end, nil
+}
+
+// Query 按条件筛选、排序并分页返回任务，默认按 position 排序
+func (s *TaskService) Query(ctx context.Context, userID uint, in TaskListQuery, loc *time.Location) (*TaskPage, error) {
+	if in.Sort.Field == "" {
+		in.Sort.Field = SortPosition
+	}
+	if in.Limit <= 0 {
+		in.Limit = pagination.DefaultLimit
+	}
+	now := nowIn(loc)
+
+	q := repository.TaskQuery{
+		Completed:     in.Completed,
+		IsCycle:       in.IsCycle,
+		MinImportance: in.MinImportance,
+		MaxImportance: in.MaxImportance,
+		ListID:        in.ListID,
+		Text:          in.Text,
+		OrderBy:       taskOrderColumns[in.Sort.Field],
+		Desc:          in.Sort.Desc,
+		Limit:         in.Limit + 1,
+	}
+	var err error
+	if in.Completed != nil {
+		if q.CompletedRecurring, err = s.completedRecurring(ctx, userID, now); err != nil {
+			return nil, err
+		}
+	}
+	if q.CreatedFrom, q.CreatedTo, err = dayRange(in.CreatedFrom, in.CreatedTo, loc); err != nil {
+		return nil, err
+	}
+	if q.CompletedFrom, q.CompletedTo, err = dayRange(in.CompletedFrom, in.CompletedTo, loc); err != nil {
+		return nil, err
+	}
+	if in.Tag != "" {
+		tag, err := s.tags.FindByName(ctx, userID, in.Tag)
+		if errors.Is(err, repository.ErrNotFound) {
+			return &TaskPage{Items: []model.Task{}}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		q.TagID = &tag.ID
+	}
+	if in.Cursor != "" {
+		var cur taskCursor
+		if err := pagination.Decode(in.Cursor, &cur); err != nil {
+			return nil, err
+		}
+		if cur.Sort != in.Sort.Field || cur.Desc != in.Sort.Desc {
+			return nil, pagination.ErrInvalidCursor
+		}
+		q.After = cur.key()
+	}
+
+	tasks, err := s.tasks.Query(ctx, userID, q)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.tasks.Count(ctx, userID, q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &TaskPage{Items: tasks, Total: total}
+	if len(tasks) > in.Limit {
+		page.Items = tasks[:in.Limit]
+		page.NextCursor = pagination.Encode(newTaskCursor(&page.Items[in.Limit-1], in.Sort))
+	}
+	presentTasks(page.Items, now)
+	return page, nil
+}
+
+// completedRecurring 返回当前这一次已完成的循环任务ID，与 presentTask 的判断一致
+func (s *TaskService) completedRecurring(ctx context.Context, userID uint, now time.Time) ([]uint, error) {
+	tasks, err := s.tasks.ListRecurring(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	presentTasks(tasks, now)
+	var ids []uint
+	for _, task := range tasks {
+		if task.Completed {
+			ids = append(ids, task.ID)
+		}
+	}
+	return ids, nil
+}
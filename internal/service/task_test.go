@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/repository"
+	"github.com/PisaListBE/pkg/migrate"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB 打开执行过全部迁移的内存 SQLite，只有一个连接，连接关闭后数据即丢弃
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if _, err := migrate.Up(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func newTestTaskService(db *gorm.DB, tasks repository.TaskRepository) *TaskService {
+	return NewTaskService(
+		repository.NewTransactor(db),
+		tasks,
+		repository.NewCompletionRepository(db),
+		repository.NewReminderRepository(db),
+		repository.NewChecklistRepository(db),
+		repository.NewTagRepository(db),
+		repository.NewListRepository(db),
+	)
+}
+
+// racingTasks 在第一次读取任务之后模拟另一个请求修改了这个任务，
+// 服务随后按读到的版本写入时会遇到仓储层的版本冲突
+type racingTasks struct {
+	repository.TaskRepository
+	db    *gorm.DB
+	raced bool
+}
+
+func (r *racingTasks) FindByID(ctx context.Context, userID, id uint) (*model.Task, error) {
+	task, err := r.TaskRepository.FindByID(ctx, userID, id)
+	if err != nil || r.raced {
+		return task, err
+	}
+	r.raced = true
+	err = r.db.Model(&model.Task{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"description": "并发修改", "version": gorm.Expr("version + 1")}).Error
+	return task, err
+}
+
+func TestTaskServiceNotFound(t *testing.T) {
+	db := openTestDB(t)
+	s := newTestTaskService(db, repository.NewTaskRepository(db))
+	ctx := context.Background()
+
+	task, err := s.Create(ctx, 1, TaskInput{Event: "买牛奶"}, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted, err := s.Create(ctx, 1, TaskInput{Event: "已删除"}, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, 1, deleted.ID, nil, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, ref := range map[string]struct{ userID, id uint }{
+		"不存在":   {1, 9999},
+		"其他用户的": {2, task.ID},
+		"已删除":   {1, deleted.ID},
+	} {
+		t.Run(name, func(t *testing.T) {
+			calls := map[string]error{}
+			_, calls["Get"] = s.Get(ctx, ref.userID, ref.id, time.UTC)
+			_, calls["Update"] = s.Update(ctx, ref.userID, ref.id, TaskInput{Event: "x"}, time.UTC)
+			calls["Delete"] = s.Delete(ctx, ref.userID, ref.id, nil, time.UTC)
+			_, calls["ToggleComplete"] = s.ToggleComplete(ctx, ref.userID, ref.id, nil, time.UTC)
+			_, calls["Subtasks"] = s.Subtasks(ctx, ref.userID, ref.id, time.UTC)
+			for call, err := range calls {
+				if !errors.Is(err, ErrTaskNotFound) {
+					t.Errorf("%s = %v, want ErrTaskNotFound", call, err)
+				}
+			}
+			// 作为父任务时是请求参数无效，而不是请求的任务不存在
+			if _, err := s.Create(ctx, ref.userID, TaskInput{Event: "x", ParentID: &ref.id}, time.UTC); !errors.Is(err, ErrInvalidParent) {
+				t.Errorf("Create 子任务 = %v, want ErrInvalidParent", err)
+			}
+		})
+	}
+
+	if _, err := s.Restore(ctx, 1, task.ID, nil, time.UTC); !errors.Is(err, ErrTaskNotInTrash) {
+		t.Errorf("恢复不在回收站的任务 = %v, want ErrTaskNotInTrash", err)
+	}
+	if _, err := s.GetDeleted(ctx, 2, deleted.ID, time.UTC); !errors.Is(err, ErrTaskNotInTrash) {
+		t.Errorf("获取其他用户回收站中的任务 = %v, want ErrTaskNotInTrash", err)
+	}
+	if _, err := s.Restore(ctx, 1, deleted.ID, nil, time.UTC); err != nil {
+		t.Errorf("恢复回收站中的任务: %v", err)
+	}
+}
+
+func TestTaskServiceVersion(t *testing.T) {
+	db := openTestDB(t)
+	s := newTestTaskService(db, repository.NewTaskRepository(db))
+	ctx := context.Background()
+
+	task, err := s.Create(ctx, 1, TaskInput{Event: "买牛奶"}, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := task.Version
+
+	updated, err := s.Update(ctx, 1, task.ID, TaskInput{Event: "买酸奶", Version: &stale}, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Version != stale+1 {
+		t.Fatalf("更新后版本 %d, want %d", updated.Version, stale+1)
+	}
+
+	// If-Match 与当前版本不一致
+	if _, err := s.Update(ctx, 1, task.ID, TaskInput{Event: "买豆奶", Version: &stale}, time.UTC); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("过期版本更新 = %v, want ErrVersionConflict", err)
+	}
+	if err := s.Delete(ctx, 1, task.ID, &stale, time.UTC); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("过期版本删除 = %v, want ErrVersionConflict", err)
+	}
+	if _, err := s.ToggleComplete(ctx, 1, task.ID, &stale, time.UTC); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("过期版本完成 = %v, want ErrVersionConflict", err)
+	}
+
+	current, err := s.Get(ctx, 1, task.ID, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Event != "买酸奶" || current.Completed || current.Version != updated.Version {
+		t.Fatalf("版本冲突后任务被修改: %+v", current)
+	}
+
+	// 批量修改重要性时返回冲突的任务
+	err = s.UpdateImportance(ctx, 1, []ImportanceInput{{ID: task.ID, Level: 3, Version: &stale}})
+	var batch *BatchVersionError
+	if !errors.As(err, &batch) || batch.ID != task.ID || !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("批量修改重要性 = %v, want 任务 %d 的 BatchVersionError", err, task.ID)
+	}
+}
+
+// 读取之后被并发修改时，没有 If-Match 的请求也不会覆盖对方的修改
+func TestTaskServiceConcurrentUpdate(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	task, err := newTestTaskService(db, repository.NewTaskRepository(db)).Create(ctx, 1, TaskInput{Event: "买牛奶"}, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, call := range map[string]func(s *TaskService) error{
+		"Update": func(s *TaskService) error {
+			_, err := s.Update(ctx, 1, task.ID, TaskInput{Event: "买酸奶"}, time.UTC)
+			return err
+		},
+		"Delete": func(s *TaskService) error {
+			return s.Delete(ctx, 1, task.ID, nil, time.UTC)
+		},
+		"ToggleComplete": func(s *TaskService) error {
+			_, err := s.ToggleComplete(ctx, 1, task.ID, nil, time.UTC)
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := newTestTaskService(db, &racingTasks{TaskRepository: repository.NewTaskRepository(db), db: db})
+			if err := call(s); !errors.Is(err, ErrVersionConflict) {
+				t.Fatalf("%s = %v, want ErrVersionConflict", name, err)
+			}
+			var current model.Task
+			if err := db.First(&current, task.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			if current.Event != "买牛奶" || current.Description != "并发修改" || current.Completed {
+				t.Fatalf("%s 覆盖了并发修改: %+v", name, current)
+			}
+		})
+	}
+}
+
+func TestTaskServiceValidation(t *testing.T) {
+	db := openTestDB(t)
+	s := newTestTaskService(db, repository.NewTaskRepository(db))
+	ctx := context.Background()
+
+	start := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	due := start.Add(-time.Hour)
+	tests := []struct {
+		name string
+		in   TaskInput
+		want error
+	}{
+		{"截止时间早于开始时间", TaskInput{Event: "x", StartAt: &start, DueAt: &due}, ErrInvalidTaskDates},
+		{"无效的重复规则", TaskInput{Event: "x", Recurrence: "FREQ=HOURLY"}, ErrInvalidRecurrence},
+		{"不存在的清单", TaskInput{Event: "x", ListID: uintPtr(9999)}, ErrListNotFound},
+		{"不存在的标签", TaskInput{Event: "x", TagIDs: []uint{9999}}, ErrTagNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := s.Create(ctx, 1, tt.in, time.UTC); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Create = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	var count int64
+	if err := db.Model(&model.Task{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("校验失败后写入了 %d 个任务", count)
+	}
+}
+
+func uintPtr(v uint) *uint {
+	return &v
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PisaListBE/internal/repository"
+	"github.com/PisaListBE/pkg/pagination"
+)
+
+// 时间线分组方式
+const (
+	GroupByDay   = "day"
+	GroupByWeek  = "week"
+	GroupByMonth = "month"
+)
+
+// TimelineQuery 时间线查询参数，日期格式为 YYYY-MM-DD 且包含两端
+type TimelineQuery struct {
+	From string
+	To   string
+	// Cursor 上一页返回的 next_cursor，为空时返回第一页
+	Cursor string
+	Limit  int
+}
+
+// withDefaults 补全未指定的参数：To 默认为 loc 的今天，From 默认为 To 往前6天
+func (q TimelineQuery) withDefaults(loc *time.Location) TimelineQuery {
+	if q.To == "" {
+		q.To = nowIn(loc).Format(DateLayout)
+	}
+	if q.From == "" {
+		to, err := time.Parse(DateLayout, q.To)
+		if err != nil {
+			to = nowIn(loc)
+		}
+		q.From = to.AddDate(0, 0, -6).Format(DateLayout)
+	}
+	if q.Limit <= 0 {
+		q.Limit = pagination.DefaultLimit
+	}
+	return q
+}
+
+// TimelinePage 时间线的一页，NextCursor 为空表示没有更多记录
+type TimelinePage struct {
+	Items      []TimelineEntry `json:"items"`
+	NextCursor string          `json:"next_cursor" example:"eyJkIjoiMjAyNS0wMS0xMCIsImkiOjQyfQ"`
+}
+
+// timelineCursor 时间线游标，记录上一页最后一条完成记录的位置
+type timelineCursor struct {
+	Date string `json:"d"`
+	ID   uint   `json:"i"`
+}
+
+// TimelineBucket 时间线的一个分组，From/To 为分组覆盖的日期范围
+type TimelineBucket struct {
+	Key   string `json:"key" example:"2025-W02"`
+	From  string `json:"from" example:"2025-01-06"`
+	To    string `json:"to" example:"2025-01-12"`
+	Count int64  `json:"count" example:"9"`
+}
+
+// ValidGroupBy 判断分组方式是否支持
+func ValidGroupBy(groupBy string) bool {
+	switch groupBy {
+	case GroupByDay, GroupByWeek, GroupByMonth:
+		return true
+	}
+	return false
+}
+
+// groupTimeline 将按天倒序的完成数量合并为分组，周从周一开始
+func groupTimeline(counts []repository.DayCount, groupBy string) ([]TimelineBucket, error) {
+	buckets := make([]TimelineBucket, 0, len(counts))
+	for _, c := range counts {
+		day, err := time.Parse(DateLayout, c.Date)
+		if err != nil {
+			return nil, fmt.Errorf("无效的完成日期 %q: %w", c.Date, err)
+		}
+
+		var b TimelineBucket
+		switch groupBy {
+		case GroupByWeek:
+			start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+			year, week := day.ISOWeek()
+			b = TimelineBucket{
+				Key:  fmt.Sprintf("%d-W%02d", year, week),
+				From: start.Format(DateLayout),
+				To:   start.AddDate(0, 0, 6).Format(DateLayout),
+			}
+		case GroupByMonth:
+			start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+			b = TimelineBucket{
+				Key:  start.Format("2006-01"),
+				From: start.Format(DateLayout),
+				To:   start.AddDate(0, 1, -1).Format(DateLayout),
+			}
+		default:
+			b = TimelineBucket{Key: c.Date, From: c.Date, To: c.Date}
+		}
+
+		// 输入按日期倒序，同一分组的日期一定相邻
+		if n := len(buckets); n > 0 && buckets[n-1].Key == b.Key {
+			buckets[n-1].Count += c.Count
+			continue
+		}
+		b.Count = c.Count
+		buckets = append(buckets, b)
+	}
+	return buckets, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/repository"
+)
+
+// Trash 回收站内容
+type Trash struct {
+	Tasks  []model.Task `json:"tasks"`
+	Wishes []TrashWish  `json:"wishes"`
+}
+
+// TrashWish 回收站中的心愿，附带删除时间
+type TrashWish struct {
+	model.Wish
+	DeletedAt time.Time `json:"deleted_at" example:"2024-01-10T15:04:05Z"`
+}
+
+// TrashService 回收站查询与过期清理
+type TrashService struct {
+	tasks     repository.TaskRepository
+	wishes    repository.WishRepository
+	tags      repository.TagRepository
+	lists     repository.ListRepository
+	retention time.Duration
+}
+
+// NewTrashService 创建回收站服务，retention 为回收站中数据的保留时长
+func NewTrashService(tasks repository.TaskRepository, wishes repository.WishRepository, tags repository.TagRepository, lists repository.ListRepository, retention time.Duration) *TrashService {
+	return &TrashService{tasks: tasks, wishes: wishes, tags: tags, lists: lists, retention: retention}
+}
+
+// List 返回用户回收站中的任务和心愿
+func (s *TrashService) List(ctx context.Context, userID uint) (*Trash, error) {
+	tasks, err := s.tasks.ListDeleted(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	wishes, err := s.wishes.ListDeleted(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	trash := &Trash{Tasks: tasks, Wishes: make([]TrashWish, 0, len(wishes))}
+	for _, wish := range wishes {
+		trash.Wishes = append(trash.Wishes, TrashWish{Wish: wish, DeletedAt: wish.DeletedAt.Time})
+	}
+	return trash, nil
+}
+
+// Purge 彻底删除超过保留期的任务和心愿，返回删除数量。
+// 已删除的标签和清单不进入回收站，保留同样的时长后一起清理。
+func (s *TrashService) Purge(ctx context.Context) (tasks, wishes int64, err error) {
+	before := time.Now().UTC().Add(-s.retention)
+	if tasks, err = s.tasks.PurgeDeletedBefore(ctx, before); err != nil {
+		return 0, 0, err
+	}
+	if wishes, err = s.wishes.PurgeDeletedBefore(ctx, before); err != nil {
+		return tasks, 0, err
+	}
+	if _, err = s.tags.PurgeDeletedBefore(ctx, before); err != nil {
+		return tasks, wishes, err
+	}
+	if _, err = s.lists.PurgeDeletedBefore(ctx, before); err != nil {
+		return tasks, wishes, err
+	}
+	return tasks, wishes, nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// RegisterInput 注册参数
+type RegisterInput struct {
+	Username string
+	Password string
+	Email    string
+	// Timezone IANA 时区名，可为空
+	Timezone string
+}
+
+// UserService 用户注册、登录和个人设置
+type UserService struct {
+	users repository.UserRepository
+	// defaultLoc 用户未设置时区时使用的时区
+	defaultLoc *time.Location
+}
+
+// NewUserService 创建用户服务
+func NewUserService(users repository.UserRepository, defaultLoc *time.Location) *UserService {
+	return &UserService{users: users, defaultLoc: defaultLoc}
+}
+
+// normalizeTimezone 校验 IANA 时区名，空字符串表示使用默认时区。
+// "Local" 依赖服务器所在时区，不允许使用。
+func normalizeTimezone(name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return "", fmt.Errorf("%w: %s", ErrInvalidTimezone, name)
+	}
+	return loc.String(), nil
+}
+
+// Register 创建新用户，用户名已存在时返回 ErrUsernameTaken
+func (s *UserService) Register(ctx context.Context, in RegisterInput) (*model.User, error) {
+	timezone, err := normalizeTimezone(in.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查用户名是否已存在
+	_, err = s.users.FindByUsername(ctx, in.Username)
+	if err == nil {
+		return nil, ErrUsernameTaken
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	// 加密密码
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(in.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("密码加密失败: %w", err)
+	}
+
+	user := &model.User{
+		Username: in.Username,
+		Password: string(hashedPassword),
+		Email:    in.Email,
+		Timezone: timezone,
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Login 校验用户名和密码，失败时返回 ErrInvalidCredentials
+func (s *UserService) Login(ctx context.Context, username, password string) (*model.User, error) {
+	user, err := s.users.FindByUsername(ctx, username)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// Get 返回用户信息
+func (s *UserService) Get(ctx context.Context, userID uint) (*model.User, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+// Timezone 返回用户实际使用的时区名，未设置时为默认时区
+func (s *UserService) Timezone(user *model.User) string {
+	if user.Timezone == "" {
+		return s.defaultLoc.String()
+	}
+	return user.Timezone
+}
+
+// Location 返回用户所在时区，用于计算“今天”等日期边界
+func (s *UserService) Location(ctx context.Context, userID uint) (*time.Location, error) {
+	user, err := s.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.locationOf(user.Timezone), nil
+}
+
+// locationOf 将用户保存的时区名解析为时区，为空时使用默认时区
+func (s *UserService) locationOf(timezone string) *time.Location {
+	if timezone == "" {
+		return s.defaultLoc
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		// 保存时已校验，只有时区数据库变化时才会走到这里
+		return s.defaultLoc
+	}
+	return loc
+}
+
+// Timezones 返回用户设置过的所有时区，未设置时区的用户对应空字符串
+func (s *UserService) Timezones(ctx context.Context) ([]string, error) {
+	return s.users.Timezones(ctx)
+}
+
+// SetTimezone 设置用户时区，空字符串表示恢复默认时区
+func (s *UserService) SetTimezone(ctx context.Context, userID uint, name string) (*model.User, error) {
+	timezone, err := normalizeTimezone(name)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.Update(ctx, user, map[string]interface{}{"timezone": timezone}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
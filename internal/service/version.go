@@ -0,0 +1,32 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/PisaListBE/internal/repository"
+)
+
+// checkVersion 校验客户端期望的版本号（If-Match），expected 为 nil 时不校验
+func checkVersion(current uint, expected *uint) error {
+	if expected != nil && *expected != current {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// versionError 把仓储层的版本冲突转换为 ErrVersionConflict
+func versionError(err error) error {
+	if errors.Is(err, repository.ErrConflict) {
+		return ErrVersionConflict
+	}
+	return err
+}
+
+// BatchVersionError 批量修改时某一条记录的版本冲突，ID 为冲突的记录
+type BatchVersionError struct {
+	ID uint
+}
+
+func (e *BatchVersionError) Error() string { return ErrVersionConflict.Error() }
+
+func (e *BatchVersionError) Unwrap() error { return ErrVersionConflict }
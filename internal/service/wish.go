@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/repository"
+)
+
+// WishInput 创建或更新心愿的参数
+type WishInput struct {
+	Event       string
+	Description string
+	IsCycle     bool
+	// TagIDs 心愿的标签，更新时为 nil 表示保持不变
+	TagIDs []uint
+	// Version 更新时期望的当前版本号（If-Match），为 nil 时不校验
+	Version *uint
+	// ClientID 离线客户端生成的ID，只在创建时使用，同一用户内重复创建时返回已有的心愿，已有的心愿已删除时返回 ErrWishNotFound
+	ClientID string
+}
+
+// WishService 心愿及心愿社区相关业务逻辑
+type WishService struct {
+	tx            repository.Transactor
+	wishes        repository.WishRepository
+	tags          repository.TagRepository
+	notifications *NotificationService
+}
+
+// NewWishService 创建心愿服务
+func NewWishService(tx repository.Transactor, wishes repository.WishRepository, tags repository.TagRepository, notifications *NotificationService) *WishService {
+	return &WishService{tx: tx, wishes: wishes, tags: tags, notifications: notifications}
+}
+
+func (s *WishService) get(ctx context.Context, userID, id uint) (*model.Wish, error) {
+	wish, err := s.wishes.FindByID(ctx, userID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrWishNotFound
+	}
+	return wish, err
+}
+
+// Create 创建心愿
+func (s *WishService) Create(ctx context.Context, userID uint, in WishInput) (*model.Wish, error) {
+	if in.ClientID != "" {
+		existing, err := s.wishes.FindByClientID(ctx, userID, in.ClientID)
+		if err == nil && existing.DeletedAt.Valid {
+			// 同一个 client_id 创建的记录已被删除，不再重复创建
+			return nil, ErrWishNotFound
+		}
+		if err == nil {
+			return existing, nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+	}
+	tags, err := resolveTags(ctx, s.tags, userID, in.TagIDs)
+	if err != nil {
+		return nil, err
+	}
+	wish := &model.Wish{
+		Version:     1,
+		UserID:      userID,
+		Event:       in.Event,
+		Description: in.Description,
+		IsCycle:     in.IsCycle,
+		IsShared:    false,
+		ClientID:    clientID(in.ClientID),
+		Tags:        tags,
+	}
+	if err := s.wishes.Create(ctx, wish); err != nil {
+		return nil, err
+	}
+	return wish, nil
+}
+
+// Update 更新心愿
+func (s *WishService) Update(ctx context.Context, userID, id uint, in WishInput) (*model.Wish, error) {
+	wish, err := s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(wish.Version, in.Version); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"event":       in.Event,
+		"description": in.Description,
+		"is_cycle":    in.IsCycle,
+	}
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.wishes.Update(ctx, wish, updates); err != nil {
+			return err
+		}
+		if in.TagIDs == nil {
+			return nil
+		}
+		tags, err := resolveTags(ctx, s.tags, userID, in.TagIDs)
+		if err != nil {
+			return err
+		}
+		return s.wishes.SetTags(ctx, wish, tags)
+	})
+	if err != nil {
+		return nil, versionError(err)
+	}
+	return s.get(ctx, userID, id)
+}
+
+// Get 返回心愿详情
+func (s *WishService) Get(ctx context.Context, userID, id uint) (*model.Wish, error) {
+	return s.get(ctx, userID, id)
+}
+
+// GetDeleted 获取回收站中的心愿，不在回收站时返回 ErrWishNotInTrash
+func (s *WishService) GetDeleted(ctx context.Context, userID, id uint) (*model.Wish, error) {
+	wish, err := s.wishes.FindDeleted(ctx, userID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrWishNotInTrash
+	}
+	return wish, err
+}
+
+// Delete 删除心愿。version 为期望的当前版本号（If-Match），为 nil 时不校验
+func (s *WishService) Delete(ctx context.Context, userID, id uint, version *uint) error {
+	wish, err := s.get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(wish.Version, version); err != nil {
+		return err
+	}
+	return versionError(s.wishes.Delete(ctx, wish))
+}
+
+// Share 将心愿分享到心愿社区，心愿的标签一起复制到社区心愿。
+// 每个心愿只能分享一次，已分享时返回 ErrWishAlreadyShared；并发分享时只有一个成功，其余返回 ErrVersionConflict
+func (s *WishService) Share(ctx context.Context, userID, id uint) (*model.SharedWish, error) {
+	wish, err := s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if wish.IsShared {
+		return nil, ErrWishAlreadyShared
+	}
+
+	shared := &model.SharedWish{
+		OriginalWishID: wish.ID,
+		Event:          wish.Event,
+		Description:    wish.Description,
+		SharedByUserID: userID,
+	}
+	for _, tag := range wish.Tags {
+		shared.Tags = append(shared.Tags, model.SharedWishTag{Name: tag.Name, Color: tag.Color})
+	}
+	if err := s.wishes.Share(ctx, wish, shared); err != nil {
+		return nil, versionError(err)
+	}
+	return shared, nil
+}
+
+// List 返回用户的所有心愿，tag 不为空时只返回带有该标签的心愿
+func (s *WishService) List(ctx context.Context, userID uint, tag string) ([]model.Wish, error) {
+	match, err := matchTag(ctx, s.tags, userID, tag)
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.wishes.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	wishes := make([]model.Wish, 0, len(all))
+	for _, wish := range all {
+		if match(wish.Tags) {
+			wishes = append(wishes, wish)
+		}
+	}
+	return wishes, nil
+}
+
+// Community 返回心愿社区中的所有心愿，tag 不为空时只返回带有该名称标签的心愿
+func (s *WishService) Community(ctx context.Context, tag string) ([]model.SharedWish, error) {
+	return s.wishes.ListShared(ctx, tag)
+}
+
+// Random 从心愿社区随机返回一个心愿，社区为空时返回 ErrNoSharedWish
+func (s *WishService) Random(ctx context.Context) (*model.SharedWish, error) {
+	count, err := s.wishes.CountShared(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrNoSharedWish
+	}
+
+	return s.wishes.SharedAt(ctx, int(rand.Int63n(count)))
+}
+
+// Restore 从回收站恢复心愿，version 不为 nil 时必须与回收站中心愿的版本一致
+func (s *WishService) Restore(ctx context.Context, userID, id uint, version *uint) (*model.Wish, error) {
+	if err := s.wishes.Restore(ctx, userID, id, version); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrWishNotInTrash
+		}
+		return nil, versionError(err)
+	}
+	return s.get(ctx, userID, id)
+}
+
+func (s *WishService) getShared(ctx context.Context, id uint) (*model.SharedWish, error) {
+	shared, err := s.wishes.FindShared(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrSharedWishNotFound
+	}
+	return shared, err
+}
+
+// Like 点赞社区心愿并通知分享者，重复点赞不会重复计数，返回更新后的社区心愿
+func (s *WishService) Like(ctx context.Context, userID, sharedID uint) (*model.SharedWish, error) {
+	shared, err := s.getShared(ctx, sharedID)
+	if err != nil {
+		return nil, err
+	}
+	liked, err := s.wishes.Like(ctx, sharedID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if liked {
+		// 通知失败不影响点赞结果
+		if err := s.notifications.NotifyWishLiked(ctx, shared, userID); err != nil {
+			fmt.Printf("写入点赞通知失败: %v\n", err)
+		}
+	}
+	return s.getShared(ctx, sharedID)
+}
+
+// Unlike 取消点赞社区心愿，返回更新后的社区心愿
+func (s *WishService) Unlike(ctx context.Context, userID, sharedID uint) (*model.SharedWish, error) {
+	if _, err := s.getShared(ctx, sharedID); err != nil {
+		return nil, err
+	}
+	if _, err := s.wishes.Unlike(ctx, sharedID, userID); err != nil {
+		return nil, err
+	}
+	return s.getShared(ctx, sharedID)
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/repository"
+	"gorm.io/gorm"
+)
+
+func newTestWishService(db *gorm.DB, wishes repository.WishRepository) *WishService {
+	users := NewUserService(repository.NewUserRepository(db), time.UTC)
+	notifications := NewNotificationService(repository.NewNotificationRepository(db), repository.NewTaskRepository(db), users, 8)
+	return NewWishService(repository.NewTransactor(db), wishes, repository.NewTagRepository(db), notifications)
+}
+
+// racingWishes 在第一次读取心愿之后模拟另一个请求修改了这个心愿
+type racingWishes struct {
+	repository.WishRepository
+	db    *gorm.DB
+	raced bool
+}
+
+func (r *racingWishes) FindByID(ctx context.Context, userID, id uint) (*model.Wish, error) {
+	wish, err := r.WishRepository.FindByID(ctx, userID, id)
+	if err != nil || r.raced {
+		return wish, err
+	}
+	r.raced = true
+	err = r.db.Model(&model.Wish{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"description": "并发修改", "version": gorm.Expr("version + 1")}).Error
+	return wish, err
+}
+
+func TestWishServiceNotFound(t *testing.T) {
+	db := openTestDB(t)
+	s := newTestWishService(db, repository.NewWishRepository(db))
+	ctx := context.Background()
+
+	wish, err := s.Create(ctx, 1, WishInput{Event: "环游世界"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted, err := s.Create(ctx, 1, WishInput{Event: "已删除"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, 1, deleted.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, ref := range map[string]struct{ userID, id uint }{
+		"不存在":   {1, 9999},
+		"其他用户的": {2, wish.ID},
+		"已删除":   {1, deleted.ID},
+	} {
+		t.Run(name, func(t *testing.T) {
+			calls := map[string]error{}
+			_, calls["Get"] = s.Get(ctx, ref.userID, ref.id)
+			_, calls["Update"] = s.Update(ctx, ref.userID, ref.id, WishInput{Event: "x"})
+			calls["Delete"] = s.Delete(ctx, ref.userID, ref.id, nil)
+			_, calls["Share"] = s.Share(ctx, ref.userID, ref.id)
+			for call, err := range calls {
+				if !errors.Is(err, ErrWishNotFound) {
+					t.Errorf("%s = %v, want ErrWishNotFound", call, err)
+				}
+			}
+		})
+	}
+
+	if _, err := s.Restore(ctx, 1, wish.ID, nil); !errors.Is(err, ErrWishNotInTrash) {
+		t.Errorf("恢复不在回收站的心愿 = %v, want ErrWishNotInTrash", err)
+	}
+	if _, err := s.GetDeleted(ctx, 2, deleted.ID); !errors.Is(err, ErrWishNotInTrash) {
+		t.Errorf("获取其他用户回收站中的心愿 = %v, want ErrWishNotInTrash", err)
+	}
+	if _, err := s.Like(ctx, 1, 9999); !errors.Is(err, ErrSharedWishNotFound) {
+		t.Errorf("点赞不存在的社区心愿 = %v, want ErrSharedWishNotFound", err)
+	}
+	if _, err := s.Random(ctx); !errors.Is(err, ErrNoSharedWish) {
+		t.Errorf("社区为空时随机心愿 = %v, want ErrNoSharedWish", err)
+	}
+	if _, err := s.Create(ctx, 1, WishInput{Event: "x", TagIDs: []uint{9999}}); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("使用不存在的标签 = %v, want ErrTagNotFound", err)
+	}
+}
+
+func TestWishServiceVersion(t *testing.T) {
+	db := openTestDB(t)
+	s := newTestWishService(db, repository.NewWishRepository(db))
+	ctx := context.Background()
+
+	wish, err := s.Create(ctx, 1, WishInput{Event: "环游世界"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := wish.Version
+
+	updated, err := s.Update(ctx, 1, wish.ID, WishInput{Event: "环游中国", Version: &stale})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Version != stale+1 {
+		t.Fatalf("更新后版本 %d, want %d", updated.Version, stale+1)
+	}
+	if _, err := s.Update(ctx, 1, wish.ID, WishInput{Event: "环游月球", Version: &stale}); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("过期版本更新 = %v, want ErrVersionConflict", err)
+	}
+	if err := s.Delete(ctx, 1, wish.ID, &stale); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("过期版本删除 = %v, want ErrVersionConflict", err)
+	}
+
+	// 回收站中的心愿按删除时的版本恢复
+	if err := s.Delete(ctx, 1, wish.ID, &updated.Version); err != nil {
+		t.Fatal(err)
+	}
+	trashed, err := s.GetDeleted(ctx, 1, wish.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Restore(ctx, 1, wish.ID, &updated.Version); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("过期版本恢复 = %v, want ErrVersionConflict", err)
+	}
+	restored, err := s.Restore(ctx, 1, wish.ID, &trashed.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Event != "环游中国" {
+		t.Fatalf("恢复后的心愿 %+v", restored)
+	}
+
+	if _, err := s.Share(ctx, 1, wish.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Share(ctx, 1, wish.ID); !errors.Is(err, ErrWishAlreadyShared) {
+		t.Errorf("重复分享 = %v, want ErrWishAlreadyShared", err)
+	}
+}
+
+// 读取之后被并发修改时，没有 If-Match 的请求也不会覆盖对方的修改
+func TestWishServiceConcurrentUpdate(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	wish, err := newTestWishService(db, repository.NewWishRepository(db)).Create(ctx, 1, WishInput{Event: "环游世界"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, call := range map[string]func(s *WishService) error{
+		"Update": func(s *WishService) error {
+			_, err := s.Update(ctx, 1, wish.ID, WishInput{Event: "环游中国"})
+			return err
+		},
+		"Delete": func(s *WishService) error {
+			return s.Delete(ctx, 1, wish.ID, nil)
+		},
+		// 分享失败时社区心愿也不会写入
+		"Share": func(s *WishService) error {
+			_, err := s.Share(ctx, 1, wish.ID)
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := newTestWishService(db, &racingWishes{WishRepository: repository.NewWishRepository(db), db: db})
+			if err := call(s); !errors.Is(err, ErrVersionConflict) {
+				t.Fatalf("%s = %v, want ErrVersionConflict", name, err)
+			}
+			var current model.Wish
+			if err := db.First(&current, wish.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			if current.Event != "环游世界" || current.Description != "并发修改" || current.IsShared {
+				t.Fatalf("%s 覆盖了并发修改: %+v", name, current)
+			}
+			var shared int64
+			if err := db.Model(&model.SharedWish{}).Where("original_wish_id = ?", wish.ID).Count(&shared).Error; err != nil {
+				t.Fatal(err)
+			}
+			if shared != 0 {
+				t.Fatalf("%s 之后社区中有 %d 条该心愿", name, shared)
+			}
+		})
+	}
+}
@@ -1,22 +1,158 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	// 内置时区数据，精简镜像中没有 /usr/share/zoneinfo 时也能解析用户时区
+	_ "time/tzdata"
+
+	"github.com/PisaListBE/pkg/config"
 	"github.com/PisaListBE/pkg/database"
+	"github.com/PisaListBE/pkg/jwt"
+	"github.com/PisaListBE/pkg/migrate"
+	"github.com/PisaListBE/pkg/worker"
 	"github.com/PisaListBE/router"
 	"github.com/gin-gonic/gin"
 )
 
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `用法:
+  %[1]s [--config path]                   启动服务
+  %[1]s [--config path] migrate up        执行所有未执行的迁移
+  %[1]s [--config path] migrate down [n]  回滚最近 n 个迁移（默认 1）
+  %[1]s [--config path] migrate status    查看迁移状态
+  %[1]s [--config path] migrate seed      写入初始数据
+
+参数:
+`, os.Args[0])
+	flag.PrintDefaults()
+}
+
 func main() {
+	defaultPath := config.DefaultPath
+	if p := os.Getenv("PISA_CONFIG"); p != "" {
+		defaultPath = p
+	}
+	configPath := flag.String("config", defaultPath, "配置文件路径")
+	flag.Usage = usage
+	flag.Parse()
+
+	command := flag.Arg(0)
+	if command != "" && command != "migrate" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// 加载配置
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		panic("加载配置失败: " + err.Error())
+	}
+
+	gin.SetMode(cfg.Server.Mode)
+	jwt.Init(cfg.JWT)
+
 	// 初始化数据库连接
-	if err := database.InitGormDB(); err != nil {
+	if err := database.InitGormDB(cfg.Database); err != nil {
 		panic("数据库连接失败: " + err.Error())
 	}
 
+	if command == "migrate" {
+		err := runMigrate(flag.Args()[1:])
+		database.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if cfg.Database.AutoMigrate {
+		n, err := migrate.Up(database.GormDB)
+		if err != nil {
+			panic("数据库迁移失败: " + err.Error())
+		}
+		if n > 0 {
+			fmt.Printf("已执行 %d 个数据库迁移\n", n)
+		}
+		if err := migrate.Seed(database.GormDB); err != nil {
+			panic("写入初始数据失败: " + err.Error())
+		}
+	}
+
+	err = runServer(cfg)
+	// 请求和后台任务都已结束后再关闭连接池
+	database.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// runServer 启动 HTTP 服务和后台任务，收到 SIGINT/SIGTERM 后在 shutdown_timeout 内优雅退出
+func runServer(cfg *config.Config) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	a, err := newApp(database.GormDB, cfg)
+	if err != nil {
+		return err
+	}
+	workers := worker.NewGroup(ctx)
+	a.startWorkers(workers, cfg)
+
 	r := gin.Default()
 
 	// 初始化路由
-	router.InitRouter(r)
+	router.InitRouter(r, a.handlers(database.GormDB))
+
+	srv := &http.Server{
+		Addr:         cfg.Server.Addr(),
+		Handler:      r,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
 
 	// 启动服务器
-	r.Run(":8080")
+	errCh := make(chan error, 1)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+	fmt.Printf("服务已启动，监听 %s\n", srv.Addr)
+
+	select {
+	case err := <-errCh:
+		workers.Stop(context.Background())
+		a.close()
+		return fmt.Errorf("HTTP 服务异常退出: %w", err)
+	case <-ctx.Done():
+	}
+	stop()
+	fmt.Println("收到退出信号，正在关闭服务...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		fmt.Printf("等待请求处理完成超时: %v\n", err)
+	}
+	if err := workers.Stop(shutdownCtx); err != nil {
+		fmt.Printf("等待后台任务退出超时: %v\n", err)
+	}
+	if err := a.close(); err != nil {
+		fmt.Printf("关闭搜索索引失败: %v\n", err)
+	}
+
+	fmt.Println("服务已关闭")
+	return nil
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/PisaListBE/pkg/database"
+	"github.com/PisaListBE/pkg/migrate"
+)
+
+// runMigrate 处理 migrate 子命令
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New("缺少 migrate 子命令: up | down [n] | status | seed")
+	}
+
+	db := database.GormDB
+	switch args[0] {
+	case "up":
+		n, err := migrate.Up(db)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("已执行 %d 个迁移\n", n)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			v, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("无效的回滚步数: %s", args[1])
+			}
+			steps = v
+		}
+		n, err := migrate.Down(db, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("已回滚 %d 个迁移\n", n)
+	case "status":
+		list, err := migrate.Status(db)
+		if err != nil {
+			return err
+		}
+		for _, s := range list {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%s_%-30s %s\n", s.Version, s.Name, state)
+		}
+	case "seed":
+		if err := migrate.Seed(db); err != nil {
+			return err
+		}
+		fmt.Println("初始数据写入完成")
+	default:
+		return fmt.Errorf("未知的 migrate 子命令: %s", args[0])
+	}
+	return nil
+}
@@ -0,0 +1,365 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix 环境变量前缀，例如 PISA_JWT_SECRET 覆盖 jwt.secret
+const EnvPrefix = "PISA"
+
+// DefaultPath 默认配置文件路径
+const DefaultPath = "config/config.yaml"
+
+// Conf 全局配置，由 Load 初始化
+var Conf *Config
+
+// Config 应用配置
+type Config struct {
+	App      AppConfig      `mapstructure:"app"`
+	Server   ServerConfig   `mapstructure:"server"`
+	Database DatabaseConfig `mapstructure:"database"`
+	JWT      JWTConfig      `mapstructure:"jwt"`
+	Redis    RedisConfig    `mapstructure:"redis"`
+	Trash    TrashConfig    `mapstructure:"trash"`
+	Notify   NotifyConfig   `mapstructure:"notify"`
+	Search   SearchConfig   `mapstructure:"search"`
+}
+
+// AppConfig 应用通用配置
+type AppConfig struct {
+	// DefaultTimezone 用户未设置时区时使用的 IANA 时区名
+	DefaultTimezone string `mapstructure:"default_timezone"`
+}
+
+// Location 返回默认时区
+func (a AppConfig) Location() *time.Location {
+	loc, err := time.LoadLocation(a.DefaultTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// ServerConfig HTTP 服务配置
+type ServerConfig struct {
+	Port         int           `mapstructure:"port"`
+	Mode         string        `mapstructure:"mode"`
+	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+	// ShutdownTimeout 收到退出信号后等待请求处理完成的最长时间
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+}
+
+// Addr 返回 http 监听地址
+func (s ServerConfig) Addr() string {
+	return fmt.Sprintf(":%d", s.Port)
+}
+
+// DatabaseConfig 数据库配置
+type DatabaseConfig struct {
+	// Driver 数据库驱动: mysql、postgres 或 sqlite
+	Driver string `mapstructure:"driver"`
+	// DSN 完整连接串，设置后忽略 host/port 等字段；sqlite 下为文件路径或 :memory:
+	DSN          string `mapstructure:"dsn"`
+	Host         string `mapstructure:"host"`
+	Port         int    `mapstructure:"port"`
+	Username     string `mapstructure:"username"`
+	Password     string `mapstructure:"password"`
+	DBName       string `mapstructure:"dbname"`
+	SSLMode      string `mapstructure:"sslmode"` // 仅 postgres 使用
+	MaxIdleConns int    `mapstructure:"max_idle_conns"`
+	MaxOpenConns int    `mapstructure:"max_open_conns"`
+	// ConnMaxLifetime 连接最长存活时间，0 表示不限制
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	// ConnMaxIdleTime 连接最长空闲时间，0 表示不限制
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
+	// AutoMigrate 启动时自动执行未执行的迁移并写入初始数据
+	AutoMigrate bool `mapstructure:"auto_migrate"`
+	// ConnectRetries 启动时连接失败的重试次数
+	ConnectRetries int `mapstructure:"connect_retries"`
+	// ConnectRetryInterval 首次重试间隔，之后按指数退避
+	ConnectRetryInterval time.Duration `mapstructure:"connect_retry_interval"`
+}
+
+// JWTConfig JWT 配置
+type JWTConfig struct {
+	Secret string `mapstructure:"secret"`
+	Expire int    `mapstructure:"expire"` // 小时
+}
+
+// TrashConfig 回收站配置
+type TrashConfig struct {
+	// RetentionDays 删除的任务和心愿在回收站中保留的天数，过期后彻底删除
+	RetentionDays int `mapstructure:"retention_days"`
+	// PurgeInterval 清理任务的执行间隔
+	PurgeInterval time.Duration `mapstructure:"purge_interval"`
+}
+
+// Retention 返回回收站保留时长
+func (t TrashConfig) Retention() time.Duration {
+	return time.Duration(t.RetentionDays) * 24 * time.Hour
+}
+
+// NotifyConfig 提醒与通知配置
+type NotifyConfig struct {
+	// Channels 提醒未指定渠道时使用的默认渠道：inbox、email、webhook
+	Channels []string `mapstructure:"channels"`
+	// PollInterval 提醒调度器检查到期提醒的间隔
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// MaxAttempts 每个提醒发送失败时的最大尝试次数
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// DueHour 用户所在时区的几点开始发送当天循环任务的站内通知
+	DueHour int `mapstructure:"due_hour"`
+	// DueCheckInterval 检查当天循环任务的间隔
+	DueCheckInterval time.Duration `mapstructure:"due_check_interval"`
+	SMTP             SMTPConfig    `mapstructure:"smtp"`
+	Webhook          WebhookConfig `mapstructure:"webhook"`
+}
+
+// SMTPConfig 邮件通知配置，Host 为空时不启用邮件渠道
+type SMTPConfig struct {
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
+	// Username 为空时不进行 SMTP 认证，适用于本地 mailpit 等测试服务
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+}
+
+// Enabled 是否配置了邮件渠道
+func (s SMTPConfig) Enabled() bool {
+	return s.Host != ""
+}
+
+// Addr 返回 SMTP 服务地址
+func (s SMTPConfig) Addr() string {
+	return fmt.Sprintf("%s:%d", s.Host, s.Port)
+}
+
+// WebhookConfig Webhook 通知配置，URL 为空时不启用
+type WebhookConfig struct {
+	URL string `mapstructure:"url"`
+	// Secret 不为空时使用 HMAC-SHA256 对请求体签名，放在 X-Pisa-Signature 头中
+	Secret  string        `mapstructure:"secret"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// Enabled 是否配置了 Webhook 渠道
+func (w WebhookConfig) Enabled() bool {
+	return w.URL != ""
+}
+
+// 支持的全文搜索后端
+const (
+	SearchBackendBleve = "bleve"
+	SearchBackendMySQL = "mysql"
+)
+
+// SearchConfig 全文搜索配置
+type SearchConfig struct {
+	// Backend 搜索后端：bleve 为内嵌索引，支持所有数据库；mysql 使用 FULLTEXT ngram 索引，仅支持 MySQL
+	Backend string `mapstructure:"backend"`
+	// BlevePath Bleve 索引目录，为空时使用内存索引，重启后重新构建
+	BlevePath string `mapstructure:"bleve_path"`
+	// SyncInterval Bleve 后端从数据库同步变更的间隔
+	SyncInterval time.Duration `mapstructure:"sync_interval"`
+}
+
+// RedisConfig Redis 配置
+type RedisConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+}
+
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("app.default_timezone", "UTC")
+
+	v.SetDefault("server.port", 8080)
+	v.SetDefault("server.mode", "debug")
+	v.SetDefault("server.read_timeout", 15*time.Second)
+	v.SetDefault("server.write_timeout", 30*time.Second)
+	v.SetDefault("server.idle_timeout", 60*time.Second)
+	v.SetDefault("server.shutdown_timeout", 20*time.Second)
+
+	v.SetDefault("database.driver", "mysql")
+	v.SetDefault("database.dsn", "")
+	v.SetDefault("database.host", "localhost")
+	v.SetDefault("database.port", 3306)
+	v.SetDefault("database.username", "root")
+	v.SetDefault("database.password", "")
+	v.SetDefault("database.dbname", "pisa_list")
+	v.SetDefault("database.sslmode", "disable")
+	v.SetDefault("database.max_idle_conns", 10)
+	v.SetDefault("database.max_open_conns", 100)
+	v.SetDefault("database.conn_max_lifetime", time.Hour)
+	v.SetDefault("database.conn_max_idle_time", 10*time.Minute)
+	v.SetDefault("database.auto_migrate", true)
+	v.SetDefault("database.connect_retries", 10)
+	v.SetDefault("database.connect_retry_interval", time.Second)
+
+	v.SetDefault("jwt.secret", "")
+	v.SetDefault("jwt.expire", 24)
+
+	v.SetDefault("trash.retention_days", 30)
+	v.SetDefault("trash.purge_interval", time.Hour)
+
+	v.SetDefault("notify.channels", []string{"inbox"})
+	v.SetDefault("notify.poll_interval", 30*time.Second)
+	v.SetDefault("notify.max_attempts", 5)
+	v.SetDefault("notify.due_hour", 8)
+	v.SetDefault("notify.due_check_interval", 15*time.Minute)
+	v.SetDefault("notify.smtp.port", 25)
+	v.SetDefault("notify.webhook.timeout", 10*time.Second)
+
+	v.SetDefault("search.backend", SearchBackendBleve)
+	v.SetDefault("search.bleve_path", "data/search.bleve")
+	v.SetDefault("search.sync_interval", 10*time.Second)
+
+	v.SetDefault("redis.host", "localhost")
+	v.SetDefault("redis.port", 6379)
+	v.SetDefault("redis.password", "")
+	v.SetDefault("redis.db", 0)
+}
+
+// Load 读取配置文件并应用 PISA_* 环境变量覆盖，校验失败时返回错误。
+// path 为空时使用 DefaultPath。
+func Load(path string) (*Config, error) {
+	if path == "" {
+		path = DefaultPath
+	}
+
+	v := viper.New()
+	setDefaults(v)
+	v.SetConfigFile(path)
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("读取配置文件 %s 失败: %w", path, err)
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("解析配置失败: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	Conf = &cfg
+	return &cfg, nil
+}
+
+// Validate 检查必填配置项
+func (c *Config) Validate() error {
+	var errs []error
+	if _, err := time.LoadLocation(c.App.DefaultTimezone); err != nil || c.App.DefaultTimezone == "Local" {
+		errs = append(errs, fmt.Errorf("app.default_timezone 无效: %q", c.App.DefaultTimezone))
+	}
+	if c.JWT.Secret == "" {
+		errs = append(errs, errors.New("jwt.secret 不能为空"))
+	}
+	if c.JWT.Expire <= 0 {
+		errs = append(errs, errors.New("jwt.expire 必须大于 0"))
+	}
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port 无效: %d", c.Server.Port))
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("server.shutdown_timeout 必须大于 0"))
+	}
+	switch c.Server.Mode {
+	case "debug", "release", "test":
+	default:
+		errs = append(errs, fmt.Errorf("server.mode 无效: %q", c.Server.Mode))
+	}
+	switch c.Database.Driver {
+	case "mysql", "postgres":
+		if c.Database.DSN == "" && c.Database.Host == "" {
+			errs = append(errs, errors.New("database.host 不能为空"))
+		}
+		if c.Database.DSN == "" && c.Database.DBName == "" {
+			errs = append(errs, errors.New("database.dbname 不能为空"))
+		}
+	case "sqlite":
+		if c.Database.DSN == "" && c.Database.DBName == "" {
+			errs = append(errs, errors.New("sqlite 需要配置 database.dsn 或 database.dbname"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("database.driver 无效: %q", c.Database.Driver))
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs = append(errs, errors.New("database.max_idle_conns 不能大于 max_open_conns"))
+	}
+	if c.Database.ConnectRetries < 0 {
+		errs = append(errs, errors.New("database.connect_retries 不能为负数"))
+	}
+	if c.Trash.RetentionDays <= 0 {
+		errs = append(errs, errors.New("trash.retention_days 必须大于 0"))
+	}
+	if c.Trash.PurgeInterval <= 0 {
+		errs = append(errs, errors.New("trash.purge_interval 必须大于 0"))
+	}
+	errs = append(errs, c.Notify.validate()...)
+	switch c.Search.Backend {
+	case SearchBackendBleve:
+		if c.Search.SyncInterval <= 0 {
+			errs = append(errs, errors.New("search.sync_interval 必须大于 0"))
+		}
+	case SearchBackendMySQL:
+		if c.Database.Driver != "mysql" {
+			errs = append(errs, errors.New("search.backend 为 mysql 时 database.driver 必须为 mysql"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("search.backend 无效: %q", c.Search.Backend))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("配置校验失败: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (n NotifyConfig) validate() []error {
+	var errs []error
+	for _, ch := range n.Channels {
+		switch ch {
+		case "inbox":
+		case "email":
+			if !n.SMTP.Enabled() {
+				errs = append(errs, errors.New("notify.channels 包含 email 时需要配置 notify.smtp.host"))
+			}
+		case "webhook":
+			if !n.Webhook.Enabled() {
+				errs = append(errs, errors.New("notify.channels 包含 webhook 时需要配置 notify.webhook.url"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("notify.channels 包含未知渠道: %q", ch))
+		}
+	}
+	if n.SMTP.Enabled() && n.SMTP.From == "" {
+		errs = append(errs, errors.New("notify.smtp.from 不能为空"))
+	}
+	if n.PollInterval <= 0 {
+		errs = append(errs, errors.New("notify.poll_interval 必须大于 0"))
+	}
+	if n.MaxAttempts <= 0 {
+		errs = append(errs, errors.New("notify.max_attempts 必须大于 0"))
+	}
+	if n.DueHour < 0 || n.DueHour > 23 {
+		errs = append(errs, fmt.Errorf("notify.due_hour 无效: %d", n.DueHour))
+	}
+	if n.DueCheckInterval <= 0 {
+		errs = append(errs, errors.New("notify.due_check_interval 必须大于 0"))
+	}
+	return errs
+}
@@ -2,60 +2,107 @@ package database
 
 import (
 	"fmt"
+	"time"
 
-	"github.com/PisaListBE/internal/model"
-	"gorm.io/driver/mysql"
+	"github.com/PisaListBE/pkg/config"
 	"gorm.io/gorm"
 )
 
+// 连接重试的退避上限
+const maxRetryInterval = 30 * time.Second
+
 var GormDB *gorm.DB
 
-func initSharedWishes() {
-	var count int64
-	GormDB.Model(&model.SharedWish{}).Count(&count)
-	if count == 0 {
-		wishes := []model.SharedWish{
-			{Event: "珍惜时光，享受当下", Description: "春风若有怜花意，可否许我在少年"},
-			{Event: "不忘初心，牢记使命", Description: "奋勇争先，不负韶华"},
-			{Event: "Be confident all the time", Description: "仰天大笑出门去，我辈岂是蓬蒿人"},
-			{Event: "来一场说走就走的旅行吧", Description: ""},
-			{Event: "永远相信美好的事情即将发生", Description: "只要出发了，我们就在通往胜利的路上"},
-			{Event: "己所不欲，勿施于人", Description: ""},
-			{Event: "穷则兼善其身，达则兼济天下", Description: "天下兴亡，匹夫有责"},
-			{Event: "永远积极向上，永远热泪盈眶，永远豪情满怀，永远坦坦荡荡", Description: ""},
-			{Event: "把我的技能包点满☺☺☺", Description: "不忘初心，牢记使命"},
-			{Event: "柴米油盐皆是诗，无灾无难是最重", Description: "如果快乐太难，祝你我都平平安安"},
+// openWithRetry 按指数退避重试连接，避免数据库晚于应用启动时直接失败
+func openWithRetry(dialector gorm.Dialector, cfg config.DatabaseConfig) (*gorm.DB, error) {
+	interval := cfg.ConnectRetryInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= cfg.ConnectRetries; attempt++ {
+		if attempt > 0 {
+			fmt.Printf("数据库连接失败 (第 %d 次): %v，%v 后重试\n", attempt, lastErr, interval)
+			time.Sleep(interval)
+			interval *= 2
+			if interval > maxRetryInterval {
+				interval = maxRetryInterval
+			}
 		}
 
-		for _, wish := range wishes {
-			GormDB.Create(&wish)
+		db, err := gorm.Open(dialector, &gorm.Config{
+			// 所有时间统一以 UTC 存储，按天计算时再转换到用户时区
+			NowFunc: func() time.Time { return time.Now().UTC() },
+		})
+		if err == nil {
+			return db, nil
 		}
+		lastErr = err
 	}
+	return nil, lastErr
 }
 
-func InitGormDB() error {
-	dsn := "root:268968&&ABc@tcp(localhost:3306)/pisa_list?charset=utf8mb4&parseTime=True&loc=Local"
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+// configurePool 将连接池配置应用到底层 sql.DB
+func configurePool(db *gorm.DB, cfg config.DatabaseConfig) error {
+	sqlDB, err := db.DB()
 	if err != nil {
-		return fmt.Errorf("连接数据库失败: %v", err)
+		return err
+	}
+	if cfg.Driver == DriverSQLite {
+		// SQLite 同一时间只允许一个写连接；内存库必须保持唯一连接常驻
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		if IsMemorySQLite(cfg) {
+			sqlDB.SetConnMaxLifetime(0)
+			sqlDB.SetConnMaxIdleTime(0)
+			return nil
+		}
+	} else {
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
 	}
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	return nil
+}
 
-	// 先创建数据库（如果不存在）
-	err = db.Exec("CREATE DATABASE IF NOT EXISTS pisa_list").Error
+func InitGormDB(cfg config.DatabaseConfig) error {
+	dialector, err := Dialector(cfg)
 	if err != nil {
-		return fmt.Errorf("创建数据库失败: %v", err)
+		return err
 	}
 
-	// 自动迁移
-	err = db.AutoMigrate(&model.Task{}, &model.Wish{}, &model.SharedWish{}, &model.User{})
+	db, err := openWithRetry(dialector, cfg)
 	if err != nil {
-		return fmt.Errorf("数据库迁移失败: %v", err)
+		return fmt.Errorf("连接数据库失败: %v", err)
 	}
 
-	GormDB = db
+	if err := configurePool(db, cfg); err != nil {
+		return fmt.Errorf("配置连接池失败: %v", err)
+	}
+
+	// 先创建数据库（如果不存在）
+	if cfg.Driver == DriverMySQL {
+		err = db.Exec(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", cfg.DBName)).Error
+		if err != nil {
+			return fmt.Errorf("创建数据库失败: %v", err)
+		}
+	}
 
-	// 初始化心愿社区数据
-	initSharedWishes()
+	GormDB = db
 
 	return nil
 }
+
+// Close 关闭数据库连接池
+func Close() error {
+	if GormDB == nil {
+		return nil
+	}
+	sqlDB, err := GormDB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
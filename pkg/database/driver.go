@@ -0,0 +1,88 @@
+package database
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PisaListBE/pkg/config"
+	"github.com/glebarez/sqlite"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// 支持的数据库驱动
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Dialector 根据配置选择对应的 gorm 驱动
+func Dialector(cfg config.DatabaseConfig) (gorm.Dialector, error) {
+	dsn := BuildDSN(cfg)
+	switch cfg.Driver {
+	case DriverMySQL:
+		return mysql.Open(dsn), nil
+	case DriverPostgres:
+		return postgres.Open(dsn), nil
+	case DriverSQLite:
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %q", cfg.Driver)
+	}
+}
+
+// BuildDSN 根据配置生成连接串，配置了 database.dsn 时直接使用
+func BuildDSN(cfg config.DatabaseConfig) string {
+	if cfg.DSN != "" {
+		return cfg.DSN
+	}
+	switch cfg.Driver {
+	case DriverPostgres:
+		return postgresDSN(cfg)
+	case DriverSQLite:
+		return cfg.DBName + ".db"
+	default:
+		return mysqlDSN(cfg)
+	}
+}
+
+// mysqlDSN 用户名和密码中的特殊字符由驱动负责转义
+func mysqlDSN(cfg config.DatabaseConfig) string {
+	c := mysqldriver.NewConfig()
+	c.User = cfg.Username
+	c.Passwd = cfg.Password
+	c.Net = "tcp"
+	c.Addr = net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	c.DBName = cfg.DBName
+	c.ParseTime = true
+	c.Loc = time.UTC
+	c.Params = map[string]string{"charset": "utf8mb4"}
+	return c.FormatDSN()
+}
+
+func postgresDSN(cfg config.DatabaseConfig) string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.Username, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:     cfg.DBName,
+		RawQuery: url.Values{"sslmode": {cfg.SSLMode}}.Encode(),
+	}
+	return u.String()
+}
+
+// IsMemorySQLite 判断是否为内存 SQLite，内存库在最后一个连接关闭时即被销毁
+func IsMemorySQLite(cfg config.DatabaseConfig) bool {
+	if cfg.Driver != DriverSQLite {
+		return false
+	}
+	dsn := BuildDSN(cfg)
+	return strings.Contains(dsn, ":memory:") || strings.Contains(dsn, "mode=memory")
+}
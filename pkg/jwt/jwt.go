@@ -4,8 +4,13 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/PisaListBE/pkg/config"
 	"github.com/dgrijalva/jwt-go"
-	"github.com/spf13/viper"
+)
+
+var (
+	jwtSecret   []byte
+	expireHours int
 )
 
 type Claims struct {
@@ -13,17 +18,23 @@ type Claims struct {
 	jwt.StandardClaims
 }
 
+// Init 使用配置初始化签名密钥和过期时间
+func Init(cfg config.JWTConfig) {
+	jwtSecret = []byte(cfg.Secret)
+	expireHours = cfg.Expire
+}
+
 func GenerateToken(userID uint) (string, error) {
 	nowTime := time.Now()
-	expireHours := viper.GetInt("jwt.expire")
-	if expireHours <= 0 {
-		expireHours = 24 // 默认24小时
+	hours := expireHours
+	if hours <= 0 {
+		hours = 24 // 默认24小时
 	}
-	expireTime := nowTime.Add(time.Duration(expireHours) * time.Hour)
+	expireTime := nowTime.Add(time.Duration(hours) * time.Hour)
 
 	fmt.Printf("Token generation details:\n")
 	fmt.Printf("Current time: %v\n", nowTime)
-	fmt.Printf("Expire hours: %d\n", expireHours)
+	fmt.Printf("Expire hours: %d\n", hours)
 	fmt.Printf("Expire time: %v\n", expireTime)
 
 	claims := Claims{
@@ -36,7 +47,7 @@ func GenerateToken(userID uint) (string, error) {
 	}
 
 	tokenClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	token, err := tokenClaims.SignedString([]byte(viper.GetString("jwt.secret")))
+	token, err := tokenClaims.SignedString(jwtSecret)
 
 	if err != nil {
 		fmt.Printf("Error generating token: %v\n", err)
@@ -49,7 +60,7 @@ func GenerateToken(userID uint) (string, error) {
 
 func ParseToken(token string) (*Claims, error) {
 	tokenClaims, err := jwt.ParseWithClaims(token, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(viper.GetString("jwt.secret")), nil
+		return jwtSecret, nil
 	})
 
 	if err != nil {
@@ -57,10 +68,12 @@ func ParseToken(token string) (*Claims, error) {
 		fmt.Printf("Current time: %v\n", time.Now())
 		if ve, ok := err.(*jwt.ValidationError); ok {
 			fmt.Printf("Validation error type: %v\n", ve.Errors)
-			claims, _ := tokenClaims.Claims.(*Claims)
-			if claims != nil {
-				fmt.Printf("Token expire time: %v\n", time.Unix(claims.ExpiresAt, 0))
-				fmt.Printf("Token issue time: %v\n", time.Unix(claims.IssuedAt, 0))
+			// 格式错误的 token 解析不出 tokenClaims
+			if tokenClaims != nil {
+				if claims, _ := tokenClaims.Claims.(*Claims); claims != nil {
+					fmt.Printf("Token expire time: %v\n", time.Unix(claims.ExpiresAt, 0))
+					fmt.Printf("Token issue time: %v\n", time.Unix(claims.IssuedAt, 0))
+				}
 			}
 		}
 	}
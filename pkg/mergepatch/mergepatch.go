@@ -0,0 +1,56 @@
+// Package mergepatch 实现 JSON Merge Patch（RFC 7396），用于 PATCH 接口的部分更新
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// Apply 按 JSON Merge Patch（RFC 7396）把 patch 合并到 doc 上并返回结果：
+// patch 中值为 null 的成员从结果中删除，对象递归合并，其他值（包括数组）整体替换。
+// patch 不是对象时整体替换 doc。
+func Apply(doc, patch []byte) ([]byte, error) {
+	p, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+	d, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(d, p))
+}
+
+// decode 解析 JSON，数字保留原文，避免大整数经过 float64 后变成科学计数法
+func decode(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("JSON 之后有多余内容")
+	}
+	return v, nil
+}
+
+func merge(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = merge(t[k], v)
+	}
+	return t
+}
@@ -0,0 +1,73 @@
+package migrate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 以下结构体是 0001 版本时的表结构快照，之后模型变更不应修改这里
+
+type task0001 struct {
+	ID              uint `gorm:"primarykey"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       *time.Time `gorm:"index"`
+	UserID          uint       `gorm:"not null"`
+	Event           string     `gorm:"type:varchar(256);not null"`
+	Completed       bool       `gorm:"default:false"`
+	IsCycle         bool       `gorm:"default:false"`
+	Description     string     `gorm:"type:text"`
+	ImportanceLevel int        `gorm:"default:0"`
+	CompletedDate   time.Time  `gorm:"column:completed_date;default:null"`
+}
+
+func (task0001) TableName() string { return "tasks" }
+
+type wish0001 struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   *time.Time
+	UserID      uint   `gorm:"not null"`
+	Event       string `gorm:"type:varchar(256);not null"`
+	IsCycle     bool   `gorm:"default:false"`
+	Description string `gorm:"type:text"`
+	IsShared    bool   `gorm:"default:false"`
+}
+
+func (wish0001) TableName() string { return "wishes" }
+
+type sharedWish0001 struct {
+	ID             uint `gorm:"primarykey"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      *time.Time
+	OriginalWishID uint   `gorm:"not null"`
+	Event          string `gorm:"type:varchar(256);not null"`
+	Description    string `gorm:"type:text"`
+	SharedByUserID uint   `gorm:"not null"`
+}
+
+func (sharedWish0001) TableName() string { return "shared_wishes" }
+
+type user0001 struct {
+	gorm.Model
+	Username string `gorm:"type:varchar(32);uniqueIndex;not null"`
+	Password string `gorm:"type:varchar(255);not null"`
+	Email    string `gorm:"type:varchar(255);uniqueIndex;not null"`
+}
+
+func (user0001) TableName() string { return "users" }
+
+// m0001Init 初始表结构。使用 AutoMigrate 以兼容此前由 AutoMigrate 创建过表的数据库
+var m0001Init = Migration{
+	Version: "0001",
+	Name:    "init",
+	Up: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(&task0001{}, &wish0001{}, &sharedWish0001{}, &user0001{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&user0001{}, &sharedWish0001{}, &wish0001{}, &task0001{})
+	},
+}
@@ -0,0 +1,30 @@
+package migrate
+
+import (
+	"gorm.io/gorm"
+)
+
+type wish0002 struct {
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (wish0002) TableName() string { return "wishes" }
+
+// m0002SoftDelete 任务和心愿改为软删除，为 wishes.deleted_at 补充索引
+var m0002SoftDelete = Migration{
+	Version: "0002",
+	Name:    "soft_delete",
+	Up: func(tx *gorm.DB) error {
+		if tx.Migrator().HasIndex(&wish0002{}, "DeletedAt") {
+			return nil
+		}
+		return tx.Migrator().CreateIndex(&wish0002{}, "DeletedAt")
+	},
+	Down: func(tx *gorm.DB) error {
+		// SQLite 回滚之后的迁移时会重建 wishes 表，索引可能已经不存在
+		if !tx.Migrator().HasIndex(&wish0002{}, "DeletedAt") {
+			return nil
+		}
+		return tx.Migrator().DropIndex(&wish0002{}, "DeletedAt")
+	},
+}
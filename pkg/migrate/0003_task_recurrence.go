@@ -0,0 +1,30 @@
+package migrate
+
+import (
+	"gorm.io/gorm"
+)
+
+type task0003 struct {
+	Recurrence string `gorm:"type:varchar(255);not null;default:''"`
+}
+
+func (task0003) TableName() string { return "tasks" }
+
+// m0003TaskRecurrence 为循环任务增加重复规则，已有的循环任务按每天重复处理
+var m0003TaskRecurrence = Migration{
+	Version: "0003",
+	Name:    "task_recurrence",
+	Up: func(tx *gorm.DB) error {
+		if !tx.Migrator().HasColumn(&task0003{}, "Recurrence") {
+			if err := tx.Migrator().AddColumn(&task0003{}, "Recurrence"); err != nil {
+				return err
+			}
+		}
+		return tx.Table("tasks").
+			Where("is_cycle = ? AND recurrence = ?", true, "").
+			Update("recurrence", "FREQ=DAILY").Error
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&task0003{}, "Recurrence")
+	},
+}
@@ -0,0 +1,62 @@
+package migrate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type taskCompletion0004 struct {
+	ID             uint `gorm:"primarykey"`
+	CreatedAt      time.Time
+	TaskID         uint      `gorm:"not null;uniqueIndex:idx_task_completions_task_date"`
+	UserID         uint      `gorm:"not null;index"`
+	OccurrenceDate string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_task_completions_task_date"`
+	CompletedAt    time.Time `gorm:"not null;index"`
+}
+
+func (taskCompletion0004) TableName() string { return "task_completions" }
+
+// m0004TaskCompletions 记录每一次完成，并把已完成任务的完成时间迁移为第一条记录
+var m0004TaskCompletions = Migration{
+	Version: "0004",
+	Name:    "task_completions",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Migrator().CreateTable(&taskCompletion0004{}); err != nil {
+			return err
+		}
+
+		var done []struct {
+			ID            uint
+			UserID        uint
+			CompletedDate time.Time
+		}
+		err := tx.Table("tasks").
+			Select("id, user_id, completed_date").
+			Where("completed = ? AND completed_date IS NOT NULL", true).
+			Scan(&done).Error
+		if err != nil {
+			return err
+		}
+
+		completions := make([]taskCompletion0004, 0, len(done))
+		for _, t := range done {
+			if t.CompletedDate.IsZero() {
+				continue
+			}
+			completions = append(completions, taskCompletion0004{
+				TaskID:         t.ID,
+				UserID:         t.UserID,
+				OccurrenceDate: t.CompletedDate.Format("2006-01-02"),
+				CompletedAt:    t.CompletedDate,
+			})
+		}
+		if len(completions) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(completions, 500).Error
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&taskCompletion0004{})
+	},
+}
@@ -0,0 +1,48 @@
+package migrate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type task0005 struct {
+	StartAt *time.Time
+	DueAt   *time.Time `gorm:"index"`
+}
+
+func (task0005) TableName() string { return "tasks" }
+
+// m0005TaskDates 为任务增加开始时间和截止时间
+var m0005TaskDates = Migration{
+	Version: "0005",
+	Name:    "task_dates",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		for _, field := range []string{"StartAt", "DueAt"} {
+			if m.HasColumn(&task0005{}, field) {
+				continue
+			}
+			if err := m.AddColumn(&task0005{}, field); err != nil {
+				return err
+			}
+		}
+		if m.HasIndex(&task0005{}, "DueAt") {
+			return nil
+		}
+		return m.CreateIndex(&task0005{}, "DueAt")
+	},
+	Down: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		// SQLite 回滚之后的迁移时会重建 tasks 表，索引可能已经不存在
+		if m.HasIndex(&task0005{}, "DueAt") {
+			if err := m.DropIndex(&task0005{}, "DueAt"); err != nil {
+				return err
+			}
+		}
+		if err := m.DropColumn(&task0005{}, "DueAt"); err != nil {
+			return err
+		}
+		return m.DropColumn(&task0005{}, "StartAt")
+	},
+}
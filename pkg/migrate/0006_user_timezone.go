@@ -0,0 +1,26 @@
+package migrate
+
+import (
+	"gorm.io/gorm"
+)
+
+type user0006 struct {
+	Timezone string `gorm:"type:varchar(64);not null;default:''"`
+}
+
+func (user0006) TableName() string { return "users" }
+
+// m0006UserTimezone 为用户增加时区设置
+var m0006UserTimezone = Migration{
+	Version: "0006",
+	Name:    "user_timezone",
+	Up: func(tx *gorm.DB) error {
+		if tx.Migrator().HasColumn(&user0006{}, "Timezone") {
+			return nil
+		}
+		return tx.Migrator().AddColumn(&user0006{}, "Timezone")
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&user0006{}, "Timezone")
+	},
+}
@@ -0,0 +1,27 @@
+package migrate
+
+import (
+	"gorm.io/gorm"
+)
+
+type taskCompletion0007 struct {
+	UserID         uint   `gorm:"index:idx_task_completions_user_date,priority:1"`
+	OccurrenceDate string `gorm:"type:varchar(10);index:idx_task_completions_user_date,priority:2"`
+}
+
+func (taskCompletion0007) TableName() string { return "task_completions" }
+
+// m0007CompletionUserDateIndex 为按日期范围查询和分组统计时间线增加 (user_id, occurrence_date) 索引
+var m0007CompletionUserDateIndex = Migration{
+	Version: "0007",
+	Name:    "completion_user_date_index",
+	Up: func(tx *gorm.DB) error {
+		if tx.Migrator().HasIndex(&taskCompletion0007{}, "idx_task_completions_user_date") {
+			return nil
+		}
+		return tx.Migrator().CreateIndex(&taskCompletion0007{}, "idx_task_completions_user_date")
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropIndex(&taskCompletion0007{}, "idx_task_completions_user_date")
+	},
+}
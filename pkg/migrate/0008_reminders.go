@@ -0,0 +1,51 @@
+package migrate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type reminder0008 struct {
+	ID            uint `gorm:"primarykey"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	TaskID        uint `gorm:"not null;index"`
+	UserID        uint `gorm:"not null;index"`
+	RemindAt      *time.Time
+	OffsetMinutes *int
+	FireAt        *time.Time `gorm:"index"`
+	Channels      string     `gorm:"type:varchar(64);not null;default:''"`
+	Delivered     string     `gorm:"type:varchar(64);not null;default:''"`
+	SentAt        *time.Time
+	Attempts      int    `gorm:"not null;default:0"`
+	LastError     string `gorm:"type:varchar(512);not null;default:''"`
+}
+
+func (reminder0008) TableName() string { return "reminders" }
+
+type notification0008 struct {
+	ID         uint `gorm:"primarykey"`
+	CreatedAt  time.Time
+	UserID     uint       `gorm:"not null;index:idx_notifications_user_read,priority:1"`
+	Type       string     `gorm:"type:varchar(32);not null"`
+	Title      string     `gorm:"type:varchar(255);not null"`
+	Body       string     `gorm:"type:text"`
+	Resource   string     `gorm:"type:varchar(32);not null;default:''"`
+	ResourceID uint       `gorm:"not null;default:0"`
+	ReadAt     *time.Time `gorm:"index:idx_notifications_user_read,priority:2"`
+}
+
+func (notification0008) TableName() string { return "notifications" }
+
+// m0008Reminders 增加任务提醒和站内通知
+var m0008Reminders = Migration{
+	Version: "0008",
+	Name:    "reminders",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&reminder0008{}, &notification0008{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&notification0008{}, &reminder0008{})
+	},
+}
@@ -0,0 +1,67 @@
+package migrate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type notification0009 struct {
+	UserID   uint    `gorm:"uniqueIndex:idx_notifications_user_dedup,priority:1"`
+	DedupKey *string `gorm:"type:varchar(64);uniqueIndex:idx_notifications_user_dedup,priority:2"`
+}
+
+func (notification0009) TableName() string { return "notifications" }
+
+type sharedWish0009 struct {
+	LikeCount int `gorm:"not null;default:0"`
+}
+
+func (sharedWish0009) TableName() string { return "shared_wishes" }
+
+type sharedWishLike0009 struct {
+	ID           uint `gorm:"primarykey"`
+	CreatedAt    time.Time
+	SharedWishID uint `gorm:"not null;uniqueIndex:idx_shared_wish_likes_wish_user,priority:1"`
+	UserID       uint `gorm:"not null;uniqueIndex:idx_shared_wish_likes_wish_user,priority:2"`
+}
+
+func (sharedWishLike0009) TableName() string { return "shared_wish_likes" }
+
+// m0009NotificationEvents 为通知增加去重键，并增加社区心愿点赞
+var m0009NotificationEvents = Migration{
+	Version: "0009",
+	Name:    "notification_events",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if !m.HasColumn(&notification0009{}, "DedupKey") {
+			if err := m.AddColumn(&notification0009{}, "DedupKey"); err != nil {
+				return err
+			}
+		}
+		if !m.HasIndex(&notification0009{}, "idx_notifications_user_dedup") {
+			if err := m.CreateIndex(&notification0009{}, "idx_notifications_user_dedup"); err != nil {
+				return err
+			}
+		}
+		if !m.HasColumn(&sharedWish0009{}, "LikeCount") {
+			if err := m.AddColumn(&sharedWish0009{}, "LikeCount"); err != nil {
+				return err
+			}
+		}
+		return m.CreateTable(&sharedWishLike0009{})
+	},
+	Down: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if err := m.DropTable(&sharedWishLike0009{}); err != nil {
+			return err
+		}
+		if err := m.DropColumn(&sharedWish0009{}, "LikeCount"); err != nil {
+			return err
+		}
+		if err := m.DropIndex(&notification0009{}, "idx_notifications_user_dedup"); err != nil {
+			return err
+		}
+		return m.DropColumn(&notification0009{}, "DedupKey")
+	},
+}
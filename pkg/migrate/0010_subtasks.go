@@ -0,0 +1,68 @@
+package migrate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type task0010 struct {
+	ParentID     *uint `gorm:"index"`
+	AutoComplete bool  `gorm:"not null;default:false"`
+}
+
+func (task0010) TableName() string { return "tasks" }
+
+type checklistItem0010 struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	TaskID      uint   `gorm:"not null;index"`
+	Content     string `gorm:"type:varchar(256);not null"`
+	Completed   bool   `gorm:"not null;default:false"`
+	CompletedAt *time.Time
+	Position    int `gorm:"not null;default:0"`
+}
+
+func (checklistItem0010) TableName() string { return "checklist_items" }
+
+// m0010Subtasks 支持任务嵌套和任务下的清单项
+var m0010Subtasks = Migration{
+	Version: "0010",
+	Name:    "subtasks",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		for _, field := range []string{"ParentID", "AutoComplete"} {
+			if m.HasColumn(&task0010{}, field) {
+				continue
+			}
+			if err := m.AddColumn(&task0010{}, field); err != nil {
+				return err
+			}
+		}
+		if !m.HasIndex(&task0010{}, "ParentID") {
+			if err := m.CreateIndex(&task0010{}, "ParentID"); err != nil {
+				return err
+			}
+		}
+		return m.CreateTable(&checklistItem0010{})
+	},
+	Down: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if err := m.DropTable(&checklistItem0010{}); err != nil {
+			return err
+		}
+		// SQLite 回滚之后的迁移时会重建 tasks 表，索引可能已经不存在
+		if m.HasIndex(&task0010{}, "ParentID") {
+			if err := m.DropIndex(&task0010{}, "ParentID"); err != nil {
+				return err
+			}
+		}
+		for _, field := range []string{"AutoComplete", "ParentID"} {
+			if err := m.DropColumn(&task0010{}, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,54 @@
+package migrate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type tag0011 struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	UserID    uint           `gorm:"not null;index"`
+	Name      string         `gorm:"type:varchar(64);not null"`
+	Color     string         `gorm:"type:varchar(16);not null;default:''"`
+}
+
+func (tag0011) TableName() string { return "tags" }
+
+type taskTag0011 struct {
+	TaskID uint `gorm:"primaryKey"`
+	TagID  uint `gorm:"primaryKey;index"`
+}
+
+func (taskTag0011) TableName() string { return "task_tags" }
+
+type wishTag0011 struct {
+	WishID uint `gorm:"primaryKey"`
+	TagID  uint `gorm:"primaryKey;index"`
+}
+
+func (wishTag0011) TableName() string { return "wish_tags" }
+
+type sharedWishTag0011 struct {
+	ID           uint   `gorm:"primarykey"`
+	SharedWishID uint   `gorm:"not null;index"`
+	Name         string `gorm:"type:varchar(64);not null;index"`
+	Color        string `gorm:"type:varchar(16);not null;default:''"`
+}
+
+func (sharedWishTag0011) TableName() string { return "shared_wish_tags" }
+
+// m0011Tags 新增标签及任务、心愿、社区心愿与标签的关联表
+var m0011Tags = Migration{
+	Version: "0011",
+	Name:    "tags",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&tag0011{}, &taskTag0011{}, &wishTag0011{}, &sharedWishTag0011{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&sharedWishTag0011{}, &wishTag0011{}, &taskTag0011{}, &tag0011{})
+	},
+}
@@ -0,0 +1,62 @@
+package migrate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type list0012 struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	UserID    uint           `gorm:"not null;index"`
+	Name      string         `gorm:"type:varchar(64);not null"`
+	Color     string         `gorm:"type:varchar(16);not null;default:''"`
+	Icon      string         `gorm:"type:varchar(32);not null;default:''"`
+	SortOrder int            `gorm:"not null;default:0"`
+	Archived  bool           `gorm:"not null;default:false"`
+}
+
+func (list0012) TableName() string { return "lists" }
+
+type task0012 struct {
+	ListID *uint `gorm:"index"`
+}
+
+func (task0012) TableName() string { return "tasks" }
+
+// m0012Lists 新增任务清单，任务可以属于一个清单
+var m0012Lists = Migration{
+	Version: "0012",
+	Name:    "lists",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if err := m.CreateTable(&list0012{}); err != nil {
+			return err
+		}
+		if !m.HasColumn(&task0012{}, "ListID") {
+			if err := m.AddColumn(&task0012{}, "ListID"); err != nil {
+				return err
+			}
+		}
+		if !m.HasIndex(&task0012{}, "ListID") {
+			return m.CreateIndex(&task0012{}, "ListID")
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		// SQLite 回滚之后的迁移时会重建 tasks 表，索引可能已经不存在
+		if m.HasIndex(&task0012{}, "ListID") {
+			if err := m.DropIndex(&task0012{}, "ListID"); err != nil {
+				return err
+			}
+		}
+		if err := m.DropColumn(&task0012{}, "ListID"); err != nil {
+			return err
+		}
+		return m.DropTable(&list0012{})
+	},
+}
@@ -0,0 +1,71 @@
+package migrate
+
+import (
+	"github.com/PisaListBE/pkg/ordering"
+	"gorm.io/gorm"
+)
+
+type task0013 struct {
+	ID              uint   `gorm:"primarykey"`
+	UserID          uint   `gorm:"not null;index:idx_tasks_user_position,priority:1"`
+	ImportanceLevel int    `gorm:"default:0"`
+	Position        string `gorm:"type:varchar(255);not null;default:'';index:idx_tasks_user_position,priority:2"`
+}
+
+func (task0013) TableName() string { return "tasks" }
+
+// m0013TaskPosition 新增手动排序键 position，按原来用作排序的 importance_level 初始化，
+// 然后把 importance_level 限制回 0–5 的优先级范围。
+// 回滚只删除 position，超出范围的 importance_level 已被改写，无法恢复原值
+var m0013TaskPosition = Migration{
+	Version: "0013",
+	Name:    "task_position",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if !m.HasColumn(&task0013{}, "Position") {
+			if err := m.AddColumn(&task0013{}, "Position"); err != nil {
+				return err
+			}
+		}
+		if !m.HasIndex(&task0013{}, "idx_tasks_user_position") {
+			if err := m.CreateIndex(&task0013{}, "idx_tasks_user_position"); err != nil {
+				return err
+			}
+		}
+
+		var tasks []task0013
+		if err := tx.Order("user_id asc, importance_level asc, id asc").Find(&tasks).Error; err != nil {
+			return err
+		}
+		for start := 0; start < len(tasks); {
+			end := start
+			for end < len(tasks) && tasks[end].UserID == tasks[start].UserID {
+				end++
+			}
+			for i, key := range ordering.Sequence(end - start) {
+				if err := tx.Model(&tasks[start+i]).Update("position", key).Error; err != nil {
+					return err
+				}
+			}
+			start = end
+		}
+
+		if err := tx.Model(&task0013{}).Where("importance_level < ?", 0).Update("importance_level", 0).Error; err != nil {
+			return err
+		}
+		return tx.Model(&task0013{}).Where("importance_level > ?", 5).Update("importance_level", 5).Error
+	},
+	Down: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		// SQLite 回滚 0015、0016 时重建 tasks 表，这个索引可能已经不存在
+		if m.HasIndex(&task0013{}, "idx_tasks_user_position") {
+			if err := m.DropIndex(&task0013{}, "idx_tasks_user_position"); err != nil {
+				return err
+			}
+		}
+		if !m.HasColumn(&task0013{}, "Position") {
+			return nil
+		}
+		return m.DropColumn(&task0013{}, "Position")
+	},
+}
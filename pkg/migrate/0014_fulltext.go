@@ -0,0 +1,46 @@
+package migrate
+
+import (
+	"gorm.io/gorm"
+)
+
+// fulltextTables 需要全文索引的表，索引列均为 (event, description)
+var fulltextTables = []string{"tasks", "wishes", "shared_wishes"}
+
+func fulltextIndex(table string) string { return "ft_" + table + "_text" }
+
+// m0014Fulltext 为 search.backend=mysql 创建 FULLTEXT ngram 索引，支持中文分词。
+// 只在 MySQL 上执行，其他数据库使用 Bleve 后端，不需要数据库索引。
+var m0014Fulltext = Migration{
+	Version: "0014",
+	Name:    "fulltext",
+	Up: func(tx *gorm.DB) error {
+		if tx.Dialector.Name() != "mysql" {
+			return nil
+		}
+		for _, table := range fulltextTables {
+			if tx.Migrator().HasIndex(table, fulltextIndex(table)) {
+				continue
+			}
+			err := tx.Exec("CREATE FULLTEXT INDEX " + fulltextIndex(table) + " ON " + table + " (event, description) WITH PARSER ngram").Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		if tx.Dialector.Name() != "mysql" {
+			return nil
+		}
+		for _, table := range fulltextTables {
+			if !tx.Migrator().HasIndex(table, fulltextIndex(table)) {
+				continue
+			}
+			if err := tx.Migrator().DropIndex(table, fulltextIndex(table)); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,44 @@
+package migrate
+
+import (
+	"gorm.io/gorm"
+)
+
+type task0015 struct {
+	Version uint `gorm:"not null;default:1"`
+}
+
+func (task0015) TableName() string { return "tasks" }
+
+type wish0015 struct {
+	Version uint `gorm:"not null;default:1"`
+}
+
+func (wish0015) TableName() string { return "wishes" }
+
+// m0015Versions 为任务和心愿增加版本号，用于 ETag 和 If-Match 乐观并发控制，已有记录从 1 开始
+var m0015Versions = Migration{
+	Version: "0015",
+	Name:    "versions",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		for _, value := range []interface{}{&task0015{}, &wish0015{}} {
+			if m.HasColumn(value, "Version") {
+				continue
+			}
+			if err := m.AddColumn(value, "Version"); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		for _, value := range []interface{}{&wish0015{}, &task0015{}} {
+			if err := m.DropColumn(value, "Version"); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,80 @@
+package migrate
+
+import (
+	"gorm.io/gorm"
+)
+
+type task0016 struct {
+	UserID   uint    `gorm:"not null;uniqueIndex:idx_tasks_user_client,priority:1"`
+	ClientID *string `gorm:"type:varchar(64);uniqueIndex:idx_tasks_user_client,priority:2"`
+}
+
+func (task0016) TableName() string { return "tasks" }
+
+type wish0016 struct {
+	UserID   uint    `gorm:"not null;uniqueIndex:idx_wishes_user_client,priority:1"`
+	ClientID *string `gorm:"type:varchar(64);uniqueIndex:idx_wishes_user_client,priority:2"`
+}
+
+func (wish0016) TableName() string { return "wishes" }
+
+type list0016 struct {
+	UserID   uint    `gorm:"not null;uniqueIndex:idx_lists_user_client,priority:1"`
+	ClientID *string `gorm:"type:varchar(64);uniqueIndex:idx_lists_user_client,priority:2"`
+}
+
+func (list0016) TableName() string { return "lists" }
+
+type tag0016 struct {
+	UserID   uint    `gorm:"not null;uniqueIndex:idx_tags_user_client,priority:1"`
+	ClientID *string `gorm:"type:varchar(64);uniqueIndex:idx_tags_user_client,priority:2"`
+}
+
+func (tag0016) TableName() string { return "tags" }
+
+// clientIDTables 需要客户端ID的表及其 (user_id, client_id) 唯一索引
+var clientIDTables = []struct {
+	value interface{}
+	index string
+}{
+	{&task0016{}, "idx_tasks_user_client"},
+	{&wish0016{}, "idx_wishes_user_client"},
+	{&list0016{}, "idx_lists_user_client"},
+	{&tag0016{}, "idx_tags_user_client"},
+}
+
+// m0016ClientIDs 为任务、心愿、清单和标签增加离线客户端生成的ID。
+// 同一用户内唯一，重复提交同一个创建操作时据此返回已创建的记录；已有记录为 NULL，不受唯一约束限制
+var m0016ClientIDs = Migration{
+	Version: "0016",
+	Name:    "client_ids",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		for _, t := range clientIDTables {
+			if !m.HasColumn(t.value, "ClientID") {
+				if err := m.AddColumn(t.value, "ClientID"); err != nil {
+					return err
+				}
+			}
+			if !m.HasIndex(t.value, t.index) {
+				if err := m.CreateIndex(t.value, t.index); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		for i := len(clientIDTables) - 1; i >= 0; i-- {
+			t := clientIDTables[i]
+			if err := m.DropIndex(t.value, t.index); err != nil {
+				return err
+			}
+			if err := m.DropColumn(t.value, "ClientID"); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
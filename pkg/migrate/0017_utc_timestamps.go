@@ -0,0 +1,157 @@
+package migrate
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// utcBatchSize 每批转换的行数
+const utcBatchSize = 500
+
+// m0017UTCTimestamps 将 MySQL 中已有的时间从服务器本地时间转换为 UTC。
+// 早期版本以 loc=Local 连接 MySQL，DATETIME 列保存的是服务器本地时间；现在连接改为 loc=UTC，
+// 不转换的话旧数据会整体偏移一个时区。按执行迁移的进程所在时区（TZ）换算，升级时需与旧版本保持一致。
+// PostgreSQL 与 SQLite 保存的时间带有时区信息，不需要转换；schema_migrations 只是执行记录，也不转换。
+//
+// 0004 由完成时间得到的 occurrence_date 按服务器本地日期计算，这里不重算：
+// 非循环任务取消完成时会删除该任务的全部完成记录，不依赖这个日期。
+var m0017UTCTimestamps = Migration{
+	Version: "0017",
+	Name:    "utc_timestamps",
+	Up: func(tx *gorm.DB) error {
+		return convertTimestamps(tx, func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.Local).UTC()
+		})
+	},
+	Down: func(tx *gorm.DB) error {
+		return convertTimestamps(tx, func(t time.Time) time.Time {
+			l := t.In(time.Local)
+			return time.Date(l.Year(), l.Month(), l.Day(), l.Hour(), l.Minute(), l.Second(), l.Nanosecond(), time.UTC)
+		})
+	},
+}
+
+// convertTimestamps 对所有带 id 主键的表中的 DATETIME/TIMESTAMP 列逐行应用 convert
+func convertTimestamps(tx *gorm.DB, convert func(time.Time) time.Time) error {
+	if tx.Dialector.Name() != "mysql" {
+		return nil
+	}
+	if isUTC(time.Local) {
+		return nil
+	}
+
+	tables, err := tx.Migrator().GetTables()
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if table == (SchemaMigration{}).TableName() {
+			continue
+		}
+		columns, err := timeColumns(tx, table)
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		if err := convertTable(tx, table, columns, convert); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isUTC 判断时区今年冬夏两季都是零偏移，此时转换前后相同
+func isUTC(loc *time.Location) bool {
+	year := time.Now().Year()
+	for _, month := range []time.Month{time.January, time.July} {
+		if _, offset := time.Date(year, month, 1, 0, 0, 0, 0, loc).Zone(); offset != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// timeColumns 返回表中的时间列，表没有 id 列时返回空
+func timeColumns(tx *gorm.DB, table string) ([]string, error) {
+	types, err := tx.Migrator().ColumnTypes(table)
+	if err != nil {
+		return nil, err
+	}
+	var columns []string
+	hasID := false
+	for _, c := range types {
+		switch strings.ToUpper(c.DatabaseTypeName()) {
+		case "DATETIME", "TIMESTAMP":
+			columns = append(columns, c.Name())
+		}
+		if c.Name() == "id" {
+			hasID = true
+		}
+	}
+	if !hasID {
+		return nil, nil
+	}
+	return columns, nil
+}
+
+// convertTable 按 id 分批读取并回写，每行只处理一次，避免重复换算
+func convertTable(tx *gorm.DB, table string, columns []string, convert func(time.Time) time.Time) error {
+	var lastID uint
+	for {
+		type row struct {
+			id     uint
+			values []sql.NullTime
+		}
+		rows, err := tx.Table(table).
+			Select(append([]string{"id"}, columns...)).
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(utcBatchSize).
+			Rows()
+		if err != nil {
+			return err
+		}
+		var batch []row
+		for rows.Next() {
+			r := row{values: make([]sql.NullTime, len(columns))}
+			dest := []interface{}{&r.id}
+			for i := range r.values {
+				dest = append(dest, &r.values[i])
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return err
+			}
+			batch = append(batch, r)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, r := range batch {
+			updates := map[string]interface{}{}
+			for i, v := range r.values {
+				if v.Valid {
+					updates[columns[i]] = convert(v.Time)
+				}
+			}
+			if len(updates) == 0 {
+				continue
+			}
+			if err := tx.Table(table).Where("id = ?", r.id).UpdateColumns(updates).Error; err != nil {
+				return err
+			}
+		}
+		if len(batch) < utcBatchSize {
+			return nil
+		}
+		lastID = batch[len(batch)-1].id
+	}
+}
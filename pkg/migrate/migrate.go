@@ -0,0 +1,169 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration 一个版本化的数据库结构变更
+type Migration struct {
+	// Version 版本号，按字典序执行，例如 "0001"
+	Version string
+	// Name 简短描述
+	Name string
+	Up   func(tx *gorm.DB) error
+	Down func(tx *gorm.DB) error
+}
+
+// SchemaMigration 已执行的迁移记录
+type SchemaMigration struct {
+	Version   string    `gorm:"primaryKey;type:varchar(32)"`
+	Name      string    `gorm:"type:varchar(128);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus 迁移状态，AppliedAt 为空表示尚未执行
+type MigrationStatus struct {
+	Version   string
+	Name      string
+	AppliedAt *time.Time
+}
+
+// migrations 全部迁移，新增迁移时追加到末尾
+var migrations = []Migration{
+	m0001Init,
+	m0002SoftDelete,
+	m0003TaskRecurrence,
+	m0004TaskCompletions,
+	m0005TaskDates,
+	m0006UserTimezone,
+	m0007CompletionUserDateIndex,
+	m0008Reminders,
+	m0009NotificationEvents,
+	m0010Subtasks,
+	m0011Tags,
+	m0012Lists,
+	m0013TaskPosition,
+	m0014Fulltext,
+	m0015Versions,
+	m0016ClientIDs,
+	m0017UTCTimestamps,
+}
+
+func sorted() []Migration {
+	list := make([]Migration, len(migrations))
+	copy(list, migrations)
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list
+}
+
+func applied(db *gorm.DB) (map[string]SchemaMigration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("创建 schema_migrations 表失败: %w", err)
+	}
+
+	var records []SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("读取迁移记录失败: %w", err)
+	}
+
+	result := make(map[string]SchemaMigration, len(records))
+	for _, r := range records {
+		result[r.Version] = r
+	}
+	return result, nil
+}
+
+// Up 按版本顺序执行所有未执行的迁移，返回本次执行的迁移数量
+func Up(db *gorm.DB) (int, error) {
+	done, err := applied(db)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, m := range sorted() {
+		if _, ok := done[m.Version]; ok {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{
+				Version:   m.Version,
+				Name:      m.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("执行迁移 %s_%s 失败: %w", m.Version, m.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Down 按版本倒序回滚最近执行的 steps 个迁移，返回实际回滚的数量
+func Down(db *gorm.DB, steps int) (int, error) {
+	if steps <= 0 {
+		return 0, errors.New("回滚步数必须大于 0")
+	}
+
+	done, err := applied(db)
+	if err != nil {
+		return 0, err
+	}
+
+	list := sorted()
+	count := 0
+	for i := len(list) - 1; i >= 0 && count < steps; i-- {
+		m := list[i]
+		if _, ok := done[m.Version]; !ok {
+			continue
+		}
+		if m.Down == nil {
+			return count, fmt.Errorf("迁移 %s_%s 不支持回滚", m.Version, m.Name)
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{Version: m.Version}).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("回滚迁移 %s_%s 失败: %w", m.Version, m.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Status 返回所有迁移及其执行状态
+func Status(db *gorm.DB) ([]MigrationStatus, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []MigrationStatus
+	for _, m := range sorted() {
+		s := MigrationStatus{Version: m.Version, Name: m.Name}
+		if r, ok := done[m.Version]; ok {
+			appliedAt := r.AppliedAt
+			s.AppliedAt = &appliedAt
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
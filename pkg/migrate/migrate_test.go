@@ -0,0 +1,130 @@
+package migrate
+
+import (
+	"testing"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB 打开一个只有一个连接的内存 SQLite，连接关闭后数据即丢弃
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// appliedVersions 返回 Status 中已执行的版本号
+func appliedVersions(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	status, err := Status(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status) != len(migrations) {
+		t.Fatalf("Status 返回 %d 个迁移, want %d", len(status), len(migrations))
+	}
+	var versions []string
+	for _, s := range status {
+		if s.AppliedAt != nil {
+			versions = append(versions, s.Version)
+		}
+	}
+	return versions
+}
+
+// 执行全部迁移、逐个回滚、再全部执行一遍
+func TestUpDownUp(t *testing.T) {
+	db := openTestDB(t)
+
+	n, err := Up(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(migrations) {
+		t.Fatalf("Up 执行了 %d 个迁移, want %d", n, len(migrations))
+	}
+	if n, err := Up(db); err != nil || n != 0 {
+		t.Fatalf("重复 Up = %d, %v, want 0, nil", n, err)
+	}
+
+	list := sorted()
+	for i := len(list) - 1; i >= 0; i-- {
+		if n, err := Down(db, 1); err != nil || n != 1 {
+			t.Fatalf("回滚 %s_%s = %d, %v", list[i].Version, list[i].Name, n, err)
+		}
+		if got := appliedVersions(t, db); len(got) != i {
+			t.Fatalf("回滚 %s 后已执行 %v", list[i].Version, got)
+		}
+	}
+	if n, err := Down(db, 1); err != nil || n != 0 {
+		t.Fatalf("没有可回滚的迁移时 Down = %d, %v, want 0, nil", n, err)
+	}
+
+	if n, err := Up(db); err != nil || n != len(migrations) {
+		t.Fatalf("再次 Up = %d, %v, want %d, nil", n, err, len(migrations))
+	}
+}
+
+// 部分回滚后 Status 只把剩下的迁移标记为已执行
+func TestStatusAfterPartialDown(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := Down(db, 3); err != nil || n != 3 {
+		t.Fatalf("Down(3) = %d, %v", n, err)
+	}
+
+	list := sorted()
+	got := appliedVersions(t, db)
+	if len(got) != len(list)-3 {
+		t.Fatalf("已执行 %v, want 前 %d 个", got, len(list)-3)
+	}
+	for i, v := range got {
+		if v != list[i].Version {
+			t.Fatalf("已执行 %v, want 前 %d 个", got, len(list)-3)
+		}
+	}
+
+	if n, err := Up(db); err != nil || n != 3 {
+		t.Fatalf("Up = %d, %v, want 3, nil", n, err)
+	}
+}
+
+func TestDownInvalidSteps(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := Down(db, 0); err == nil {
+		t.Fatal("Down(0) 应返回错误")
+	}
+}
+
+func TestSeedIdempotent(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := Up(db); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := Seed(db); err != nil {
+			t.Fatal(err)
+		}
+		var count int64
+		if err := db.Model(&model.SharedWish{}).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != int64(len(sharedWishSeeds)) {
+			t.Fatalf("第 %d 次 Seed 后有 %d 条社区心愿, want %d", i+1, count, len(sharedWishSeeds))
+		}
+	}
+}
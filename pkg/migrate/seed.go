@@ -0,0 +1,37 @@
+package migrate
+
+import (
+	"fmt"
+
+	"github.com/PisaListBE/internal/model"
+	"gorm.io/gorm"
+)
+
+// sharedWishSeeds 心愿社区的初始数据
+var sharedWishSeeds = []model.SharedWish{
+	{Event: "珍惜时光，享受当下", Description: "春风若有怜花意，可否许我在少年"},
+	{Event: "不忘初心，牢记使命", Description: "奋勇争先，不负韶华"},
+	{Event: "Be confident all the time", Description: "仰天大笑出门去，我辈岂是蓬蒿人"},
+	{Event: "来一场说走就走的旅行吧", Description: ""},
+	{Event: "永远相信美好的事情即将发生", Description: "只要出发了，我们就在通往胜利的路上"},
+	{Event: "己所不欲，勿施于人", Description: ""},
+	{Event: "穷则兼善其身，达则兼济天下", Description: "天下兴亡，匹夫有责"},
+	{Event: "永远积极向上，永远热泪盈眶，永远豪情满怀，永远坦坦荡荡", Description: ""},
+	{Event: "把我的技能包点满☺☺☺", Description: "不忘初心，牢记使命"},
+	{Event: "柴米油盐皆是诗，无灾无难是最重", Description: "如果快乐太难，祝你我都平平安安"},
+}
+
+// Seed 写入初始数据，可重复执行：已存在的系统心愿不会重复插入
+func Seed(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, seed := range sharedWishSeeds {
+			wish := seed
+			err := tx.Where("event = ? AND shared_by_user_id = ?", wish.Event, 0).
+				FirstOrCreate(&wish).Error
+			if err != nil {
+				return fmt.Errorf("写入社区心愿失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
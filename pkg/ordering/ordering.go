@@ -0,0 +1,142 @@
+// Package ordering 生成用于手动排序的字典序键。
+//
+// 键由 0-9、a-z 组成，按字节比较即为排列顺序，且不以 '0' 结尾，
+// 因此任意两个键之间总能生成新的键。不使用大写字母是为了在 MySQL、PostgreSQL
+// 默认的大小写不敏感排序规则下数据库中的顺序与字节序一致。移动一个元素只需为它计算一个新键，不需要改写其他元素。
+package ordering
+
+import (
+	"errors"
+	"strings"
+)
+
+// digits 键使用的字符，按 ASCII 升序排列
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+// ErrInvalidKey 键包含非法字符、以 '0' 结尾，或者 Between 的上下界顺序不对
+var ErrInvalidKey = errors.New("无效的排序键")
+
+// Valid 判断 key 是否为合法的排序键
+func Valid(key string) bool {
+	if key == "" || key[len(key)-1] == '0' {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Between 返回严格位于 a 与 b 之间的键。a 为空表示没有下界，b 为空表示没有上界。
+func Between(a, b string) (string, error) {
+	if (a != "" && !Valid(a)) || (b != "" && !Valid(b)) || (a != "" && b != "" && a >= b) {
+		return "", ErrInvalidKey
+	}
+	switch {
+	case a == "" && b == "":
+		return string(digits[base/2]), nil
+	case b == "":
+		return After(a), nil
+	case a == "":
+		return Before(b), nil
+	}
+	return midpoint(a, b), nil
+}
+
+// After 返回大于 key 的一个较短的键，适合追加到末尾
+func After(key string) string {
+	for i := 0; i < len(key); i++ {
+		if key[i] != digits[base-1] {
+			return key[:i] + string(digits[strings.IndexByte(digits, key[i])+1])
+		}
+	}
+	return key + string(digits[base/2])
+}
+
+// Before 返回小于 key 的一个较短的键，适合插入到开头
+func Before(key string) string {
+	for i := 0; i < len(key); i++ {
+		if key[i] > digits[1] {
+			return key[:i] + string(digits[strings.IndexByte(digits, key[i])-1])
+		}
+	}
+	// key 只由 '0' 和 '1' 组成，且以 '1' 结尾
+	return key[:len(key)-1] + string(digits[0]) + string(digits[base-1])
+}
+
+// Sequence 返回 n 个等长、递增的键，用于给已有数据初始化顺序
+func Sequence(n int) []string {
+	width := 1
+	for capacity := base - 1; capacity < n; capacity *= base {
+		width++
+	}
+
+	keys := make([]string, n)
+	buf := make([]byte, width)
+	for i := range keys {
+		v := i + 1
+		for j := width - 1; j >= 0; j-- {
+			buf[j] = digits[v%base]
+			v /= base
+		}
+		// 末位为 '0' 时追加一位，保证键不以 '0' 结尾
+		key := string(buf)
+		if key[width-1] == digits[0] {
+			key += string(digits[base/2])
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+// midpoint 返回 a 与 b 之间的键，要求 a < b 且两者都不为空
+func midpoint(a, b string) string {
+	// 跳过公共前缀，a 较短时视为补 '0'
+	n := 0
+	for n < len(b) && digitAt(a, n) == b[n] {
+		n++
+	}
+	if n > 0 {
+		rest := ""
+		if n < len(a) {
+			rest = a[n:]
+		}
+		return b[:n] + midpointFrom(rest, b[n:])
+	}
+	return midpointFrom(a, b)
+}
+
+// midpointFrom 在 a 与 b 首位不同的情况下求中间键，a 可以为空，b 为空表示没有上界
+func midpointFrom(a, b string) string {
+	lo := 0
+	if a != "" {
+		lo = strings.IndexByte(digits, a[0])
+	}
+	hi := base
+	if b != "" {
+		hi = strings.IndexByte(digits, b[0])
+	}
+	if hi-lo > 1 {
+		return string(digits[(lo+hi)/2])
+	}
+	// 首位相邻：b 有多位时取 b 的首位即可，否则固定 a 的首位继续在后面求中间值
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(digits[lo]) + midpointFrom(rest, "")
+}
+
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return digits[0]
+}
@@ -0,0 +1,170 @@
+package ordering
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// checkBetween 检查 key 合法且严格位于 a 与 b 之间，空字符串表示没有边界
+func checkBetween(t *testing.T, a, b, key string) {
+	t.Helper()
+	if !Valid(key) {
+		t.Fatalf("Between(%q, %q) = %q, 不是合法的键", a, b, key)
+	}
+	if (a != "" && key <= a) || (b != "" && key >= b) {
+		t.Fatalf("Between(%q, %q) = %q, 不在两者之间", a, b, key)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"空列表", "", ""},
+		{"末尾", "i", ""},
+		{"最大的一位键之后", "z", ""},
+		{"全是最大字符之后", "zzz", ""},
+		{"开头", "", "i"},
+		{"最小的一位键之前", "", "1"},
+		{"只有 0 和 1 的键之前", "", "001"},
+		{"多位键之前", "", "1z"},
+		{"相邻的一位键", "a", "b"},
+		{"相邻的最小键", "01", "1"},
+		{"相邻的最大键", "y", "z"},
+		{"前缀关系", "a", "a1"},
+		{"前缀关系且相邻", "a", "a01"},
+		{"进位", "az", "b"},
+		{"进位后更长", "azz", "b01"},
+		{"公共前缀", "abc", "abd"},
+		{"长短不一", "a5", "b"},
+		{"间隔较大", "1", "z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := Between(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("Between(%q, %q): %v", tt.a, tt.b, err)
+			}
+			checkBetween(t, tt.a, tt.b, key)
+		})
+	}
+}
+
+func TestBetweenInvalid(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"b", "a"},
+		{"a", "a"},
+		{"a0", ""},
+		{"", "10"},
+		{"A", ""},
+		{"", "a-b"},
+		{"0", ""},
+	}
+	for _, tt := range tests {
+		if key, err := Between(tt.a, tt.b); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Between(%q, %q) = %q, %v, want ErrInvalidKey", tt.a, tt.b, key, err)
+		}
+	}
+}
+
+func TestAfterBefore(t *testing.T) {
+	for _, key := range []string{"1", "5", "i", "z", "zz", "z1", "a01", "1z"} {
+		if after := After(key); !Valid(after) || after <= key {
+			t.Errorf("After(%q) = %q", key, after)
+		}
+		if before := Before(key); !Valid(before) || before >= key {
+			t.Errorf("Before(%q) = %q", key, before)
+		}
+	}
+}
+
+// 在同一位置反复插入，键会变长，但顺序始终正确
+func TestRepeatedInsert(t *testing.T) {
+	const n = 500
+	tests := []struct {
+		name string
+		// next 返回新键的上下界，keys 为当前已排好序的键
+		next func(keys []string) (string, string)
+	}{
+		{"总在开头", func(keys []string) (string, string) { return "", keys[0] }},
+		{"总在末尾", func(keys []string) (string, string) { return keys[len(keys)-1], "" }},
+		{"总在第一个之后", func(keys []string) (string, string) { return keys[0], keys[1] }},
+		{"总在最后一个之前", func(keys []string) (string, string) { return keys[len(keys)-2], keys[len(keys)-1] }},
+		{"总在正中间", func(keys []string) (string, string) {
+			i := len(keys) / 2
+			return keys[i-1], keys[i]
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := []string{"a", "b"}
+			for i := 0; i < n; i++ {
+				a, b := tt.next(keys)
+				key, err := Between(a, b)
+				if err != nil {
+					t.Fatalf("第 %d 次 Between(%q, %q): %v", i, a, b, err)
+				}
+				checkBetween(t, a, b, key)
+				keys = append(keys, key)
+				sort.Strings(keys)
+			}
+			for _, key := range keys {
+				// 每次插入至少把区间缩小一个字符的几分之一，长度不应线性增长
+				if len(key) > n/4 {
+					t.Fatalf("键过长: %d", len(key))
+				}
+			}
+		})
+	}
+}
+
+func TestRandomInsert(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	keys := []string{}
+	for i := 0; i < 2000; i++ {
+		pos := r.Intn(len(keys) + 1)
+		var a, b string
+		if pos > 0 {
+			a = keys[pos-1]
+		}
+		if pos < len(keys) {
+			b = keys[pos]
+		}
+		key, err := Between(a, b)
+		if err != nil {
+			t.Fatalf("Between(%q, %q): %v", a, b, err)
+		}
+		checkBetween(t, a, b, key)
+		keys = append(keys[:pos], append([]string{key}, keys[pos:]...)...)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	for _, n := range []int{0, 1, base - 1, base, base * base, 3000} {
+		keys := Sequence(n)
+		if len(keys) != n {
+			t.Fatalf("Sequence(%d) 返回 %d 个键", n, len(keys))
+		}
+		for i, key := range keys {
+			if !Valid(key) {
+				t.Fatalf("Sequence(%d)[%d] = %q 不合法", n, i, key)
+			}
+			if i > 0 && key <= keys[i-1] {
+				t.Fatalf("Sequence(%d)[%d] = %q 不大于前一个 %q", n, i, key, keys[i-1])
+			}
+		}
+		// 初始化后仍能在任意位置插入
+		if n > 1 {
+			key, err := Between(keys[0], keys[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkBetween(t, keys[0], keys[1], key)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Package pagination 提供游标分页使用的游标编解码和每页数量解析
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// 每页数量的默认值和最大值
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("无效的分页游标")
+	ErrInvalidLimit  = errors.New("limit 应为 1 到 100 之间的整数")
+)
+
+// Encode 将游标位置编码为不透明的字符串，客户端只需原样传回
+func Encode(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode 解析 Encode 生成的游标，格式错误时返回 ErrInvalidCursor
+func Decode(cursor string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// ParseLimit 解析每页数量，为空时返回 DefaultLimit
+func ParseLimit(s string) (int, error) {
+	if s == "" {
+		return DefaultLimit, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > MaxLimit {
+		return 0, ErrInvalidLimit
+	}
+	return n, nil
+}
@@ -0,0 +1,292 @@
+// Package recurrence 实现循环任务使用的 iCalendar RRULE 子集。
+//
+// 支持的属性: FREQ (DAILY/WEEKLY/MONTHLY/YEARLY)、INTERVAL、BYDAY (仅 WEEKLY)、
+// BYMONTHDAY (仅 MONTHLY，负数表示倒数第几天) 和 UNTIL。
+// 另外接受 daily、weekly、monthly、yearly 作为简写。
+// 所有计算都以日期为单位，时刻部分会被忽略。
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Freq 重复频率
+type Freq string
+
+const (
+	Daily   Freq = "DAILY"
+	Weekly  Freq = "WEEKLY"
+	Monthly Freq = "MONTHLY"
+	Yearly  Freq = "YEARLY"
+)
+
+// 查找下一次出现时最多向后搜索的天数
+const maxSearchDays = 366 * 5
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Rule 解析后的重复规则
+type Rule struct {
+	Freq     Freq
+	Interval int
+	// ByDay 每周的哪几天，为空时取起始日期的星期
+	ByDay []time.Weekday
+	// ByMonthDay 每月的哪几天，为空时取起始日期的日
+	ByMonthDay []int
+	// Until 最后一次可能出现的日期，零值表示不限制
+	Until time.Time
+}
+
+// Parse 解析 RRULE 字符串，允许带 "RRULE:" 前缀
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("重复规则不能为空")
+	}
+
+	switch strings.ToLower(s) {
+	case "daily", "weekly", "monthly", "yearly":
+		return &Rule{Freq: Freq(strings.ToUpper(s)), Interval: 1}, nil
+	}
+
+	s = strings.TrimPrefix(strings.ToUpper(s), "RRULE:")
+	rule := &Rule{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("无效的规则片段: %q", part)
+		}
+
+		switch key {
+		case "FREQ":
+			switch Freq(value) {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = Freq(value)
+			default:
+				return nil, fmt.Errorf("不支持的 FREQ: %s", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 366 {
+				return nil, fmt.Errorf("无效的 INTERVAL: %s", value)
+			}
+			rule.Interval = n
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, ok := weekdayCodes[code]
+				if !ok {
+					return nil, fmt.Errorf("无效的 BYDAY: %s", code)
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("无效的 BYMONTHDAY: %s", v)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = until
+		case "WKST":
+			if value != "MO" {
+				return nil, errors.New("仅支持 WKST=MO")
+			}
+		default:
+			return nil, fmt.Errorf("不支持的规则属性: %s", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, errors.New("缺少 FREQ")
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != Weekly {
+		return nil, errors.New("BYDAY 仅支持 FREQ=WEEKLY")
+	}
+	if len(rule.ByMonthDay) > 0 && rule.Freq != Monthly {
+		return nil, errors.New("BYMONTHDAY 仅支持 FREQ=MONTHLY")
+	}
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102", "20060102T150405Z", "20060102T150405"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return civil(t), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无效的 UNTIL: %s", value)
+}
+
+// Normalize 解析并返回规范化后的 RRULE 字符串
+func Normalize(s string) (string, error) {
+	rule, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return rule.String(), nil
+}
+
+// String 返回规范化的 RRULE 字符串
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]time.Weekday, len(r.ByDay))
+		copy(days, r.ByDay)
+		// 按 周一 ... 周日 排序
+		sort.Slice(days, func(i, j int) bool { return mondayIndex(days[i]) < mondayIndex(days[j]) })
+		codes := make([]string, 0, len(days))
+		for i, d := range days {
+			if i > 0 && d == days[i-1] {
+				continue
+			}
+			codes = append(codes, weekdayNames[d])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Occurs 判断规则在 day 这一天是否出现，start 为规则的起始日期
+func (r *Rule) Occurs(start, day time.Time) bool {
+	start, day = civil(start), civil(day)
+	if day.Before(start) {
+		return false
+	}
+	if !r.Until.IsZero() && day.After(r.Until) {
+		return false
+	}
+
+	switch r.Freq {
+	case Daily:
+		return daysBetween(start, day)%r.Interval == 0
+	case Weekly:
+		weeks := daysBetween(weekStart(start), weekStart(day)) / 7
+		if weeks%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 {
+			return day.Weekday() == start.Weekday()
+		}
+		for _, d := range r.ByDay {
+			if d == day.Weekday() {
+				return true
+			}
+		}
+		return false
+	case Monthly:
+		months := (day.Year()-start.Year())*12 + int(day.Month()) - int(start.Month())
+		if months%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByMonthDay) == 0 {
+			return day.Day() == start.Day()
+		}
+		last := daysInMonth(day)
+		for _, d := range r.ByMonthDay {
+			if d == day.Day() || d < 0 && last+d+1 == day.Day() {
+				return true
+			}
+		}
+		return false
+	case Yearly:
+		years := day.Year() - start.Year()
+		return years%r.Interval == 0 && day.Month() == start.Month() && day.Day() == start.Day()
+	}
+	return false
+}
+
+// Next 返回 from 当天或之后的第一次出现日期，找不到时返回 false
+func (r *Rule) Next(start, from time.Time) (time.Time, bool) {
+	day := civil(from)
+	if s := civil(start); day.Before(s) {
+		day = s
+	}
+	for i := 0; i < maxSearchDays; i++ {
+		if !r.Until.IsZero() && day.After(r.Until) {
+			return time.Time{}, false
+		}
+		if r.Occurs(start, day) {
+			return inLocation(day, from.Location()), true
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}, false
+}
+
+// Prev 返回 day 当天或之前最近的一次出现日期，找不到时返回 false
+func (r *Rule) Prev(start, day time.Time) (time.Time, bool) {
+	d := civil(day)
+	s := civil(start)
+	for i := 0; i < maxSearchDays && !d.Before(s); i++ {
+		if r.Occurs(start, d) {
+			return inLocation(d, day.Location()), true
+		}
+		d = d.AddDate(0, 0, -1)
+	}
+	return time.Time{}, false
+}
+
+// civil 取 t 在其所在时区的日期，返回该日期 UTC 零点，便于按天计算
+func civil(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// inLocation 将 civil 日期转换为 loc 时区的当天零点
+func inLocation(day time.Time, loc *time.Location) time.Time {
+	y, m, d := day.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+func daysBetween(a, b time.Time) int {
+	return int(b.Sub(a).Hours() / 24)
+}
+
+func mondayIndex(d time.Weekday) int {
+	return (int(d) + 6) % 7
+}
+
+func weekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -mondayIndex(day.Weekday()))
+}
+
+func daysInMonth(day time.Time) int {
+	return time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
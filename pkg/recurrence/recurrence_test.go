@@ -0,0 +1,309 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func mustParse(t *testing.T, s string) *Rule {
+	t.Helper()
+	rule, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", s, err)
+	}
+	return rule
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // 规范化结果，为空表示应解析失败
+	}{
+		{"daily", "FREQ=DAILY"},
+		{" Weekly ", "FREQ=WEEKLY"},
+		{"FREQ=DAILY;INTERVAL=1", "FREQ=DAILY"},
+		{"RRULE:FREQ=DAILY;INTERVAL=3", "FREQ=DAILY;INTERVAL=3"},
+		{"freq=weekly;byday=fr,mo,we,mo", "FREQ=WEEKLY;BYDAY=MO,WE,FR"},
+		{"FREQ=WEEKLY;BYDAY=SU,MO;WKST=MO", "FREQ=WEEKLY;BYDAY=MO,SU"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,-1", "FREQ=MONTHLY;BYMONTHDAY=1,-1"},
+		{"FREQ=YEARLY;INTERVAL=2", "FREQ=YEARLY;INTERVAL=2"},
+		{"FREQ=DAILY;UNTIL=20250105", "FREQ=DAILY;UNTIL=20250105"},
+		{"FREQ=DAILY;UNTIL=20250105T235959Z", "FREQ=DAILY;UNTIL=20250105"},
+		{"FREQ=DAILY;UNTIL=20250105T120000", "FREQ=DAILY;UNTIL=20250105"},
+		{"FREQ=DAILY;", "FREQ=DAILY"},
+
+		{"", ""},
+		{"hourly", ""},
+		{"INTERVAL=2", ""},
+		{"FREQ=HOURLY", ""},
+		{"FREQ=DAILY;INTERVAL=0", ""},
+		{"FREQ=DAILY;INTERVAL=367", ""},
+		{"FREQ=DAILY;INTERVAL=x", ""},
+		{"FREQ=WEEKLY;BYDAY=XX", ""},
+		{"FREQ=WEEKLY;BYDAY=1MO", ""},
+		{"FREQ=DAILY;BYDAY=MO", ""},
+		{"FREQ=MONTHLY;BYMONTHDAY=0", ""},
+		{"FREQ=MONTHLY;BYMONTHDAY=32", ""},
+		{"FREQ=MONTHLY;BYMONTHDAY=-32", ""},
+		{"FREQ=WEEKLY;BYMONTHDAY=1", ""},
+		{"FREQ=DAILY;UNTIL=2025-01-05", ""},
+		{"FREQ=WEEKLY;WKST=SU", ""},
+		{"FREQ=DAILY;COUNT=5", ""}, // COUNT 不在支持的子集中
+		{"FREQ=DAILY;INTERVAL", ""},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.in)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("Normalize(%q) = %q, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Normalize(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestOccurs(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start string
+		yes   []string
+		no    []string
+	}{
+		{
+			name:  "每天",
+			rule:  "FREQ=DAILY",
+			start: "2025-01-10",
+			yes:   []string{"2025-01-10", "2025-01-11", "2026-03-01"},
+			no:    []string{"2025-01-09"},
+		},
+		{
+			name:  "每3天从起始日期对齐",
+			rule:  "FREQ=DAILY;INTERVAL=3",
+			start: "2025-01-30",
+			yes:   []string{"2025-01-30", "2025-02-02", "2025-02-05", "2025-03-01"},
+			no:    []string{"2025-01-31", "2025-02-01", "2025-02-03", "2025-03-02"},
+		},
+		{
+			name:  "每周默认取起始日期的星期",
+			rule:  "FREQ=WEEKLY",
+			start: "2025-01-01", // 周三
+			yes:   []string{"2025-01-01", "2025-01-08", "2025-12-31"},
+			no:    []string{"2025-01-02", "2025-01-06"},
+		},
+		{
+			name:  "隔周按周一开始的自然周对齐",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+			start: "2025-01-01", // 周三，所在的周从 2024-12-30 开始
+			yes:   []string{"2025-01-01", "2025-01-13", "2025-01-15", "2025-01-27"},
+			no:    []string{"2024-12-30", "2025-01-06", "2025-01-08", "2025-01-20", "2025-01-14"},
+		},
+		{
+			name:  "每周日",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU",
+			start: "2025-01-06", // 周一，同一自然周的周日为 01-12
+			yes:   []string{"2025-01-12", "2025-01-26"},
+			no:    []string{"2025-01-05", "2025-01-19"},
+		},
+		{
+			name:  "每月31日跳过没有31日的月份",
+			rule:  "FREQ=MONTHLY",
+			start: "2025-01-31",
+			yes:   []string{"2025-01-31", "2025-03-31", "2025-05-31"},
+			no:    []string{"2025-02-28", "2025-03-03", "2025-04-30"},
+		},
+		{
+			name:  "BYMONTHDAY=31",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31",
+			start: "2025-01-01",
+			yes:   []string{"2025-01-31", "2025-03-31"},
+			no:    []string{"2025-02-28", "2025-04-30"},
+		},
+		{
+			name:  "每月最后一天",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: "2024-01-01",
+			yes:   []string{"2024-01-31", "2024-02-29", "2024-04-30", "2025-02-28"},
+			no:    []string{"2024-02-28", "2024-04-29", "2025-03-30"},
+		},
+		{
+			name:  "每月倒数第二天和1日",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=1,-2",
+			start: "2025-01-01",
+			yes:   []string{"2025-01-01", "2025-01-30", "2025-02-01", "2025-02-27"},
+			no:    []string{"2025-01-31", "2025-02-28"},
+		},
+		{
+			name:  "每季度",
+			rule:  "FREQ=MONTHLY;INTERVAL=3",
+			start: "2025-01-15",
+			yes:   []string{"2025-04-15", "2025-07-15", "2026-01-15"},
+			no:    []string{"2025-02-15", "2025-03-15", "2025-04-16"},
+		},
+		{
+			name:  "闰日只在闰年出现",
+			rule:  "FREQ=YEARLY",
+			start: "2024-02-29",
+			yes:   []string{"2024-02-29", "2028-02-29"},
+			no:    []string{"2025-02-28", "2025-03-01"},
+		},
+		{
+			name:  "每两年",
+			rule:  "FREQ=YEARLY;INTERVAL=2",
+			start: "2025-06-01",
+			yes:   []string{"2027-06-01"},
+			no:    []string{"2026-06-01", "2027-06-02"},
+		},
+		{
+			name:  "UNTIL 当天仍然出现",
+			rule:  "FREQ=DAILY;UNTIL=20250105T120000Z",
+			start: "2025-01-01",
+			yes:   []string{"2025-01-01", "2025-01-05"},
+			no:    []string{"2025-01-06", "2025-02-01"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := mustParse(t, tt.rule)
+			start := date(tt.start)
+			for _, d := range tt.yes {
+				if !rule.Occurs(start, date(d)) {
+					t.Errorf("Occurs(%s) = false, want true", d)
+				}
+			}
+			for _, d := range tt.no {
+				if rule.Occurs(start, date(d)) {
+					t.Errorf("Occurs(%s) = true, want false", d)
+				}
+			}
+		})
+	}
+}
+
+func TestNextPrev(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		start    string
+		from     string
+		wantNext string // 为空表示没有下一次
+		wantPrev string // 为空表示没有上一次
+	}{
+		{"当天出现", "FREQ=DAILY;INTERVAL=2", "2025-01-01", "2025-01-03", "2025-01-03", "2025-01-03"},
+		{"两次之间", "FREQ=DAILY;INTERVAL=2", "2025-01-01", "2025-01-04", "2025-01-05", "2025-01-03"},
+		{"早于起始日期", "FREQ=WEEKLY", "2025-01-08", "2025-01-01", "2025-01-08", ""},
+		{"月末跨月", "FREQ=MONTHLY;BYMONTHDAY=-1", "2025-01-01", "2025-02-01", "2025-02-28", "2025-01-31"},
+		{"31日跳过二月", "FREQ=MONTHLY", "2025-01-31", "2025-02-01", "2025-03-31", "2025-01-31"},
+		{"隔周", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", "2025-01-06", "2025-01-14", "2025-01-20", "2025-01-06"},
+		{"闰日", "FREQ=YEARLY", "2024-02-29", "2024-03-01", "2028-02-29", "2024-02-29"},
+		{"UNTIL 之后", "FREQ=DAILY;UNTIL=20250105", "2025-01-01", "2025-01-10", "", "2025-01-05"},
+		{"UNTIL 当天", "FREQ=DAILY;UNTIL=20250105", "2025-01-01", "2025-01-05", "2025-01-05", "2025-01-05"},
+		{"UNTIL 前没有下一次", "FREQ=WEEKLY;UNTIL=20250110", "2025-01-01", "2025-01-09", "", "2025-01-08"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := mustParse(t, tt.rule)
+			start, from := date(tt.start), date(tt.from)
+
+			next, ok := rule.Next(start, from)
+			if got := formatDay(next, ok); got != tt.wantNext {
+				t.Errorf("Next = %q, want %q", got, tt.wantNext)
+			}
+			prev, ok := rule.Prev(start, from)
+			if got := formatDay(prev, ok); got != tt.wantPrev {
+				t.Errorf("Prev = %q, want %q", got, tt.wantPrev)
+			}
+		})
+	}
+}
+
+func formatDay(t time.Time, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// 夏令时切换当天只有23或25小时，按日期计算的规则不能因此错位
+func TestDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("缺少时区数据: %v", err)
+	}
+	// 2025-03-09 和 2025-11-02 为纽约的夏令时切换日
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, loc)
+
+	daily := mustParse(t, "FREQ=DAILY")
+	every2 := mustParse(t, "FREQ=DAILY;INTERVAL=2")
+	for d := start; d.Before(time.Date(2025, 11, 10, 0, 0, 0, 0, loc)); d = d.AddDate(0, 0, 1) {
+		if !daily.Occurs(start, d) {
+			t.Fatalf("每天的规则在 %s 不出现", d.Format("2006-01-02"))
+		}
+		want := (d.YearDay()-start.YearDay())%2 == 0
+		if every2.Occurs(start, d) != want {
+			t.Fatalf("每2天的规则在 %s 出现 = %v, want %v", d.Format("2006-01-02"), !want, want)
+		}
+	}
+
+	tests := []struct {
+		rule string
+		from time.Time
+		next time.Time
+		prev time.Time
+	}{
+		{
+			rule: "FREQ=DAILY",
+			from: time.Date(2025, 3, 9, 3, 30, 0, 0, loc), // 切换后不久
+			next: time.Date(2025, 3, 9, 0, 0, 0, 0, loc),
+			prev: time.Date(2025, 3, 9, 0, 0, 0, 0, loc),
+		},
+		{
+			rule: "FREQ=DAILY;INTERVAL=2",
+			from: time.Date(2025, 11, 3, 23, 30, 0, 0, loc), // 前一天是25小时的切换日
+			next: time.Date(2025, 11, 4, 0, 0, 0, 0, loc),
+			prev: time.Date(2025, 11, 2, 0, 0, 0, 0, loc),
+		},
+		{
+			rule: "FREQ=WEEKLY",
+			from: time.Date(2025, 3, 9, 12, 0, 0, 0, loc),
+			next: time.Date(2025, 3, 15, 0, 0, 0, 0, loc),
+			prev: time.Date(2025, 3, 8, 0, 0, 0, 0, loc),
+		},
+	}
+	for _, tt := range tests {
+		rule := mustParse(t, tt.rule)
+		if next, ok := rule.Next(start, tt.from); !ok || !next.Equal(tt.next) || next.Location() != loc {
+			t.Errorf("%s: Next(%s) = %s, want %s", tt.rule, tt.from, next, tt.next)
+		}
+		if prev, ok := rule.Prev(start, tt.from); !ok || !prev.Equal(tt.prev) || prev.Location() != loc {
+			t.Errorf("%s: Prev(%s) = %s, want %s", tt.rule, tt.from, prev, tt.prev)
+		}
+	}
+}
+
+// 起始日期和当前时间按各自所在时区取日期，不会因 UTC 偏移错开一天
+func TestLocalDate(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	rule := mustParse(t, "FREQ=DAILY;INTERVAL=2")
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, shanghai) // UTC 为 2024-12-31
+	now := time.Date(2025, 1, 3, 7, 0, 0, 0, shanghai)   // UTC 为 2025-01-02
+
+	if !rule.Occurs(start, now) {
+		t.Errorf("Occurs(%s) = false, want true", now)
+	}
+	prev, ok := rule.Prev(start, now)
+	if !ok || !prev.Equal(time.Date(2025, 1, 3, 0, 0, 0, 0, shanghai)) {
+		t.Errorf("Prev = %s, %v", prev, ok)
+	}
+}
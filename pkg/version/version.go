@@ -0,0 +1,11 @@
+package version
+
+// 构建信息，通过 -ldflags 注入，例如:
+//
+//	go build -ldflags "-X github.com/PisaListBE/pkg/version.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/PisaListBE/pkg/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
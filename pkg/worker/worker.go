@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Group 管理后台任务的生命周期，Stop 时取消所有任务并等待其退出
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewGroup 创建任务组，parent 取消时所有任务也会收到取消信号
+func NewGroup(parent context.Context) *Group {
+	ctx, cancel := context.WithCancel(parent)
+	return &Group{ctx: ctx, cancel: cancel}
+}
+
+// Go 启动一个后台任务，fn 应在 ctx 取消后尽快返回
+func (g *Group) Go(name string, fn func(ctx context.Context)) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				fmt.Printf("后台任务 %s 异常退出: %v\n", name, r)
+			}
+		}()
+		fn(g.ctx)
+	}()
+}
+
+// Every 启动一个周期任务，启动时立即执行一次，之后每隔 interval 执行一次
+func (g *Group) Every(name string, interval time.Duration, fn func(ctx context.Context)) {
+	g.Go(name, func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			fn(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Stop 取消所有任务并等待退出，ctx 到期时返回 ctx.Err()
+func (g *Group) Stop(ctx context.Context) error {
+	g.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
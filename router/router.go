@@ -6,37 +6,118 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func InitRouter(r *gin.Engine) {
+// Handlers 路由使用的接口处理器
+type Handlers struct {
+	Health       *v1.HealthHandler
+	User         *v1.UserHandler
+	Task         *v1.TaskHandler
+	Wish         *v1.WishHandler
+	Trash        *v1.TrashHandler
+	Stats        *v1.StatsHandler
+	Reminder     *v1.ReminderHandler
+	Notification *v1.NotificationHandler
+	Tag          *v1.TagHandler
+	List         *v1.ListHandler
+	Search       *v1.SearchHandler
+	Sync         *v1.SyncHandler
+}
+
+func InitRouter(r *gin.Engine, h Handlers) {
 	// 使用 CORS 中间件
 	r.Use(middleware.Cors())
 
+	// 健康检查与版本信息，不需要 JWT 验证
+	r.GET("/healthz", h.Health.Healthz)
+	r.GET("/readyz", h.Health.Readyz)
+	r.GET("/version", h.Health.Version)
+
 	api := r.Group("/api/v1")
 	{
 		// 公开路由 - 不需要 JWT 验证
-		api.POST("/register", v1.Register)
-		api.POST("/login", v1.Login)
-		api.GET("/wishes/community", v1.GetCommunityWishes)
-		api.GET("/wishes/random", v1.GetRandomWish)
+		api.POST("/register", h.User.Register)
+		api.POST("/login", h.User.Login)
+		api.GET("/wishes/community", h.Wish.GetCommunityWishes)
+		api.GET("/wishes/random", h.Wish.GetRandomWish)
 
 		// 需要验证的路由组
 		auth := api.Group("")
-		auth.Use(middleware.JWT())
+		auth.Use(middleware.JWT(), middleware.Location(h.User.ResolveLocation))
 		{
+			// 当前用户
+			auth.GET("/users/me", h.User.GetCurrentUser)
+			auth.PUT("/users/me/timezone", h.User.UpdateTimezone)
+
 			// 任务相关路由
-			auth.POST("/tasks", v1.CreateTask)
-			auth.GET("/tasks/today", v1.GetTodayTasks)
-			auth.GET("/tasks/timeline", v1.GetTaskTimeline)
-			auth.PUT("/tasks/:id", v1.UpdateTask)
-			auth.DELETE("/tasks/:id", v1.DeleteTask)
-			auth.PUT("/tasks/:id/complete", v1.CompleteTask)
-			auth.PUT("/tasks/importance", v1.UpdateTasksImportance)
+			auth.POST("/tasks", h.Task.CreateTask)
+			auth.GET("/tasks", h.Task.GetUserTasks)
+			auth.GET("/tasks/today", h.Task.GetTodayTasks)
+			auth.GET("/tasks/timeline", h.Task.GetTaskTimeline)
+			auth.GET("/tasks/upcoming", h.Task.GetUpcomingTasks)
+			auth.GET("/tasks/overdue", h.Task.GetOverdueTasks)
+			auth.GET("/tasks/:id", h.Task.GetTask)
+			auth.PUT("/tasks/:id", h.Task.UpdateTask)
+			auth.PATCH("/tasks/:id", h.Task.PatchTask)
+			auth.DELETE("/tasks/:id", h.Task.DeleteTask)
+			auth.PUT("/tasks/:id/complete", h.Task.CompleteTask)
+			auth.GET("/tasks/:id/completions", h.Task.GetTaskCompletions)
+			auth.PUT("/tasks/importance", h.Task.UpdateTasksImportance)
+			auth.PUT("/tasks/:id/position", h.Task.MoveTask)
+			auth.POST("/tasks/:id/restore", h.Task.RestoreTask)
+
+			// 子任务与清单项
+			auth.GET("/tasks/:id/subtasks", h.Task.GetSubtasks)
+			auth.POST("/tasks/:id/subtasks", h.Task.CreateChecklistItem)
+			auth.PUT("/tasks/:id/subtasks/:itemId", h.Task.UpdateChecklistItem)
+			auth.DELETE("/tasks/:id/subtasks/:itemId", h.Task.DeleteChecklistItem)
+
+			// 任务提醒
+			auth.POST("/tasks/:id/reminders", h.Reminder.CreateReminder)
+			auth.GET("/tasks/:id/reminders", h.Reminder.GetReminders)
+			auth.DELETE("/tasks/:id/reminders/:reminderId", h.Reminder.DeleteReminder)
 
 			// 需要验证的心愿相关路由
-			auth.POST("/wishes", v1.CreateWish)
-			auth.GET("/wishes", v1.GetUserWishes)
-			auth.PUT("/wishes/:id", v1.UpdateWish)
-			auth.DELETE("/wishes/:id", v1.DeleteWish)
-			auth.POST("/wishes/:id/share", v1.ShareWish)
+			auth.POST("/wishes", h.Wish.CreateWish)
+			auth.GET("/wishes", h.Wish.GetUserWishes)
+			auth.GET("/wishes/:id", h.Wish.GetWish)
+			auth.PUT("/wishes/:id", h.Wish.UpdateWish)
+			auth.PATCH("/wishes/:id", h.Wish.PatchWish)
+			auth.DELETE("/wishes/:id", h.Wish.DeleteWish)
+			auth.POST("/wishes/:id/share", h.Wish.ShareWish)
+			auth.POST("/wishes/:id/restore", h.Wish.RestoreWish)
+			auth.POST("/wishes/community/:id/like", h.Wish.LikeSharedWish)
+			auth.DELETE("/wishes/community/:id/like", h.Wish.UnlikeSharedWish)
+
+			// 清单
+			auth.GET("/lists", h.List.GetLists)
+			auth.POST("/lists", h.List.CreateList)
+			auth.PUT("/lists/:id", h.List.UpdateList)
+			auth.DELETE("/lists/:id", h.List.DeleteList)
+
+			// 标签
+			auth.GET("/tags", h.Tag.GetTags)
+			auth.POST("/tags", h.Tag.CreateTag)
+			auth.PUT("/tags/:id", h.Tag.UpdateTag)
+			auth.DELETE("/tags/:id", h.Tag.DeleteTag)
+
+			// 全文搜索
+			auth.GET("/search", h.Search.Search)
+
+			// 离线客户端增量同步
+			auth.GET("/sync", h.Sync.GetChanges)
+			auth.POST("/sync", h.Sync.PushMutations)
+
+			// 回收站
+			auth.GET("/trash", h.Trash.GetTrash)
+
+			// 站内通知
+			auth.GET("/notifications", h.Notification.GetNotifications)
+			auth.GET("/notifications/unread-count", h.Notification.GetUnreadCount)
+			auth.PUT("/notifications/read", h.Notification.MarkAllNotificationsRead)
+			auth.PUT("/notifications/:id/read", h.Notification.MarkNotificationRead)
+			auth.DELETE("/notifications/:id", h.Notification.DeleteNotification)
+
+			// 统计
+			auth.GET("/stats/habits", h.Stats.GetHabitStats)
 		}
 	}
 }
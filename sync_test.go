@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/pkg/pagination"
+)
+
+// syncChanges GET /sync 的响应
+type syncChanges struct {
+	Tasks   []model.Task `json:"tasks"`
+	Wishes  []model.Wish `json:"wishes"`
+	Lists   []model.List `json:"lists"`
+	Tags    []model.Tag  `json:"tags"`
+	Deleted struct {
+		Tasks  []uint `json:"tasks"`
+		Wishes []uint `json:"wishes"`
+		Lists  []uint `json:"lists"`
+		Tags   []uint `json:"tags"`
+	} `json:"deleted"`
+	Cursor string `json:"cursor"`
+	Reset  bool   `json:"reset"`
+}
+
+// syncResult POST /sync 中一个修改的结果
+type syncResult struct {
+	ClientID string          `json:"client_id"`
+	Status   string          `json:"status"`
+	ID       uint            `json:"id"`
+	Data     json.RawMessage `json:"data"`
+	Error    string          `json:"error"`
+}
+
+// pull 拉取 since 之后的变更
+func (s *testServer) pull(token, since string) syncChanges {
+	s.t.Helper()
+	path := "/api/v1/sync"
+	if since != "" {
+		path += "?since=" + url.QueryEscape(since)
+	}
+	var changes syncChanges
+	s.expect(http.StatusOK, http.MethodGet, path, token, nil).decode(s.t, &changes)
+	return changes
+}
+
+// push 提交离线修改，返回每个修改的结果
+func (s *testServer) push(token string, mutations ...map[string]interface{}) []syncResult {
+	s.t.Helper()
+	var resp struct {
+		Results []syncResult `json:"results"`
+	}
+	s.expect(http.StatusOK, http.MethodPost, "/api/v1/sync", token, map[string]interface{}{"mutations": mutations}).decode(s.t, &resp)
+	if len(resp.Results) != len(mutations) {
+		s.t.Fatalf("提交 %d 个修改, 返回 %d 个结果", len(mutations), len(resp.Results))
+	}
+	return resp.Results
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSyncPullCursor(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	task := s.createTask(token, map[string]interface{}{"event": "买牛奶"})
+
+	first := s.pull(token, "")
+	if !first.Reset || first.Cursor == "" {
+		t.Fatalf("首次同步 reset = %v, cursor = %q", first.Reset, first.Cursor)
+	}
+	if len(first.Tasks) != 1 || first.Tasks[0].ID != task.ID {
+		t.Fatalf("首次同步返回任务 %+v", first.Tasks)
+	}
+
+	next := s.pull(token, first.Cursor)
+	if next.Reset || next.Cursor == "" {
+		t.Fatalf("增量同步 reset = %v, cursor = %q", next.Reset, next.Cursor)
+	}
+
+	s.expect(http.StatusBadRequest, http.MethodGet, "/api/v1/sync?since=invalid", token, nil)
+}
+
+func TestSyncTombstones(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	task := s.createTask(token, map[string]interface{}{"event": "买牛奶"})
+	wish := s.createWish(token, map[string]interface{}{"event": "环游世界"})
+	var list model.List
+	s.expect(http.StatusOK, http.MethodPost, "/api/v1/lists", token, map[string]string{"name": "工作"}).decode(t, &list)
+	var tag model.Tag
+	s.expect(http.StatusOK, http.MethodPost, "/api/v1/tags", token, map[string]string{"name": "旅行"}).decode(t, &tag)
+
+	cursor := s.pull(token, "").Cursor
+	s.expect(http.StatusOK, http.MethodDelete, taskPath(task.ID, ""), token, nil)
+	s.expect(http.StatusOK, http.MethodDelete, "/api/v1/wishes/"+strconv.Itoa(int(wish.ID)), token, nil)
+	s.expect(http.StatusOK, http.MethodDelete, "/api/v1/lists/"+strconv.Itoa(int(list.ID)), token, nil)
+	s.expect(http.StatusOK, http.MethodDelete, "/api/v1/tags/"+strconv.Itoa(int(tag.ID)), token, nil)
+
+	changes := s.pull(token, cursor)
+	if changes.Reset {
+		t.Fatal("增量同步不应 reset")
+	}
+	for name, ok := range map[string]bool{
+		"任务": containsID(changes.Deleted.Tasks, task.ID),
+		"心愿": containsID(changes.Deleted.Wishes, wish.ID),
+		"清单": containsID(changes.Deleted.Lists, list.ID),
+		"标签": containsID(changes.Deleted.Tags, tag.ID),
+	} {
+		if !ok {
+			t.Errorf("删除记录中没有%s: %+v", name, changes.Deleted)
+		}
+	}
+	if len(changes.Tasks)+len(changes.Wishes)+len(changes.Lists)+len(changes.Tags) != 0 {
+		t.Errorf("已删除的记录仍作为变更返回: %+v", changes)
+	}
+
+	// 首次同步不返回删除记录
+	if full := s.pull(token, ""); len(full.Deleted.Tasks) != 0 || len(full.Tasks) != 0 {
+		t.Errorf("全量同步返回了已删除的任务: %+v", full)
+	}
+}
+
+func TestSyncExpiredCursorResets(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	s.createTask(token, map[string]interface{}{"event": "买牛奶"})
+
+	// 早于回收站保留期限（30 天）的游标
+	expired := pagination.Encode(map[string]time.Time{"t": time.Now().UTC().AddDate(0, 0, -31)})
+	changes := s.pull(token, expired)
+	if !changes.Reset || len(changes.Tasks) != 1 {
+		t.Fatalf("过期游标 reset = %v, 任务 %d 个", changes.Reset, len(changes.Tasks))
+	}
+
+	recent := pagination.Encode(map[string]time.Time{"t": time.Now().UTC().AddDate(0, 0, -29)})
+	if changes := s.pull(token, recent); changes.Reset {
+		t.Fatal("保留期限内的游标不应 reset")
+	}
+}
+
+func TestSyncCreateIdempotent(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	create := map[string]interface{}{
+		"client_id": "c-task-1", "type": "task", "op": "create",
+		"data": map[string]interface{}{"event": "买牛奶"},
+	}
+
+	first := s.push(token, create)[0]
+	second := s.push(token, create)[0]
+	if first.Status != "applied" || second.Status != "applied" || first.ID == 0 || first.ID != second.ID {
+		t.Fatalf("重复创建结果 %+v, %+v", first, second)
+	}
+	if changes := s.pull(token, ""); len(changes.Tasks) != 1 {
+		t.Fatalf("重复创建后有 %d 个任务", len(changes.Tasks))
+	}
+
+	// client_id 对应的记录已删除时不再返回该记录
+	s.expect(http.StatusOK, http.MethodDelete, taskPath(first.ID, ""), token, nil)
+	if res := s.push(token, create)[0]; res.Status != "not_found" {
+		t.Fatalf("已删除记录的重复创建结果 %+v, want not_found", res)
+	}
+}
+
+func TestSyncClientIDReferences(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	results := s.push(token,
+		map[string]interface{}{"client_id": "c-list", "type": "list", "op": "create", "data": map[string]interface{}{"name": "工作"}},
+		map[string]interface{}{"client_id": "c-tag", "type": "tag", "op": "create", "data": map[string]interface{}{"name": "重要"}},
+		map[string]interface{}{"client_id": "c-parent", "type": "task", "op": "create", "data": map[string]interface{}{
+			"event": "周报", "list_client_id": "c-list", "tag_client_ids": []string{"c-tag"},
+		}},
+		map[string]interface{}{"client_id": "c-child", "type": "task", "op": "create", "data": map[string]interface{}{
+			"event": "汇总数据", "parent_client_id": "c-parent",
+		}},
+		map[string]interface{}{"client_id": "c-wish", "type": "wish", "op": "create", "data": map[string]interface{}{
+			"event": "环游世界", "tag_client_ids": []string{"c-tag"},
+		}},
+		map[string]interface{}{"client_id": "c-bad", "type": "task", "op": "create", "data": map[string]interface{}{
+			"event": "引用不存在的清单", "list_client_id": "missing",
+		}},
+	)
+	for _, r := range results[:5] {
+		if r.Status != "applied" {
+			t.Fatalf("%s: %+v", r.ClientID, r)
+		}
+	}
+	if results[5].Status != "invalid" {
+		t.Fatalf("引用不存在的记录结果 %+v, want invalid", results[5])
+	}
+	listID, tagID, parentID := results[0].ID, results[1].ID, results[2].ID
+
+	var parent model.Task
+	s.expect(http.StatusOK, http.MethodGet, taskPath(parentID, ""), token, nil).decode(t, &parent)
+	if parent.ListID == nil || *parent.ListID != listID || len(parent.Tags) != 1 || parent.Tags[0].ID != tagID {
+		t.Fatalf("父任务的清单和标签 %+v", parent)
+	}
+	var child model.Task
+	s.expect(http.StatusOK, http.MethodGet, taskPath(results[3].ID, ""), token, nil).decode(t, &child)
+	if child.ParentID == nil || *child.ParentID != parentID {
+		t.Fatalf("子任务的父任务 %v, want %d", child.ParentID, parentID)
+	}
+	var wish model.Wish
+	if err := json.Unmarshal(results[4].Data, &wish); err != nil {
+		t.Fatal(err)
+	}
+	if len(wish.Tags) != 1 || wish.Tags[0].ID != tagID {
+		t.Fatalf("心愿的标签 %+v", wish.Tags)
+	}
+}
+
+func TestSyncConflict(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	task := s.createTask(token, map[string]interface{}{"event": "买牛奶"})
+	stale := task.Version
+	s.expect(http.StatusOK, http.MethodPatch, taskPath(task.ID, ""), token, map[string]string{"description": "全脂"})
+
+	results := s.push(token,
+		map[string]interface{}{"type": "task", "op": "update", "id": task.ID, "version": stale, "data": map[string]interface{}{"description": "脱脂"}},
+		map[string]interface{}{"type": "task", "op": "delete", "id": task.ID + 100},
+		map[string]interface{}{"client_id": "c-after", "type": "task", "op": "create", "data": map[string]interface{}{"event": "背单词"}},
+	)
+	if results[0].Status != "conflict" {
+		t.Fatalf("过期版本的更新结果 %+v, want conflict", results[0])
+	}
+	var current model.Task
+	if err := json.Unmarshal(results[0].Data, &current); err != nil {
+		t.Fatal(err)
+	}
+	if current.ID != task.ID || current.Description != "全脂" || current.Version == stale {
+		t.Fatalf("冲突时返回的服务器记录 %+v", current)
+	}
+	if results[1].Status != "not_found" {
+		t.Fatalf("删除不存在的任务结果 %+v, want not_found", results[1])
+	}
+	if results[2].Status != "applied" {
+		t.Fatalf("失败之后的修改结果 %+v, want applied", results[2])
+	}
+
+	// 基于当前版本的更新成功
+	res := s.push(token, map[string]interface{}{
+		"type": "task", "op": "update", "id": task.ID, "version": current.Version, "data": map[string]interface{}{"description": "脱脂"},
+	})[0]
+	if res.Status != "applied" {
+		t.Fatalf("基于当前版本的更新结果 %+v", res)
+	}
+}
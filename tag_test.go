@@ -0,0 +1,204 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/PisaListBE/internal/model"
+)
+
+// createTag 创建标签并返回
+func (s *testServer) createTag(token, name string) model.Tag {
+	s.t.Helper()
+	var tag model.Tag
+	s.expect(http.StatusOK, http.MethodPost, "/api/v1/tags", token, map[string]string{"name": name}).decode(s.t, &tag)
+	return tag
+}
+
+func wishPath(id uint, suffix string) string {
+	return "/api/v1/wishes/" + strconv.FormatUint(uint64(id), 10) + suffix
+}
+
+// tagNames 返回排序后的标签名称
+func tagNames(tags []model.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// user-018: 创建时打标签，PUT/PATCH 整体替换标签，删除标签后从任务和心愿上移除
+func TestTagAttachAndReplace(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	work, home, urgent := s.createTag(token, "工作"), s.createTag(token, "家庭"), s.createTag(token, "紧急")
+
+	task := s.createTask(token, map[string]interface{}{"event": "写周报", "tag_ids": []uint{work.ID, urgent.ID}})
+	if got := tagNames(task.Tags); !sameStrings(got, []string{"工作", "紧急"}) {
+		t.Fatalf("创建任务的标签 %v", got)
+	}
+
+	var updated model.Task
+	s.expect(http.StatusOK, http.MethodPut, taskPath(task.ID, ""), token, map[string]interface{}{
+		"event": "写周报", "tag_ids": []uint{home.ID},
+	}).decode(t, &updated)
+	if got := tagNames(updated.Tags); !sameStrings(got, []string{"家庭"}) {
+		t.Fatalf("PUT 替换后的标签 %v", got)
+	}
+	s.expect(http.StatusOK, http.MethodPatch, taskPath(task.ID, ""), token, map[string]interface{}{
+		"tag_ids": []uint{work.ID, home.ID},
+	}).decode(t, &updated)
+	if got := tagNames(updated.Tags); !sameStrings(got, []string{"家庭", "工作"}) {
+		t.Fatalf("PATCH 替换后的标签 %v", got)
+	}
+	s.expect(http.StatusOK, http.MethodPatch, taskPath(task.ID, ""), token, map[string]interface{}{"description": "本周"}).decode(t, &updated)
+	if len(updated.Tags) != 2 {
+		t.Fatalf("PATCH 省略 tag_ids 时标签变为 %v", tagNames(updated.Tags))
+	}
+
+	wish := s.createWish(token, map[string]interface{}{"event": "环游世界", "tag_ids": []uint{home.ID}})
+	var updatedWish model.Wish
+	s.expect(http.StatusOK, http.MethodPut, wishPath(wish.ID, ""), token, map[string]interface{}{
+		"event": "环游世界", "tag_ids": []uint{work.ID, urgent.ID},
+	}).decode(t, &updatedWish)
+	if got := tagNames(updatedWish.Tags); !sameStrings(got, []string{"工作", "紧急"}) {
+		t.Fatalf("PUT 替换后的心愿标签 %v", got)
+	}
+
+	// 其他用户的标签和不存在的标签不能使用
+	otherTag := s.createTag(s.register("def"), "旅行")
+	for _, ids := range [][]uint{{otherTag.ID}, {work.ID, 9999}} {
+		s.expect(http.StatusBadRequest, http.MethodPost, "/api/v1/tasks", token, map[string]interface{}{"event": "买牛奶", "tag_ids": ids})
+		s.expect(http.StatusBadRequest, http.MethodPut, wishPath(wish.ID, ""), token, map[string]interface{}{"event": "环游世界", "tag_ids": ids})
+	}
+
+	s.expect(http.StatusOK, http.MethodDelete, "/api/v1/tags/"+strconv.Itoa(int(work.ID)), token, nil)
+	s.expect(http.StatusOK, http.MethodGet, taskPath(task.ID, ""), token, nil).decode(t, &updated)
+	if got := tagNames(updated.Tags); !sameStrings(got, []string{"家庭"}) {
+		t.Fatalf("删除标签后任务的标签 %v", got)
+	}
+	s.expect(http.StatusOK, http.MethodGet, wishPath(wish.ID, ""), token, nil).decode(t, &updatedWish)
+	if got := tagNames(updatedWish.Tags); !sameStrings(got, []string{"紧急"}) {
+		t.Fatalf("删除标签后心愿的标签 %v", got)
+	}
+}
+
+// user-018: 任务、心愿和社区心愿都可以按标签名称筛选
+func TestTagFilter(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	travel, health := s.createTag(token, "旅行"), s.createTag(token, "健康")
+
+	trip := s.createTask(token, map[string]interface{}{"event": "订机票", "tag_ids": []uint{travel.ID}})
+	run := s.createTask(token, map[string]interface{}{"event": "跑步", "tag_ids": []uint{health.ID, travel.ID}})
+	s.createTask(token, map[string]interface{}{"event": "买牛奶"})
+	world := s.createWish(token, map[string]interface{}{"event": "环游世界", "tag_ids": []uint{travel.ID}})
+	marathon := s.createWish(token, map[string]interface{}{"event": "跑马拉松", "tag_ids": []uint{health.ID}})
+	s.expect(http.StatusOK, http.MethodPost, wishPath(world.ID, "/share"), token, nil)
+	s.expect(http.StatusOK, http.MethodPost, wishPath(marathon.ID, "/share"), token, nil)
+
+	// 另一个用户的同名标签
+	other := s.register("def")
+	s.createTask(other, map[string]interface{}{"event": "订酒店", "tag_ids": []uint{s.createTag(other, "旅行").ID}})
+
+	var page taskPage
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/tasks?tag="+url.QueryEscape("旅行"), token, nil).decode(t, &page)
+	if got := taskIDs(page.Items); !sameIDs(got, []uint{trip.ID, run.ID}) || page.Total != 2 {
+		t.Fatalf("按标签筛选任务 %v (total %d)", got, page.Total)
+	}
+	var today []model.Task
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/tasks/today?tag="+url.QueryEscape("健康"), token, nil).decode(t, &today)
+	if got := taskIDs(today); !sameIDs(got, []uint{run.ID}) {
+		t.Fatalf("按标签筛选今日任务 %v", got)
+	}
+
+	var wishes []model.Wish
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/wishes?tag="+url.QueryEscape("旅行"), token, nil).decode(t, &wishes)
+	if len(wishes) != 1 || wishes[0].ID != world.ID {
+		t.Fatalf("按标签筛选心愿 %+v", wishes)
+	}
+
+	var community []model.SharedWish
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/wishes/community?tag="+url.QueryEscape("健康"), "", nil).decode(t, &community)
+	if len(community) != 1 || community[0].OriginalWishID != marathon.ID || len(community[0].Tags) != 1 || community[0].Tags[0].Name != "健康" {
+		t.Fatalf("按标签筛选社区心愿 %+v", community)
+	}
+
+	// 不存在的标签返回空列表
+	for _, path := range []string{"/api/v1/tasks?tag=x", "/api/v1/wishes?tag=x", "/api/v1/wishes/community?tag=x"} {
+		resp := s.expect(http.StatusOK, http.MethodGet, path, token, nil)
+		var items struct {
+			Items []interface{} `json:"items"`
+		}
+		var list []interface{}
+		if resp.body[0] == '{' {
+			resp.decode(t, &items)
+			list = items.Items
+		} else {
+			resp.decode(t, &list)
+		}
+		if len(list) != 0 {
+			t.Errorf("%s 返回 %d 条, want 0", path, len(list))
+		}
+	}
+}
+
+// user-018: 同一用户的标签不能重名，不同用户之间互不影响
+func TestTagNameConflict(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	work := s.createTag(token, "工作")
+	home := s.createTag(token, "家庭")
+
+	s.expect(http.StatusConflict, http.MethodPost, "/api/v1/tags", token, map[string]string{"name": "工作"})
+	s.expect(http.StatusConflict, http.MethodPost, "/api/v1/tags", token, map[string]string{"name": "  工作 "})
+	s.expect(http.StatusConflict, http.MethodPut, "/api/v1/tags/"+strconv.Itoa(int(home.ID)), token, map[string]string{"name": "工作"})
+	s.expect(http.StatusBadRequest, http.MethodPost, "/api/v1/tags", token, map[string]string{"name": "   "})
+
+	// 只修改颜色时名称不变，不算重名
+	var updated model.Tag
+	s.expect(http.StatusOK, http.MethodPut, "/api/v1/tags/"+strconv.Itoa(int(work.ID)), token, map[string]string{
+		"name": "工作", "color": "#ff8800",
+	}).decode(t, &updated)
+	if updated.Color != "#FF8800" {
+		t.Fatalf("标签颜色 %q, want #FF8800", updated.Color)
+	}
+
+	s.createTag(s.register("def"), "工作")
+
+	// 删除后可以重新使用这个名称
+	s.expect(http.StatusOK, http.MethodDelete, "/api/v1/tags/"+strconv.Itoa(int(work.ID)), token, nil)
+	s.createTag(token, "工作")
+}
+
+// user-018: 每个心愿只能分享一次
+func TestShareWishOnce(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	wish := s.createWish(token, map[string]interface{}{"event": "环游世界"})
+
+	s.expect(http.StatusOK, http.MethodPost, wishPath(wish.ID, "/share"), token, nil)
+	s.expect(http.StatusConflict, http.MethodPost, wishPath(wish.ID, "/share"), token, nil)
+
+	var community []model.SharedWish
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/wishes/community", "", nil).decode(t, &community)
+	shared := 0
+	for _, w := range community {
+		if w.OriginalWishID == wish.ID {
+			shared++
+		}
+	}
+	if shared != 1 {
+		t.Fatalf("心愿 %d 在社区中有 %d 条, want 1", wish.ID, shared)
+	}
+	var got model.Wish
+	s.expect(http.StatusOK, http.MethodGet, wishPath(wish.ID, ""), token, nil).decode(t, &got)
+	if !got.IsShared {
+		t.Fatal("分享后 is_shared 仍为 false")
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+	"github.com/PisaListBE/internal/service"
+	"github.com/PisaListBE/pkg/pagination"
+)
+
+// taskPage GET /tasks 的响应
+type taskPage struct {
+	Items      []model.Task `json:"items"`
+	NextCursor string       `json:"next_cursor"`
+	Total      int64        `json:"total"`
+}
+
+// listTasks 按查询参数获取一页任务
+func (s *testServer) listTasks(token string, query url.Values) taskPage {
+	s.t.Helper()
+	var page taskPage
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/tasks?"+query.Encode(), token, nil).decode(s.t, &page)
+	return page
+}
+
+// taskIDs 返回任务ID，保持顺序
+func taskIDs(tasks []model.Task) []uint {
+	ids := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func sameIDs(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// user-021: 筛选条件组合使用时同时生效，total 与筛选结果一致
+func TestTaskListFilters(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+
+	var list model.List
+	s.expect(http.StatusOK, http.MethodPost, "/api/v1/lists", token, map[string]string{"name": "工作"}).decode(t, &list)
+	var tag model.Tag
+	s.expect(http.StatusOK, http.MethodPost, "/api/v1/tags", token, map[string]string{"name": "重要"}).decode(t, &tag)
+
+	report := s.createTask(token, map[string]interface{}{
+		"event": "写周报", "importance_level": 4, "list_id": list.ID, "tag_ids": []uint{tag.ID},
+	})
+	meeting := s.createTask(token, map[string]interface{}{
+		"event": "准备会议材料", "importance_level": 2, "list_id": list.ID,
+	})
+	run := s.createTask(token, map[string]interface{}{
+		"event": "跑步", "importance_level": 5, "is_cycle": true, "tag_ids": []uint{tag.ID},
+	})
+	milk := s.createTask(token, map[string]interface{}{"event": "买牛奶", "description": "周报写完再去"})
+	s.expect(http.StatusOK, http.MethodPut, taskPath(report.ID, "/complete"), token, nil)
+	s.expect(http.StatusOK, http.MethodPut, taskPath(run.ID, "/complete"), token, nil)
+
+	// 另一个用户的任务不出现在结果中
+	other := s.register("def")
+	s.createTask(other, map[string]interface{}{"event": "写周报", "importance_level": 4})
+
+	// 把会议材料的创建时间改到上个月
+	lastMonth := time.Now().UTC().AddDate(0, -1, 0)
+	if err := s.db.Model(&model.Task{}).Where("id = ?", meeting.ID).Update("created_at", lastMonth).Error; err != nil {
+		t.Fatal(err)
+	}
+	today := time.Now().UTC().Format(service.DateLayout)
+	listID := strconv.FormatUint(uint64(list.ID), 10)
+
+	tests := []struct {
+		name  string
+		query url.Values
+		want  []uint
+	}{
+		{"全部", url.Values{}, []uint{report.ID, meeting.ID, run.ID, milk.ID}},
+		{"清单和已完成", url.Values{"list_id": {listID}, "completed": {"true"}}, []uint{report.ID}},
+		{"清单和未完成", url.Values{"list_id": {listID}, "completed": {"false"}}, []uint{meeting.ID}},
+		{"不属于清单", url.Values{"list_id": {"0"}}, []uint{run.ID, milk.ID}},
+		{"标签和循环", url.Values{"tag": {"重要"}, "is_cycle": {"true"}}, []uint{run.ID}},
+		{"标签和重要性", url.Values{"tag": {"重要"}, "min_importance": {"4"}, "max_importance": {"4"}}, []uint{report.ID}},
+		{"不存在的标签", url.Values{"tag": {"旅行"}}, nil},
+		{"重要性范围", url.Values{"min_importance": {"2"}, "max_importance": {"4"}}, []uint{report.ID, meeting.ID}},
+		{"今天完成", url.Values{"completed_from": {today}, "completed_to": {today}}, []uint{report.ID, run.ID}},
+		{"今天创建", url.Values{"created_from": {today}}, []uint{report.ID, run.ID, milk.ID}},
+		{"上个月创建", url.Values{"created_to": {lastMonth.Format(service.DateLayout)}}, []uint{meeting.ID}},
+		{"搜索内容和描述", url.Values{"q": {"周报"}}, []uint{report.ID, milk.ID}},
+		{"搜索和未完成", url.Values{"q": {"周报"}, "completed": {"false"}}, []uint{milk.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := s.listTasks(token, tt.query)
+			got := taskIDs(page.Items)
+			if !sameIDs(got, tt.want) || page.Total != int64(len(tt.want)) {
+				t.Fatalf("%v = %v (total %d), want %v", tt.query, got, page.Total, tt.want)
+			}
+		})
+	}
+
+	for _, query := range []url.Values{
+		{"completed": {"yes"}},
+		{"min_importance": {"6"}},
+		{"list_id": {"abc"}},
+		{"created_from": {"2025/01/01"}},
+		{"sort": {"event"}},
+		{"order": {"up"}},
+		{"limit": {"0"}},
+	} {
+		s.expect(http.StatusBadRequest, http.MethodGet, "/api/v1/tasks?"+query.Encode(), token, nil)
+	}
+}
+
+// user-021: 排序值相同的任务按ID区分，逐页读取的结果与一次读取完全一致
+func TestTaskListStablePagination(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+
+	// 重要性只有两种取值，创建时间也大多相同，需要靠ID区分先后
+	for i := 0; i < 7; i++ {
+		s.createTask(token, map[string]interface{}{"event": "任务", "importance_level": i % 2})
+	}
+
+	for _, query := range []url.Values{
+		{},
+		{"sort": {"importance"}},
+		{"sort": {"importance"}, "order": {"asc"}},
+		{"sort": {"created_at"}, "order": {"desc"}},
+		{"sort": {"due_at"}},
+	} {
+		all := query
+		all.Set("limit", "100")
+		want := taskIDs(s.listTasks(token, all).Items)
+
+		var got []uint
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > len(want) {
+				t.Fatalf("%v: 分页没有结束", query)
+			}
+			q := url.Values{"limit": {"3"}, "cursor": {cursor}}
+			for k, v := range query {
+				if k != "limit" {
+					q[k] = v
+				}
+			}
+			page := s.listTasks(token, q)
+			if page.Total != int64(len(want)) {
+				t.Fatalf("%v: 第 %d 页 total = %d, want %d", query, pages+1, page.Total, len(want))
+			}
+			got = append(got, taskIDs(page.Items)...)
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		if !sameIDs(got, want) {
+			t.Errorf("%v: 分页结果 %v, want %v", query, got, want)
+		}
+	}
+}
+
+// user-021: 无法解析或与当前排序方式不符的游标返回 400
+func TestTaskListInvalidCursor(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+	for i := 0; i < 3; i++ {
+		s.createTask(token, map[string]interface{}{"event": "任务"})
+	}
+	cursor := s.listTasks(token, url.Values{"limit": {"1"}, "sort": {"importance"}}).NextCursor
+	if cursor == "" {
+		t.Fatal("第一页没有返回 next_cursor")
+	}
+
+	for name, query := range map[string]url.Values{
+		"不是 base64": {"cursor": {"!!!"}},
+		"不是 JSON":   {"cursor": {pagination.Encode("position")[:3]}},
+		"空游标对象":     {"cursor": {pagination.Encode(nil)}},
+		"排序字段不同":    {"cursor": {cursor}},
+		"排序方向不同":    {"cursor": {cursor}, "sort": {"importance"}, "order": {"asc"}},
+		"其他接口的游标":   {"cursor": {pagination.Encode(map[string]time.Time{"t": time.Now()})}},
+	} {
+		resp := s.do(http.MethodGet, "/api/v1/tasks?"+query.Encode(), token, nil)
+		if resp.status != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400\n%s", name, resp.status, resp.body)
+		}
+	}
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/tasks?"+url.Values{"cursor": {cursor}, "sort": {"importance"}}.Encode(), token, nil)
+}
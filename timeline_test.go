@@ -0,0 +1,225 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/PisaListBE/internal/model"
+)
+
+// timelineEntry GET /tasks/timeline 中的一条完成记录
+type timelineEntry struct {
+	model.Task
+	OccurrenceDate string `json:"occurrence_date"`
+}
+
+type timelinePage struct {
+	Items      []timelineEntry `json:"items"`
+	NextCursor string          `json:"next_cursor"`
+}
+
+type timelineBucket struct {
+	Key   string `json:"key"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Count int64  `json:"count"`
+}
+
+// complete 直接写入任务在某一天的完成记录
+func (s *testServer) complete(task model.Task, date string) {
+	s.t.Helper()
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	completion := model.TaskCompletion{
+		TaskID:         task.ID,
+		UserID:         task.UserID,
+		OccurrenceDate: date,
+		CompletedAt:    day.Add(20 * time.Hour),
+	}
+	if err := s.db.Create(&completion).Error; err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+// occurrences 以“内容@日期”的形式返回时间线记录，保持顺序
+func occurrences(items []timelineEntry) []string {
+	list := make([]string, 0, len(items))
+	for _, e := range items {
+		list = append(list, e.Event+"@"+e.OccurrenceDate)
+	}
+	return list
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// user-014: 时间线只返回范围内的完成记录，循环任务的每一次完成各占一条
+func TestTimelineRange(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+
+	run := s.createTask(token, map[string]interface{}{"event": "跑步", "recurrence": "daily"})
+	report := s.createTask(token, map[string]interface{}{"event": "写周报"})
+	deleted := s.createTask(token, map[string]interface{}{"event": "已删除"})
+	for _, date := range []string{"2024-12-31", "2025-01-01", "2025-01-02", "2025-01-03", "2025-01-05", "2025-01-06"} {
+		s.complete(run, date)
+	}
+	s.complete(report, "2025-01-04")
+	s.complete(deleted, "2025-01-03")
+	s.expect(http.StatusOK, http.MethodDelete, taskPath(deleted.ID, ""), token, nil)
+
+	// 另一个用户的完成记录不出现
+	other := s.register("def")
+	s.complete(s.createTask(other, map[string]interface{}{"event": "跑步"}), "2025-01-03")
+
+	timeline := func(query url.Values) timelinePage {
+		var page timelinePage
+		s.expect(http.StatusOK, http.MethodGet, "/api/v1/tasks/timeline?"+query.Encode(), token, nil).decode(t, &page)
+		return page
+	}
+
+	want := []string{"跑步@2025-01-05", "写周报@2025-01-04", "跑步@2025-01-03", "跑步@2025-01-02"}
+	page := timeline(url.Values{"from": {"2025-01-02"}, "to": {"2025-01-05"}})
+	if got := occurrences(page.Items); !sameStrings(got, want) || page.NextCursor != "" {
+		t.Fatalf("时间线 %v (next %q), want %v", got, page.NextCursor, want)
+	}
+	for _, e := range page.Items {
+		if !e.Completed {
+			t.Errorf("时间线中的任务 %+v 未标记为完成", e.Task)
+		}
+	}
+
+	// 逐页读取与一次读取一致
+	var got []string
+	cursor := ""
+	for {
+		page := timeline(url.Values{"from": {"2025-01-02"}, "to": {"2025-01-05"}, "limit": {"3"}, "cursor": {cursor}})
+		got = append(got, occurrences(page.Items)...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if !sameStrings(got, want) {
+		t.Fatalf("分页时间线 %v, want %v", got, want)
+	}
+
+	// 单独一天
+	if got := occurrences(timeline(url.Values{"from": {"2025-01-06"}, "to": {"2025-01-06"}}).Items); !sameStrings(got, []string{"跑步@2025-01-06"}) {
+		t.Fatalf("单日时间线 %v", got)
+	}
+
+	groups := func(groupBy string) []timelineBucket {
+		var buckets []timelineBucket
+		query := url.Values{"from": {"2024-12-31"}, "to": {"2025-01-06"}, "group_by": {groupBy}}
+		s.expect(http.StatusOK, http.MethodGet, "/api/v1/tasks/timeline?"+query.Encode(), token, nil).decode(t, &buckets)
+		return buckets
+	}
+	for groupBy, want := range map[string][]timelineBucket{
+		"day": {
+			{"2025-01-06", "2025-01-06", "2025-01-06", 1},
+			{"2025-01-05", "2025-01-05", "2025-01-05", 1},
+			{"2025-01-04", "2025-01-04", "2025-01-04", 1},
+			{"2025-01-03", "2025-01-03", "2025-01-03", 1},
+			{"2025-01-02", "2025-01-02", "2025-01-02", 1},
+			{"2025-01-01", "2025-01-01", "2025-01-01", 1},
+			{"2024-12-31", "2024-12-31", "2024-12-31", 1},
+		},
+		// 2025-01-06 是周一，2024-12-31 与 2025-01-05 同属 ISO 2025 年第 1 周
+		"week": {
+			{"2025-W02", "2025-01-06", "2025-01-12", 1},
+			{"2025-W01", "2024-12-30", "2025-01-05", 6},
+		},
+		"month": {
+			{"2025-01", "2025-01-01", "2025-01-31", 6},
+			{"2024-12", "2024-12-01", "2024-12-31", 1},
+		},
+	} {
+		got := groups(groupBy)
+		if len(got) != len(want) {
+			t.Errorf("group_by=%s: %+v, want %+v", groupBy, got, want)
+			continue
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("group_by=%s: %+v, want %+v", groupBy, got, want)
+				break
+			}
+		}
+	}
+
+	for _, query := range []url.Values{
+		{"from": {"2025-01-05"}, "to": {"2025-01-02"}},
+		{"from": {"2025/01/02"}},
+		{"to": {"tomorrow"}},
+		{"group_by": {"year"}},
+		{"cursor": {"!!!"}},
+		{"limit": {"101"}},
+	} {
+		s.expect(http.StatusBadRequest, http.MethodGet, "/api/v1/tasks/timeline?"+query.Encode(), token, nil)
+	}
+}
+
+// user-014: 完成日期和默认范围按用户时区划分
+func TestTimelineUserLocation(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("abc")
+
+	// 选一个与 UTC 不在同一天的时区：UTC 上午时西边已是前一天晚上，其余时间东边已是第二天
+	zone := zoneAtHour(23)
+	if h := time.Now().UTC().Hour(); h >= 10 {
+		zone = zoneAtHour((h + 14) % 24)
+	}
+	s.expect(http.StatusOK, http.MethodPut, "/api/v1/users/me/timezone", token, map[string]string{"timezone": zone})
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	today := time.Now().In(loc).Format("2006-01-02")
+	utcToday := time.Now().UTC().Format("2006-01-02")
+	if today == utcToday {
+		t.Fatalf("时区 %s 与 UTC 同一天", zone)
+	}
+
+	run := s.createTask(token, map[string]interface{}{"event": "跑步", "recurrence": "daily"})
+	report := s.createTask(token, map[string]interface{}{"event": "写周报"})
+	s.expect(http.StatusOK, http.MethodPut, taskPath(run.ID, "/complete"), token, nil)
+	s.expect(http.StatusOK, http.MethodPut, taskPath(report.ID, "/complete"), token, nil)
+
+	// 默认返回用户时区的最近 7 天
+	var page timelinePage
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/tasks/timeline", token, nil).decode(t, &page)
+	if len(page.Items) != 2 {
+		t.Fatalf("默认时间线 %v, want 2 条", occurrences(page.Items))
+	}
+	for _, e := range page.Items {
+		if e.OccurrenceDate != today {
+			t.Errorf("%s 的完成日期 %s, want 用户时区的今天 %s", e.Event, e.OccurrenceDate, today)
+		}
+	}
+
+	var buckets []timelineBucket
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/tasks/timeline?group_by=day", token, nil).decode(t, &buckets)
+	if len(buckets) != 1 || buckets[0].Key != today || buckets[0].Count != 2 {
+		t.Fatalf("按天分组 %+v, want %s 2 条", buckets, today)
+	}
+
+	query := url.Values{"from": {utcToday}, "to": {utcToday}}
+	s.expect(http.StatusOK, http.MethodGet, "/api/v1/tasks/timeline?"+query.Encode(), token, nil).decode(t, &page)
+	if len(page.Items) != 0 {
+		t.Fatalf("UTC 的今天 %s 不应有完成记录: %v", utcToday, occurrences(page.Items))
+	}
+}